@@ -0,0 +1,164 @@
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"math"
+	"slices"
+	"sync"
+)
+
+// Episode is the result of one game of a policy
+type Episode struct {
+	Seed          int64   `json:"seed"`
+	Score         int     `json:"score"`
+	Level         int     `json:"level"`  // level reached
+	Frames        int     `json:"frames"` // frames survived
+	Bricks        int     `json:"bricks"` // bricks cleared
+	Lives         int     `json:"lives"`  // lives played: the lost ones and the one at the end
+	BricksPerLife float64 `json:"bricks_per_life"`
+	Won           bool    `json:"won"`
+	Capped        bool    `json:"capped"` // stopped by the frame cap
+}
+
+// runEpisode lets the policy play the game of the seed until it is over or
+// maxFrames frames are played
+func runEpisode(cfg breakout.Config, seed int64, policy bot.Policy, maxFrames int) Episode {
+	game := breakout.NewBreakout(cfg, seed)
+	ep := Episode{Seed: seed}
+	state := game.GetState()
+	for !state.Done && ep.Frames < maxFrames {
+		for _, e := range replay.Play(game, policy.Act(&state)) {
+			switch e := e.(type) {
+			case breakout.BrickHit:
+				if e.Cleared {
+					ep.Bricks++
+				}
+			case breakout.LifeLost:
+				ep.Lives++
+			}
+		}
+		ep.Frames++
+		state = game.GetState()
+	}
+	ep.Score, ep.Level, ep.Won = state.Score, state.Level, state.Won
+	ep.Capped = !state.Done
+	if !state.Done || state.Won {
+		ep.Lives++ // the life the game ended with was not lost
+	}
+	ep.BricksPerLife = float64(ep.Bricks) / float64(max(ep.Lives, 1))
+	return ep
+}
+
+// runEpisodes plays the games of the seeds first to first+n-1 on workers
+// goroutines, every game with a new policy of newPolicy, and returns them
+// in the order of their seeds
+func runEpisodes(cfg breakout.Config, newPolicy func(seed int64) bot.Policy, first int64, n, maxFrames, workers int) []Episode {
+	episodes := make([]Episode, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range max(min(workers, n), 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				seed := first + int64(i)
+				episodes[i] = runEpisode(cfg, seed, newPolicy(seed), maxFrames)
+			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return episodes
+}
+
+// Summary holds the statistics of one metric over all episodes
+type Summary struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stddev"` // sample standard deviation
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// summarize returns the statistics of the values, zero without values
+func summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	n := len(sorted)
+	var s Summary
+	s.Min, s.Max = sorted[0], sorted[n-1]
+	s.Median = sorted[n/2]
+	if n%2 == 0 {
+		s.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	for _, v := range sorted {
+		s.Mean += v
+	}
+	s.Mean /= float64(n)
+	if n > 1 {
+		var sq float64
+		for _, v := range sorted {
+			sq += (v - s.Mean) * (v - s.Mean)
+		}
+		s.StdDev = math.Sqrt(sq / float64(n-1))
+	}
+	return s
+}
+
+// metrics are the names of the summarized metrics, in the order they are
+// reported, with their value of an episode
+var metrics = []struct {
+	name  string
+	value func(ep *Episode) float64
+}{
+	{"score", func(ep *Episode) float64 { return float64(ep.Score) }},
+	{"level", func(ep *Episode) float64 { return float64(ep.Level) }},
+	{"frames", func(ep *Episode) float64 { return float64(ep.Frames) }},
+	{"bricks_per_life", func(ep *Episode) float64 { return ep.BricksPerLife }},
+}
+
+// Report holds the episodes of an evaluation and the summaries of their
+// metrics by name
+type Report struct {
+	Bot       string             `json:"bot"`
+	FirstSeed int64              `json:"first_seed"`
+	MaxFrames int                `json:"max_frames"`
+	Won       int                `json:"won"`    // episodes won
+	Capped    int                `json:"capped"` // episodes stopped by the frame cap
+	Summaries map[string]Summary `json:"summaries"`
+	Episodes  []Episode          `json:"episodes"`
+}
+
+// newReport returns the report of the episodes of the bot
+func newReport(botName string, firstSeed int64, maxFrames int, episodes []Episode) *Report {
+	r := &Report{
+		Bot:       botName,
+		FirstSeed: firstSeed,
+		MaxFrames: maxFrames,
+		Summaries: make(map[string]Summary, len(metrics)),
+		Episodes:  episodes,
+	}
+	for i := range episodes {
+		if episodes[i].Won {
+			r.Won++
+		}
+		if episodes[i].Capped {
+			r.Capped++
+		}
+	}
+	for _, m := range metrics {
+		values := make([]float64, len(episodes))
+		for i := range episodes {
+			values[i] = m.value(&episodes[i])
+		}
+		r.Summaries[m.name] = summarize(values)
+	}
+	return r
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   Summary
+	}{
+		{nil, Summary{}},
+		{[]float64{3}, Summary{Mean: 3, Median: 3, Min: 3, Max: 3}},
+		{[]float64{4, 1, 3, 2}, Summary{Mean: 2.5, Median: 2.5, StdDev: 1.2909944487358056, Min: 1, Max: 4}},
+		{[]float64{5, 1, 3}, Summary{Mean: 3, Median: 3, StdDev: 2, Min: 1, Max: 5}},
+	}
+	for _, tt := range tests {
+		if got := summarize(tt.values); got != tt.want {
+			t.Errorf("Expected %+v for %v, got %+v", tt.want, tt.values, got)
+		}
+	}
+}
+
+func TestRunEpisode(t *testing.T) {
+	cfg := breakout.DefaultConfig()
+	lost := runEpisode(cfg, 3, bot.NewRandom(3), 100_000)
+	if lost.Capped || lost.Won || lost.Lives != breakout.MAX_LIVES {
+		t.Errorf("Expected the random bot to lose all lives, got %+v", lost)
+	}
+	if want := float64(lost.Bricks) / float64(lost.Lives); lost.BricksPerLife != want {
+		t.Errorf("Expected %g bricks per life, got %g", want, lost.BricksPerLife)
+	}
+
+	capped := runEpisode(cfg, 3, bot.NewTracker(), 1000)
+	if !capped.Capped || capped.Frames != 1000 || capped.Lives != 1 {
+		t.Errorf("Expected the tracker to play 1000 frames with one life, got %+v", capped)
+	}
+	if capped.Bricks == 0 || capped.Score == 0 || capped.Level != 1 {
+		t.Errorf("Expected cleared bricks on level 1, got %+v", capped)
+	}
+}
+
+func TestRunEpisodesIsDeterministic(t *testing.T) {
+	cfg := breakout.DefaultConfig()
+	newPolicy := func(seed int64) bot.Policy { return bot.NewRandom(seed) }
+	serial := runEpisodes(cfg, newPolicy, 10, 8, 2000, 1)
+	parallel := runEpisodes(cfg, newPolicy, 10, 8, 2000, 4)
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Error("Expected the same episodes on one and on four workers")
+	}
+	for i, ep := range serial {
+		if ep.Seed != int64(10+i) {
+			t.Errorf("Expected seed %d for episode %d, got %d", 10+i, i, ep.Seed)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	r := newReport("tracker", 1, 100, []Episode{
+		{Seed: 1, Score: 10, Level: 1, Frames: 100, BricksPerLife: 2, Capped: true},
+		{Seed: 2, Score: 30, Level: 2, Frames: 50, BricksPerLife: 4, Won: true},
+	})
+	if r.Won != 1 || r.Capped != 1 {
+		t.Errorf("Expected 1 won and 1 capped episode, got %d and %d", r.Won, r.Capped)
+	}
+	if s := r.Summaries["score"]; s.Mean != 20 || s.Min != 10 || s.Max != 30 {
+		t.Errorf("Expected a mean score of 20, got %+v", s)
+	}
+	if len(r.Summaries) != len(metrics) {
+		t.Errorf("Expected %d summaries, got %d", len(metrics), len(r.Summaries))
+	}
+}
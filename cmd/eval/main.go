@@ -0,0 +1,85 @@
+// Command eval benchmarks a policy without the server: it plays many
+// episodes of breakout.Breakout, one per seed of a range, in parallel and
+// reports statistics of their scores, levels reached, frames survived and
+// bricks cleared per life. With fixed seeds the games are deterministic, so
+// two runs before and after an engine change show whether it shifts the
+// difficulty.
+//
+// Usage:
+//
+//	eval -bot predictive -episodes 200               text summary
+//	eval -bot random -format json -out random.json   summaries and episodes
+//
+// Flags:
+//   - -bot: Name of the policy, see bot.Names. Defaults to "tracker".
+//   - -episodes: Number of episodes. Defaults to 100.
+//   - -seed: Seed of the first episode, the episodes use the seeds from
+//     -seed on. Defaults to 1.
+//   - -frames: Frames an episode is played at most. Defaults to 36000, ten
+//     minutes of play.
+//   - -workers: Episodes played at the same time. Defaults to the number of
+//     CPUs.
+//   - -config, -levels: Game configuration and level file, as for cmd/web.
+//   - -format: Report format, "text", "json" or "csv". Defaults to "text".
+//   - -out: File the report is written to. Defaults to the standard output.
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"time"
+)
+
+func main() {
+	botName := flag.String("bot", "tracker", fmt.Sprintf("Policy to evaluate, one of %v.", bot.Names()))
+	episodes := flag.Int("episodes", 100, "Number of episodes.")
+	seed := flag.Int64("seed", 1, "Seed of the first episode.")
+	frames := flag.Int("frames", 36000, "Frames an episode is played at most.")
+	workers := flag.Int("workers", runtime.NumCPU(), "Episodes played at the same time.")
+	configFile := flag.String("config", "", "JSON file with the game configuration. Defaults to the classic playfield.")
+	levelsFile := flag.String("levels", "", "JSON file with level layouts. Defaults to the full brick grid on every level.")
+	format := flag.String("format", FORMAT_TEXT, "Report format: text, json or csv.")
+	out := flag.String("out", "", "File to write the report to. Defaults to the standard output.")
+	flag.Parse()
+	if *episodes < 1 || *frames < 1 || *workers < 1 {
+		log.Fatal("Invalid -episodes, -frames or -workers")
+	}
+	if *format != FORMAT_TEXT && *format != FORMAT_JSON && *format != FORMAT_CSV {
+		log.Fatalf("Unknown format %q", *format)
+	}
+	if _, err := bot.ByName(*botName, 1); err != nil {
+		log.Fatal(err)
+	}
+	cfg, err := breakout.LoadConfig(*configFile, *levelsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	start := time.Now()
+	newPolicy := func(seed int64) bot.Policy {
+		p, _ := bot.ByName(*botName, seed)
+		return p
+	}
+	eps := runEpisodes(cfg, newPolicy, *seed, *episodes, *frames, *workers)
+	report := newReport(*botName, *seed, *frames, eps)
+	log.Printf("Played %d episodes in %v", len(eps), time.Since(start).Round(time.Millisecond))
+
+	w := os.Stdout
+	if *out != "" {
+		if w, err = os.Create(*out); err != nil {
+			log.Fatalf("Failed to create the report: %v", err)
+		}
+	}
+	err = writeReport(w, report, *format)
+	if cerr := w.Close(); err == nil && *out != "" {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatalf("Failed to write the report: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Formats of the report
+const (
+	FORMAT_TEXT = "text" // summary table for people
+	FORMAT_JSON = "json" // summaries and every episode
+	FORMAT_CSV  = "csv"  // summary table with a header row
+)
+
+// writeReport writes the report to w in the format
+func writeReport(w io.Writer, r *Report, format string) error {
+	switch format {
+	case FORMAT_TEXT:
+		return writeText(w, r)
+	case FORMAT_JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FORMAT_CSV:
+		return writeCSV(w, r)
+	}
+	return fmt.Errorf("unknown format %q, expected %s, %s or %s", format, FORMAT_TEXT, FORMAT_JSON, FORMAT_CSV)
+}
+
+// writeText writes the summaries as an aligned table below a header line
+func writeText(w io.Writer, r *Report) error {
+	last := r.FirstSeed + int64(len(r.Episodes)) - 1
+	fmt.Fprintf(w, "Bot %s, %d episodes, seeds %d to %d, at most %d frames\n", r.Bot, len(r.Episodes), r.FirstSeed, last, r.MaxFrames)
+	fmt.Fprintf(w, "Won %d, stopped by the frame cap %d\n\n", r.Won, r.Capped)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "metric\tmean\tmedian\tstddev\tmin\tmax\t")
+	for _, m := range metrics {
+		s := r.Summaries[m.name]
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n", m.name, s.Mean, s.Median, s.StdDev, s.Min, s.Max)
+	}
+	return tw.Flush()
+}
+
+// writeCSV writes the summaries, one row per metric
+func writeCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"metric", "mean", "median", "stddev", "min", "max"})
+	for _, m := range metrics {
+		s := r.Summaries[m.name]
+		row := []string{m.name}
+		for _, v := range []float64{s.Mean, s.Median, s.StdDev, s.Min, s.Max} {
+			row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testReport() *Report {
+	return newReport("tracker", 5, 100, []Episode{
+		{Seed: 5, Score: 10, Level: 1, Frames: 100, BricksPerLife: 2.5},
+		{Seed: 6, Score: 20, Level: 2, Frames: 80, BricksPerLife: 3},
+	})
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, testReport(), FORMAT_TEXT); err != nil {
+		t.Fatalf("Expected the report, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Bot tracker, 2 episodes, seeds 5 to 6", "score", "15.00", "bricks_per_life", "2.75"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the report, got\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, testReport(), FORMAT_JSON); err != nil {
+		t.Fatalf("Expected the report, got %v", err)
+	}
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected JSON, got %v", err)
+	}
+	if !reflect.DeepEqual(&got, testReport()) {
+		t.Errorf("Expected the report to round trip, got %+v", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, testReport(), FORMAT_CSV); err != nil {
+		t.Fatalf("Expected the report, got %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected CSV, got %v", err)
+	}
+	if len(rows) != len(metrics)+1 || rows[0][0] != "metric" {
+		t.Fatalf("Expected a header and %d rows, got %v", len(metrics), rows)
+	}
+	if want := []string{"score", "15", "15", "7.0710678118654755", "10", "20"}; !reflect.DeepEqual(rows[1], want) {
+		t.Errorf("Expected %v, got %v", want, rows[1])
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := writeReport(&bytes.Buffer{}, testReport(), "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
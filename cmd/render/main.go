@@ -0,0 +1,167 @@
+// Command render draws the frames of a game into image files, without a
+// browser, e.g. to embed clips of episodes into training reports or bug
+// tickets.
+//
+// The game is a replay file (see internal/replay) or a game played by the
+// built-in bot (see internal/bot). Every frame is drawn with internal/render,
+// like the web client draws it.
+//
+// Usage:
+//
+//	render -replay game.bkrp -out game.gif       animated GIF of a replay
+//	render -seed 5 -frames 1200 -out frames/     PNG sequence of a bot run
+//
+// Flags:
+//   - -out: Output, an animated GIF for a path ending in ".gif", otherwise a
+//     directory the frames are written to as frame_000000.png, ...
+//   - -replay: Replay file to draw. Without it the bot plays a game.
+//   - -bot: Name of the bot, see bot.Names. Defaults to "tracker".
+//   - -seed, -config, -levels: Seed, game configuration and level file of
+//     the bot game, as for cmd/web.
+//   - -frames: Frames the bot plays at most. Defaults to 3600, a minute.
+//   - -from, -to: First and last frame to draw, -to 0 for the last frame of
+//     the game.
+//   - -every: Draw every n-th frame. Defaults to 2.
+//   - -scale: Image pixels per game pixel. Defaults to 2.
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/render"
+	"breakout-go/internal/replay"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// TICK_RATE is the number of frames per second the game is played at, it
+// sets the speed of GIF animations
+const TICK_RATE = 60
+
+// errDone stops a game after the last frame to draw
+var errDone = errors.New("done")
+
+func main() {
+	out := flag.String("out", "", "Output: an animated GIF for a path ending in .gif, otherwise a directory of PNG files.")
+	replayFile := flag.String("replay", "", "Replay file to draw. Defaults to a game of the bot.")
+	botName := flag.String("bot", "tracker", fmt.Sprintf("Bot that plays without -replay, one of %v.", bot.Names()))
+	seed := flag.Int64("seed", 0, "Seed of the bot game. Defaults to 0, a random seed.")
+	configFile := flag.String("config", "", "JSON file with the game configuration of the bot game.")
+	levelsFile := flag.String("levels", "", "JSON file with level layouts of the bot game.")
+	frames := flag.Int("frames", 3600, "Frames the bot plays at most.")
+	from := flag.Int("from", 0, "First frame to draw.")
+	to := flag.Int("to", 0, "Last frame to draw, 0 for the last frame of the game.")
+	every := flag.Int("every", 2, "Draw every n-th frame.")
+	scale := flag.Float64("scale", 2, "Image pixels per game pixel.")
+	flag.Parse()
+	if *out == "" {
+		log.Fatal("Missing -out")
+	}
+	if *every < 1 || *scale <= 0 || *from < 0 || *to < 0 || *frames < 0 {
+		log.Fatal("Invalid -every, -scale, -from, -to or -frames")
+	}
+
+	var play func(each func(frame int, game *breakout.Breakout) error) error
+	if *replayFile != "" {
+		p, err := readReplay(*replayFile)
+		if err != nil {
+			log.Fatalf("Failed to read the replay: %v", err)
+		}
+		play = func(each func(int, *breakout.Breakout) error) error { return playReplay(p, each) }
+	} else {
+		cfg, err := breakout.LoadConfig(*configFile, *levelsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		policy, err := bot.ByName(*botName, *seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Game of the %s bot with seed %d\n", *botName, *seed)
+		game := breakout.NewBreakout(cfg, *seed)
+		play = func(each func(int, *breakout.Breakout) error) error {
+			return playBot(game, policy, *frames, each)
+		}
+	}
+
+	o, err := newOutput(*out, float64(*every)/TICK_RATE)
+	if err != nil {
+		log.Fatalf("Failed to create the output: %v", err)
+	}
+	drawn := 0
+	err = play(func(frame int, game *breakout.Breakout) error {
+		if *to > 0 && frame > *to {
+			return errDone
+		}
+		if frame < *from || (frame-*from)%*every != 0 {
+			return nil
+		}
+		state := game.GetState()
+		drawn++
+		return o.add(render.Render(&state, *scale))
+	})
+	if err == nil || errors.Is(err, errDone) {
+		err = o.close()
+	}
+	if err != nil {
+		log.Fatalf("Failed to write the frames: %v", err)
+	}
+	fmt.Printf("Wrote %d frames to %s\n", drawn, *out)
+}
+
+// readReplay returns a player of the replay file
+func readReplay(path string) (*replay.Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rp, err := replay.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	return replay.NewPlayer(rp)
+}
+
+// playReplay calls each with the game before the first and after every
+// frame of the replay
+func playReplay(p *replay.Player, each func(frame int, game *breakout.Breakout) error) error {
+	if err := each(p.Frame(), p.Game()); err != nil {
+		return err
+	}
+	for {
+		if _, ok := p.Step(); !ok {
+			return nil
+		}
+		if err := each(p.Frame(), p.Game()); err != nil {
+			return err
+		}
+	}
+}
+
+// playBot lets the policy play the game for at most maxFrames frames or
+// until it is over and calls each with the game before the first and after
+// every frame
+func playBot(game *breakout.Breakout, policy bot.Policy, maxFrames int, each func(frame int, game *breakout.Breakout) error) error {
+	if err := each(0, game); err != nil {
+		return err
+	}
+	for frame := 1; frame <= maxFrames; frame++ {
+		state := game.GetState()
+		if state.Done {
+			return nil
+		}
+		replay.Play(game, policy.Act(&state))
+		if err := each(frame, game); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"reflect"
+	"testing"
+)
+
+func TestPlayBotAndReplay(t *testing.T) {
+	game := breakout.NewBreakout(breakout.DefaultConfig(), 2)
+	rec := replay.NewRecorder(breakout.DefaultConfig(), 2)
+	tracker := bot.NewTracker()
+	var botStates []breakout.BreakoutState
+	playBot(game, policyFunc(func(state *breakout.BreakoutState) replay.Input {
+		in := tracker.Act(state)
+		rec.Record(in, state.Score)
+		return in
+	}), 300, func(frame int, game *breakout.Breakout) error {
+		if frame != len(botStates) {
+			t.Fatalf("Expected frame %d, got %d", len(botStates), frame)
+		}
+		botStates = append(botStates, game.GetState())
+		return nil
+	})
+	if len(botStates) != 301 {
+		t.Fatalf("Expected 301 states of 300 frames, got %d", len(botStates))
+	}
+
+	p, _ := replay.NewPlayer(rec.Replay())
+	var replayStates []breakout.BreakoutState
+	playReplay(p, func(frame int, game *breakout.Breakout) error {
+		replayStates = append(replayStates, game.GetState())
+		return nil
+	})
+	if !reflect.DeepEqual(replayStates, botStates) {
+		t.Error("Expected the replay to draw the frames of the bot game")
+	}
+}
+
+// policyFunc is a Policy of a function
+type policyFunc func(state *breakout.BreakoutState) replay.Input
+
+func (f policyFunc) Act(state *breakout.BreakoutState) replay.Input {
+	return f(state)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"breakout-go/internal/render"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// output writes the rendered frames
+type output interface {
+	add(img *image.RGBA) error
+	close() error
+}
+
+// newOutput returns an animated GIF for a path ending in ".gif" and a
+// directory of PNG files otherwise. delay is the time every frame of the
+// GIF is shown in seconds.
+func newOutput(path string, delay float64) (output, error) {
+	if strings.EqualFold(filepath.Ext(path), ".gif") {
+		return &gifOutput{path: path, delay: delay}, nil
+	}
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+	return &pngOutput{dir: path}, nil
+}
+
+// pngOutput writes every frame to a numbered PNG file of a directory
+type pngOutput struct {
+	dir string
+	n   int // frames written
+}
+
+func (o *pngOutput) add(img *image.RGBA) error {
+	f, err := os.Create(filepath.Join(o.dir, fmt.Sprintf("frame_%06d.png", o.n)))
+	if err != nil {
+		return err
+	}
+	o.n++
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (o *pngOutput) close() error {
+	return nil
+}
+
+// gifOutput collects the frames of an animated GIF and writes it on close
+type gifOutput struct {
+	path  string
+	delay float64 // seconds every frame is shown
+	anim  gif.GIF
+}
+
+func (o *gifOutput) add(img *image.RGBA) error {
+	// GIF delays are in 100ths of a second, the rounding error is carried
+	// over so the animation keeps the speed of the game
+	n := len(o.anim.Image)
+	delay := int(float64(n+1)*o.delay*100+0.5) - int(float64(n)*o.delay*100+0.5)
+	o.anim.Image = append(o.anim.Image, render.Paletted(img))
+	o.anim.Delay = append(o.anim.Delay, max(delay, 2))
+	return nil
+}
+
+func (o *gifOutput) close() error {
+	if len(o.anim.Image) == 0 {
+		return fmt.Errorf("no frames to write to %s", o.path)
+	}
+	f, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, &o.anim); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
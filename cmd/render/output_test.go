@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGIFOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clip.gif")
+	o, err := newOutput(path, 2.0/60)
+	if err != nil {
+		t.Fatalf("Expected a GIF output, got %v", err)
+	}
+	for range 3 {
+		o.add(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	}
+	if err := o.close(); err != nil {
+		t.Fatalf("Expected to write the GIF, got %v", err)
+	}
+	f, _ := os.Open(path)
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("Expected a GIF, got %v", err)
+	}
+	// 3.33 hundredths of a second per frame, the rounding carried over
+	if len(anim.Image) != 3 || anim.Delay[0] != 3 || anim.Delay[1] != 4 || anim.Delay[2] != 3 {
+		t.Errorf("Expected 3 frames with delays 3, 4, 3, got %v", anim.Delay)
+	}
+}
+
+func TestPNGOutput(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "frames")
+	o, err := newOutput(dir, 0)
+	if err != nil {
+		t.Fatalf("Expected a PNG output, got %v", err)
+	}
+	for range 2 {
+		if err := o.add(image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+			t.Fatalf("Expected to write the frame, got %v", err)
+		}
+	}
+	o.close()
+	files, _ := filepath.Glob(filepath.Join(dir, "*.png"))
+	if len(files) != 2 || filepath.Base(files[1]) != "frame_000001.png" {
+		t.Errorf("Expected 2 numbered PNG files, got %v", files)
+	}
+}
+
+func TestGIFOutputWithoutFrames(t *testing.T) {
+	o, _ := newOutput(filepath.Join(t.TempDir(), "empty.gif"), 0.1)
+	if err := o.close(); err == nil {
+		t.Error("Expected an error for a GIF without frames")
+	}
+}
@@ -0,0 +1,182 @@
+// Command train trains a Q-learning agent in process, without the server,
+// and saves it to a checkpoint file. It needs no dependencies and trains a
+// reference agent on a CPU in minutes.
+//
+// The agent plays the features observation of the environment (see
+// internal/env) with frame skip: a tabular agent over the discretized ball
+// position, ball direction and paddle position, or a linear function of
+// coarse features of the ball's offset from the paddle (see internal/train).
+//
+// Usage:
+//
+//	train -agent tabular -checkpoint tabular.json
+//	train -agent linear -checkpoint linear.json -episodes 500
+//	train -checkpoint tabular.json -resume -episodes 1000   train on
+//	train -checkpoint tabular.json -resume -episodes 0      evaluate only
+//
+// Flags:
+//   - -agent: "tabular" or "linear". Defaults to "tabular", with -resume
+//     the agent of the checkpoint, which has to match a given -agent.
+//   - -episodes: Training episodes to play. Defaults to 2000.
+//   - -max-frames: Frames after which an episode is truncated. Defaults to
+//     10000.
+//   - -frame-skip: Frames every action is repeated. Defaults to 4.
+//   - -reward: Reward preset, see env.RewardPreset. Defaults to "shaped".
+//   - -alpha, -gamma: Learning rate and discount. Default to 0.1 and 0.99.
+//   - -epsilon: Exploration schedule, "constant", "linear" or "exp", from
+//     -eps-start to -eps-end over -eps-steps steps (the half-life for
+//     "exp"). Defaults to "linear" from 1 to 0.05 over 200000 steps.
+//   - -seed: Seed of the exploration and the first episode, at least 1.
+//     Defaults to 1.
+//   - -checkpoint: File the agent is saved to every -save-every episodes
+//     and at the end.
+//   - -resume: Continue the training of the -checkpoint file.
+//   - -log-every: Episodes averaged in a progress line. Defaults to 100.
+//   - -eval: Greedy episodes played after the training. Defaults to 10.
+//   - -config, -levels: Game configuration and level file, as for cmd/web.
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"breakout-go/internal/train"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+)
+
+// EVAL_SEED is the seed of the first evaluation episode, far from the
+// seeds of the training episodes
+const EVAL_SEED = 1_000_000_000
+
+func main() {
+	agentKind := flag.String("agent", train.KIND_TABULAR, "Agent to train: tabular or linear.")
+	episodes := flag.Int("episodes", 2000, "Training episodes to play.")
+	maxFrames := flag.Int("max-frames", 10000, "Frames after which an episode is truncated.")
+	frameSkip := flag.Int("frame-skip", 4, "Frames every action is repeated.")
+	reward := flag.String("reward", "shaped", fmt.Sprintf("Reward preset, one of %v.", env.RewardPresetNames()))
+	defaults := train.DefaultOptions()
+	alpha := flag.Float64("alpha", defaults.Alpha, "Learning rate.")
+	gamma := flag.Float64("gamma", defaults.Gamma, "Discount of future rewards.")
+	schedule := flag.String("epsilon", train.SCHEDULE_LINEAR, "Exploration schedule: constant, linear or exp.")
+	epsStart := flag.Float64("eps-start", 1, "Exploration rate at the start.")
+	epsEnd := flag.Float64("eps-end", 0.05, "Exploration rate at the end.")
+	epsSteps := flag.Int("eps-steps", 200_000, "Steps of the exploration schedule, the half-life for exp.")
+	seed := flag.Int64("seed", defaults.Seed, "Seed of the exploration and the first episode.")
+	checkpoint := flag.String("checkpoint", "", "File to save the agent to.")
+	resume := flag.Bool("resume", false, "Continue the training of the -checkpoint file.")
+	saveEvery := flag.Int("save-every", 100, "Episodes between two checkpoints.")
+	logEvery := flag.Int("log-every", 100, "Episodes averaged in a progress line.")
+	evalEpisodes := flag.Int("eval", 10, "Greedy episodes played after the training.")
+	configFile := flag.String("config", "", "JSON file with the game configuration. Defaults to the classic playfield.")
+	levelsFile := flag.String("levels", "", "JSON file with level layouts. Defaults to the full brick grid on every level.")
+	flag.Parse()
+	if *episodes < 0 || *maxFrames < 1 || *saveEvery < 1 || *logEvery < 1 || *evalEpisodes < 0 {
+		log.Fatal("Invalid -episodes, -max-frames, -save-every, -log-every or -eval")
+	}
+	if *resume && *checkpoint == "" {
+		log.Fatal("-resume needs -checkpoint")
+	}
+	wrappers := env.Wrappers{FrameSkip: *frameSkip}
+	if err := wrappers.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	rewardFunc, err := env.RewardPreset(*reward)
+	if err != nil {
+		log.Fatal(err)
+	}
+	eps, err := train.NewSchedule(*schedule, *epsStart, *epsEnd, *epsSteps)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg, err := breakout.LoadConfig(*configFile, *levelsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cp := &train.Checkpoint{}
+	if *resume {
+		if cp, err = train.LoadCheckpoint(*checkpoint); err != nil {
+			log.Fatalf("Failed to load the checkpoint: %v", err)
+		}
+		agentSet := false
+		flag.Visit(func(f *flag.Flag) { agentSet = agentSet || f.Name == "agent" })
+		if agentSet && cp.Agent.Kind() != *agentKind {
+			log.Fatalf("-agent %s does not match the %s agent of the checkpoint", *agentKind, cp.Agent.Kind())
+		}
+		log.Printf("Resuming the %s agent after %d episodes", cp.Agent.Kind(), cp.Episodes)
+	} else {
+		switch *agentKind {
+		case train.KIND_TABULAR:
+			cp.Agent = train.NewQTable(train.DefaultBuckets())
+		case train.KIND_LINEAR:
+			cp.Agent = train.NewLinear()
+		default:
+			log.Fatalf("Unknown agent %q, expected %s or %s", *agentKind, train.KIND_TABULAR, train.KIND_LINEAR)
+		}
+	}
+
+	e := wrappers.Wrap(env.NewBreakoutEnv(cfg, env.Options{
+		MaxSteps:    *maxFrames,
+		Observation: env.ObsOptions{Mode: env.ObsFeatures},
+		Reward:      rewardFunc,
+	}))
+	t, err := train.NewTrainer(e, cp.Agent, train.Options{Alpha: *alpha, Gamma: *gamma, Epsilon: eps, Seed: *seed})
+	if err != nil {
+		log.Fatal(err)
+	}
+	t.Steps, t.Episodes = cp.Steps, cp.Episodes
+
+	start := time.Now()
+	var window []train.EpisodeStats
+	for range *episodes {
+		window = append(window, t.Episode())
+		if len(window) == *logEvery {
+			log.Printf("Episode %d, step %d, epsilon %.3f: %s", t.Episodes, t.Steps, t.Epsilon(), average(window))
+			window = window[:0]
+		}
+		if *checkpoint != "" && t.Episodes%*saveEvery == 0 {
+			if err := save(*checkpoint, t); err != nil {
+				log.Fatalf("Failed to save the checkpoint: %v", err)
+			}
+		}
+	}
+	if *episodes > 0 {
+		log.Printf("Trained %d episodes in %v", *episodes, time.Since(start).Round(time.Second))
+	}
+	if *checkpoint != "" && *episodes > 0 {
+		if err := save(*checkpoint, t); err != nil {
+			log.Fatalf("Failed to save the checkpoint: %v", err)
+		}
+		log.Printf("Saved the agent to %s", *checkpoint)
+	}
+
+	if *evalEpisodes > 0 {
+		stats, err := train.Evaluate(e, t.Agent(), EVAL_SEED, *evalEpisodes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Greedy %s agent, %d episodes: %s\n", t.Agent().Kind(), len(stats), average(stats))
+	}
+}
+
+// save writes the agent and the progress of the trainer to the checkpoint
+// file at path
+func save(path string, t *train.Trainer) error {
+	cp := train.Checkpoint{Agent: t.Agent(), Steps: t.Steps, Episodes: t.Episodes}
+	return cp.Save(path)
+}
+
+// average returns the mean return, score, level and frames of the episodes
+func average(stats []train.EpisodeStats) string {
+	var ret, score, level, frames float64
+	for _, s := range stats {
+		ret += s.Return
+		score += float64(s.Score)
+		level += float64(s.Level)
+		frames += float64(s.Frames)
+	}
+	n := float64(max(len(stats), 1))
+	return fmt.Sprintf("return %.2f, score %.1f, level %.2f, frames %.0f", ret/n, score/n, level/n, frames/n)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"breakout-go/internal/train"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAverage(t *testing.T) {
+	got := average([]train.EpisodeStats{
+		{Return: 1, Score: 10, Level: 1, Frames: 100},
+		{Return: 2, Score: 20, Level: 2, Frames: 300},
+	})
+	if want := "return 1.50, score 15.0, level 1.50, frames 200"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestSaveResumesTraining(t *testing.T) {
+	e := env.NewBreakoutEnv(breakout.DefaultConfig(), env.Options{MaxSteps: 500, Observation: env.ObsOptions{Mode: env.ObsFeatures}})
+	tr, err := train.NewTrainer(e, train.NewLinear(), train.DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected a trainer, got %v", err)
+	}
+	tr.Episode()
+	path := filepath.Join(t.TempDir(), "agent.json")
+	if err := save(path, tr); err != nil {
+		t.Fatalf("Expected the checkpoint to be saved, got %v", err)
+	}
+	cp, err := train.LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Expected the checkpoint to load, got %v", err)
+	}
+	if cp.Steps != tr.Steps || cp.Episodes != 1 || !reflect.DeepEqual(cp.Agent, tr.Agent()) {
+		t.Errorf("Expected the agent after %d steps and 1 episode, got %d steps and %d episodes", tr.Steps, cp.Steps, cp.Episodes)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+// key is a key press read from the terminal
+type key int
+
+const (
+	keyNone key = iota
+	keyLeft
+	keyRight
+	keyFire    // space
+	keyPause   // p
+	keyRestart // r
+	keyQuit    // q, Esc or ^C
+)
+
+// parseKeys returns the key presses in the bytes read from a raw terminal.
+// Arrow keys arrive as the escape sequences ESC [ C/D or ESC O C/D, a lone
+// ESC quits.
+func parseKeys(buf []byte) []key {
+	var keys []key
+	for i := 0; i < len(buf); i++ {
+		switch c := buf[i]; c {
+		case 0x1b:
+			if i+2 < len(buf) && (buf[i+1] == '[' || buf[i+1] == 'O') {
+				switch buf[i+2] {
+				case 'D':
+					keys = append(keys, keyLeft)
+				case 'C':
+					keys = append(keys, keyRight)
+				}
+				i += 2
+			} else if i+1 == len(buf) {
+				keys = append(keys, keyQuit)
+			}
+		case 'a', 'h':
+			keys = append(keys, keyLeft)
+		case 'd', 'l':
+			keys = append(keys, keyRight)
+		case ' ':
+			keys = append(keys, keyFire)
+		case 'p':
+			keys = append(keys, keyPause)
+		case 'r':
+			keys = append(keys, keyRestart)
+		case 'q', 0x03:
+			keys = append(keys, keyQuit)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := map[string][]key{
+		"\x1b[D\x1b[C":  {keyLeft, keyRight},
+		"\x1bOD":        {keyLeft},
+		" pr":           {keyFire, keyPause, keyRestart},
+		"ahdl":          {keyLeft, keyLeft, keyRight, keyRight},
+		"\x1b":          {keyQuit},
+		"\x03":          {keyQuit},
+		"\x1b[A\x1b[Bx": nil,
+	}
+	for in, want := range tests {
+		if got := parseKeys([]byte(in)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected keys %v for %q, got %v", want, in, got)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Command tui plays and shows the game in a terminal, without a browser, e.g.
+// on a training box over SSH. It drives breakout.Breakout directly and draws
+// every frame with colored Unicode half blocks.
+//
+// Usage:
+//
+//	tui [flags]                    play with the arrow keys
+//	tui -watch [flags]             watch the built-in bot play
+//	tui -replay game.bkrp [flags]  watch a replay, e.g. downloaded from /replays
+//
+// Keys:
+//   - Left, Right (or a/d, h/l): Move the paddle. A terminal only reports key
+//     presses, so a press holds the key for HOLD_FRAMES frames; holding it
+//     down repeats it. In a replay they jump one second back and forward.
+//   - Space: Release the ball held by the sticky paddle.
+//   - p: Pause. r: Start a new game, or the replay over. q, Esc or ^C: Quit.
+//
+// Flags:
+//   - -seed: Seed of the first game, 0 for a random seed. Every new game
+//     gets a new random seed.
+//   - -config, -levels: Game configuration and level file, as for cmd/web.
+//   - -tick: Frames per second. Defaults to 60.
+//   - -watch: Let the bot play, see internal/bot.
+//   - -bot: Name of the bot to watch. Defaults to "tracker".
+//   - -replay: Replay file to watch.
+//   - -record: Write the replay of the last game to this file on quit.
+//   - -truecolor: Use 24 bit colors. Defaults to true, use -truecolor=false for
+//     terminals with 256 colors.
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// HOLD_FRAMES is the number of frames a key press holds an arrow key
+const HOLD_FRAMES = 15
+
+// GAME_OVER_FRAMES is the number of frames the bot waits after a game over
+// before it starts a new game
+const GAME_OVER_FRAMES = 180
+
+// SEEK_FRAMES is the number of frames an arrow key jumps in a replay
+const SEEK_FRAMES = 60
+
+func main() {
+	seed := flag.Int64("seed", 0, "Seed of the first game. Defaults to 0, a random seed.")
+	configFile := flag.String("config", "", "JSON file with the game configuration. Defaults to the classic playfield.")
+	levelsFile := flag.String("levels", "", "JSON file with level layouts. Defaults to the full brick grid on every level.")
+	tickRate := flag.Int("tick", 60, "Frames per second.")
+	watch := flag.Bool("watch", false, "Watch the built-in bot play.")
+	botName := flag.String("bot", "tracker", fmt.Sprintf("Bot to watch, one of %v.", bot.Names()))
+	replayFile := flag.String("replay", "", "Replay file to watch.")
+	recordFile := flag.String("record", "", "Write the replay of the last game to this file on quit.")
+	trueColor := flag.Bool("truecolor", true, "Use 24 bit colors, false for 256 colors.")
+	flag.Parse()
+	if *tickRate <= 0 {
+		log.Fatalf("Invalid tick rate %d", *tickRate)
+	}
+
+	var t *tui
+	if *replayFile != "" {
+		f, err := os.Open(*replayFile)
+		if err != nil {
+			log.Fatalf("Failed to open the replay: %v", err)
+		}
+		rp, err := replay.Read(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("Failed to read the replay: %v", err)
+		}
+		p, err := replay.NewPlayer(rp)
+		if err != nil {
+			log.Fatalf("Failed to play the replay: %v", err)
+		}
+		t = newReplayTUI(p)
+	} else {
+		cfg, err := breakout.LoadConfig(*configFile, *levelsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var policy bot.Policy
+		if *watch {
+			var err error
+			if policy, err = bot.ByName(*botName, *seed); err != nil {
+				log.Fatal(err)
+			}
+		}
+		t = newGameTUI(cfg, *seed, policy)
+	}
+
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		if t.policy == nil && t.player == nil {
+			log.Fatalf("Playing needs a terminal: %v", err)
+		}
+		restore = func() {} // watch without keys, quit with ^C
+	} else {
+		go readKeys(t.keys)
+	}
+	fmt.Print("\x1b[?1049h\x1b[?25l") // alternate screen, hide the cursor
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	t.run(&screen{trueColor: *trueColor}, *tickRate, quit)
+	fmt.Print("\x1b[0m\x1b[?25h\x1b[?1049l")
+	restore()
+
+	if *recordFile != "" && t.recorder != nil && t.recorder.Len() > 0 {
+		f, err := os.Create(*recordFile)
+		if err == nil {
+			_, err = t.recorder.Replay().WriteTo(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			log.Fatalf("Failed to write the replay: %v", err)
+		}
+		fmt.Printf("Replay written to %s\n", *recordFile)
+	}
+}
+
+// readKeys sends the key presses read from the terminal to keys
+func readKeys(keys chan<- key) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+// tui plays a game, with the keys or a bot, or a replay and handles the
+// keys of the terminal
+type tui struct {
+	keys chan key
+
+	cfg      breakout.Config
+	seed     int64
+	game     *breakout.Breakout
+	policy   bot.Policy       // plays the game, nil for the keys
+	recorder *replay.Recorder // records the game
+	player   *replay.Player   // plays a replay instead of a game
+
+	frame     int  // frames played of the game
+	leftTill  int  // frame up to which the left key is held
+	rightTill int  // frame up to which the right key is held
+	fire      bool // release the held balls on the next frame
+	paused    bool
+	overFor   int // frames since the game is over
+	quit      bool
+}
+
+// newGameTUI returns a tui playing games with cfg, starting with seed.
+// Without a policy the game is played with the keys.
+func newGameTUI(cfg breakout.Config, seed int64, policy bot.Policy) *tui {
+	t := &tui{keys: make(chan key, 16), cfg: cfg, policy: policy}
+	t.newGame(seed)
+	return t
+}
+
+// newReplayTUI returns a tui playing a replay
+func newReplayTUI(p *replay.Player) *tui {
+	return &tui{keys: make(chan key, 16), player: p, game: p.Game()}
+}
+
+// newGame starts a new game with the seed, 0 for a random seed
+func (t *tui) newGame(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.seed = seed
+	t.game = breakout.NewBreakout(t.cfg, seed)
+	t.recorder = replay.NewRecorder(t.cfg, seed)
+	t.frame, t.leftTill, t.rightTill, t.fire, t.overFor = 0, 0, 0, false, 0
+}
+
+// run plays tickRate frames per second and draws them until quit
+func (t *tui) run(s *screen, tickRate int, quit <-chan os.Signal) {
+	ticker := time.NewTicker(time.Second / time.Duration(tickRate))
+	defer ticker.Stop()
+	for !t.quit {
+		select {
+		case <-quit:
+			return
+		case k := <-t.keys:
+			t.press(k)
+		case <-ticker.C:
+			t.tick()
+			cols, rows, err := termSize(int(os.Stdout.Fd()))
+			if err != nil || cols <= 0 || rows <= 1 {
+				cols, rows = 80, 24
+			}
+			state := t.game.GetState()
+			os.Stdout.WriteString(s.frame(&state, t.status(&state), cols, rows))
+		}
+	}
+}
+
+// press handles a key press
+func (t *tui) press(k key) {
+	switch k {
+	case keyQuit:
+		t.quit = true
+	case keyPause:
+		t.paused = !t.paused
+	case keyRestart:
+		if t.player != nil {
+			t.player.Seek(0)
+		} else {
+			t.newGame(0)
+		}
+		t.paused = false
+	case keyLeft:
+		if t.player != nil {
+			t.player.Seek(max(t.player.Frame()-SEEK_FRAMES, 0))
+		} else {
+			t.leftTill, t.rightTill = t.frame+HOLD_FRAMES, 0
+		}
+	case keyRight:
+		if t.player != nil {
+			t.player.Seek(min(t.player.Frame()+SEEK_FRAMES, t.player.Len()))
+		} else {
+			t.leftTill, t.rightTill = 0, t.frame+HOLD_FRAMES
+		}
+	case keyFire:
+		t.fire = true
+	}
+	if t.player != nil {
+		t.game = t.player.Game()
+	}
+}
+
+// tick plays one frame
+func (t *tui) tick() {
+	if t.paused {
+		return
+	}
+	if t.player != nil {
+		t.player.Step()
+		t.game = t.player.Game()
+		return
+	}
+	state := t.game.GetState()
+	if state.Done {
+		t.overFor++
+		if t.policy != nil && t.overFor >= GAME_OVER_FRAMES {
+			t.newGame(0)
+		}
+		return
+	}
+	var in replay.Input
+	if t.policy != nil {
+		in = t.policy.Act(&state)
+	} else {
+		in = replay.NewInput(t.frame < t.leftTill, t.frame < t.rightTill, t.fire)
+		t.fire = false
+	}
+	replay.Play(t.game, in)
+	t.recorder.Record(in, t.game.GetState().Score)
+	t.frame++
+}
+
+// status returns the status line below the game
+func (t *tui) status(state *breakout.BreakoutState) string {
+	line := fmt.Sprintf("Score %d  Level %d  Lives %d", state.Score, state.Level, state.Lives)
+	switch {
+	case t.player != nil:
+		line += fmt.Sprintf("  Replay frame %d/%d", t.player.Frame(), t.player.Len())
+	case t.policy != nil:
+		line += fmt.Sprintf("  Bot  Seed %d", t.seed)
+	default:
+		line += fmt.Sprintf("  Seed %d", t.seed)
+	}
+	switch {
+	case t.paused:
+		line += "  PAUSED (p)"
+	case t.player != nil && t.player.Frame() == t.player.Len():
+		line += "  END, r to watch again"
+	case state.Done && state.Won:
+		line += "  YOU WIN, r for a new game"
+	case state.Done:
+		line += "  GAME OVER, r for a new game"
+	}
+	return line + "  q quits"
+}
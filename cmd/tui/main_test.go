@@ -0,0 +1,57 @@
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"reflect"
+	"testing"
+)
+
+func TestTUIHoldsKeys(t *testing.T) {
+	tu := newGameTUI(breakout.DefaultConfig(), 1, nil)
+	before := tu.game.GetState().PaddleX
+	tu.press(keyLeft)
+	for range 2 * HOLD_FRAMES {
+		tu.tick()
+	}
+	want := before - HOLD_FRAMES*breakout.PADDLE_STEP
+	if got := tu.game.GetState().PaddleX; got != want {
+		t.Errorf("Expected the key held for %d frames, paddle at %d, got %d", HOLD_FRAMES, want, got)
+	}
+	if tu.recorder.Len() != 2*HOLD_FRAMES {
+		t.Errorf("Expected %d recorded frames, got %d", 2*HOLD_FRAMES, tu.recorder.Len())
+	}
+
+	tu.press(keyPause)
+	tu.tick()
+	if tu.frame != 2*HOLD_FRAMES {
+		t.Error("Expected no frame while paused")
+	}
+}
+
+func TestTUIReplay(t *testing.T) {
+	bots := newGameTUI(breakout.DefaultConfig(), 3, bot.NewTracker())
+	for range 500 {
+		bots.tick()
+	}
+	p, err := replay.NewPlayer(bots.recorder.Replay())
+	if err != nil {
+		t.Fatalf("Expected a player, got %v", err)
+	}
+	tu := newReplayTUI(p)
+	for range 500 {
+		tu.tick()
+	}
+	if !reflect.DeepEqual(tu.game.Snapshot(), bots.game.Snapshot()) {
+		t.Error("Expected the replay to show the game of the bot")
+	}
+	tu.press(keyLeft)
+	if p.Frame() != 500-SEEK_FRAMES {
+		t.Errorf("Expected to jump back to frame %d, got %d", 500-SEEK_FRAMES, p.Frame())
+	}
+	tu.press(keyRestart)
+	if p.Frame() != 0 || tu.game != p.Game() {
+		t.Errorf("Expected the replay to start over, got frame %d", p.Frame())
+	}
+}
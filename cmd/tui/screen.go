@@ -0,0 +1,73 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"fmt"
+	"strings"
+)
+
+// screen draws game states into a terminal with Unicode half blocks: every
+// character cell shows two pixels of the bitmap (env.DrawBitmap), the upper
+// one in the foreground and the lower one in the background color.
+type screen struct {
+	trueColor  bool // 24 bit colors, otherwise the 256 color palette
+	cols, rows int  // terminal size of the last frame
+}
+
+// frame returns the escape sequences that draw the state and the status
+// line into a terminal of cols x rows characters
+func (s *screen) frame(state *breakout.BreakoutState, status string, cols, rows int) string {
+	var b strings.Builder
+	if cols != s.cols || rows != s.rows {
+		b.WriteString("\x1b[2J") // clear what the old size left behind
+		s.cols, s.rows = cols, rows
+	}
+	b.WriteString("\x1b[H")
+	w, h := fit(state.Width, state.Height, cols, rows-1)
+	bitmap := env.DrawBitmap(state, 2*h, w)
+	pad := strings.Repeat(" ", (cols-w)/2)
+	for y := range h {
+		b.WriteString(pad)
+		fg, bg := -1, -1
+		for x := range w {
+			top, bottom := bitmap[2*y][x], bitmap[2*y+1][x]
+			if top != fg {
+				b.WriteString(s.color(38, top))
+				fg = top
+			}
+			if bottom != bg {
+				b.WriteString(s.color(48, bottom))
+				bg = bottom
+			}
+			b.WriteString("▀")
+		}
+		b.WriteString("\x1b[0m\x1b[K\r\n")
+	}
+	b.WriteString("\x1b[0m\x1b[J")
+	if len(status) > cols {
+		status = status[:cols]
+	}
+	b.WriteString(status)
+	return b.String()
+}
+
+// color returns the escape sequence that sets the foreground (38) or
+// background (48) to the color of a bitmap cell
+func (s *screen) color(layer, cell int) string {
+	c := env.CellColor(cell)
+	if s.trueColor {
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, c[0], c[1], c[2])
+	}
+	// nearest color of the 6x6x6 cube of the 256 color palette
+	cube := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	return fmt.Sprintf("\x1b[%d;5;%dm", layer, 16+36*cube(c[0])+6*cube(c[1])+cube(c[2]))
+}
+
+// fit returns the size in characters of the largest picture of a game area
+// of width x height that fits into cols x rows characters of two pixels,
+// keeping its aspect ratio and never scaling it up
+func fit(width, height, cols, rows int) (int, int) {
+	scale := min(float64(cols)/float64(width), float64(2*rows)/float64(height), 1)
+	return max(int(float64(width)*scale), 1), max(int(float64(height)*scale/2), 1)
+}
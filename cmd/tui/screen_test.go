@@ -0,0 +1,51 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"strings"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		cols, rows   int
+		wantW, wantH int
+	}{
+		{1000, 1000, 182, 120}, // never scaled up
+		{91, 1000, 91, 60},     // limited by the width
+		{1000, 30, 45, 30},     // limited by the height
+	}
+	for _, tt := range tests {
+		w, h := fit(182, 240, tt.cols, tt.rows)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("Expected %dx%d for %dx%d characters, got %dx%d", tt.wantW, tt.wantH, tt.cols, tt.rows, w, h)
+		}
+	}
+}
+
+func TestScreenFrame(t *testing.T) {
+	state := breakout.NewBreakout(breakout.DefaultConfig(), 1).GetState()
+	s := &screen{trueColor: true}
+	out := s.frame(&state, "Score 0", 80, 25)
+
+	if !strings.HasPrefix(out, "\x1b[2J\x1b[H") {
+		t.Error("Expected the first frame to clear the screen")
+	}
+	if got := strings.Count(out, "\r\n"); got != 24 {
+		t.Errorf("Expected 24 lines of the game, got %d", got)
+	}
+	if !strings.Contains(out, "\x1b[38;2;255;0;0m") {
+		t.Error("Expected red bricks")
+	}
+	if !strings.HasSuffix(out, "Score 0") {
+		t.Error("Expected the status line last")
+	}
+	if out := s.frame(&state, "", 80, 25); strings.Contains(out, "\x1b[2J") {
+		t.Error("Expected no clear without a resize")
+	}
+
+	s.trueColor = false
+	if out := s.frame(&state, "", 80, 25); !strings.Contains(out, "\x1b[38;5;196m") {
+		t.Error("Expected red bricks in the 256 color palette")
+	}
+}
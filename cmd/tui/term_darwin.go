@@ -0,0 +1,9 @@
+package main
+
+import "syscall"
+
+// termios requests of ioctl
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// errNoTerminal is returned where the raw terminal is not supported
+var errNoTerminal = errors.New("raw terminal mode is not supported on this system")
+
+// makeRaw is not supported, see term_unix.go
+func makeRaw(fd int) (func(), error) {
+	return nil, errNoTerminal
+}
+
+// termSize is not supported, see term_unix.go
+func termSize(fd int) (int, int, error) {
+	return 0, 0, errNoTerminal
+}
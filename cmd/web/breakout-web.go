@@ -1,16 +1,20 @@
 package main
 
 import (
+	"breakout-go/internal/bot"
 	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"breakout-go/internal/replay"
+	"breakout-go/internal/session"
 	_ "embed"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
-	"math"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 )
 
 //go:embed index.html
@@ -20,35 +24,153 @@ var indexHTML []byte
 // parses command-line flags, and sets up HTTP handlers for serving the game
 // and managing its state.
 //
-// The server supports two modes:
+// The server supports three modes:
 // - Human player mode: Allows a human player to control the paddle.
 // - AI player mode: Allows an AI to control the paddle.
+// - Bot mode: A built-in bot (see internal/bot) plays on the server, as a
+//   baseline and an attract mode, every client just watches.
 //
 // Command-line flags:
 // - -aibot: A boolean flag to enable AI player mode. Defaults to false (human player mode).
+// - -bot: Name of the built-in bot that plays in bot mode, one of
+//   "predictive", "random" and "tracker". A new game starts three seconds
+//   after every game over. Cannot be combined with -aibot or -lockstep.
+// - -seed: Seed for the game engine. Every game (including after /reset) is
+//   started from this seed, so runs can be replayed. Defaults to 0, which
+//   picks a new time based seed for every game and logs it, so 0 cannot be
+//   chosen as a seed itself.
+// - -config: Path to a JSON file with the game configuration (breakout.Config).
+//   Fields missing from the file keep their default values.
+// - -levels: Path to a level file (see breakout.LoadLevels). Level N of the
+//   game is built from layout N of the file.
+// - -tick: Frames per second the server plays. The server advances the game on
+//   its own at this rate, clients send their input asynchronously and get
+//   the state of the latest frame. Defaults to 60.
+// - -lockstep: Advance the game by exactly one frame on every request to
+//   "/ai-state" (or "/game-state" in human player mode) instead of at the
+//   tick rate, for training. Defaults to true in AI player mode.
+// - -max-sessions: Number of game sessions (see "/sessions") that can be live
+//   at the same time. Defaults to 64.
+// - -session-idle: Sessions that are not used for this long are removed.
+//   Defaults to 10 minutes.
+// - -replays: Directory the replays of the played games are stored in.
+//   Defaults to "", keeping them in memory.
+// - -max-replays: Number of replays that are kept, the oldest are dropped.
+//   Defaults to 100, 0 for no limit.
 //
 // The following HTTP endpoints are provided:
 //   - "/" (GET): Serves the static HTML file for the game interface.
 //   - "/reset" (POST): Resets the game state to its initial configuration.
-//   - "/game-state" (POST): Updates the game state based on player input and
-//     returns the current game state as JSON.
-//   - "/ai-state" (POST): Updates the game state based on AI input and returns
-//     the AI-specific game state, including action, reward, and game status.
+//   - "/game-state" (POST): Sets the player input and returns the game state
+//     of the next frame as JSON.
+//   - "/ai-state" (POST): Sets the AI input and returns the AI-specific game
+//     state of the next frame, including action, reward, and game status.
+//     Like the Gym-style endpoints it can send the bitmap in a binary format
+//     instead of JSON, see registerEnv.
+//   - "/ws" (GET): WebSocket stream of the game. The server sends the full
+//     state once and then only the changes of every frame, the client sends
+//     key-down and key-up events as player input. Not available in human
+//     player mode with -lockstep, where the game only advances on requests.
+//   - "/snapshot" (GET): Returns the full game state (breakout.Snapshot) as
+//     JSON, to save the game.
+//   - "/snapshot" (POST): Replaces the game with a saved snapshot, to resume it.
+//   - "/sessions" (POST), "/sessions/{id}/state" (GET), "/sessions/{id}/step"
+//     (POST), "/sessions/{id}/reset" (POST), "/sessions/{id}" (DELETE): Games
+//     of their own for every client, see registerSessions.
+//   - "/env/reset" (POST), "/env/step" (POST): Gym-style episodes on the
+//     session games, see registerEnv.
+//   - "/vec/reset" (POST), "/vec/step" (POST): Many Gym-style episodes stepped
+//     together in one request, see registerEnv.
+//   - "/replays" (GET, POST), "/replays/{id}" (GET), "/replays/{id}/frames"
+//     (GET): Recorded games to list, upload, download and watch, see
+//     registerReplays.
 //
 // The server listens on a port specified by the PORT environment variable.
 // If the PORT variable is not set, it defaults to port 8080.
 func main() {
 	port := "8080"
 	aibot := flag.Bool("aibot", false, "Run as AI player. Defaults to human player.")
+	botName := flag.String("bot", "", fmt.Sprintf("Let a built-in bot play on the server, one of %v.", bot.Names()))
+	seed := flag.Int64("seed", 0, "Game seed. Defaults to 0, a new time based seed for every game that is logged.")
+	configFile := flag.String("config", "", "JSON file with the game configuration. Defaults to the classic playfield.")
+	levelsFile := flag.String("levels", "", "JSON file with level layouts. Defaults to the full brick grid on every level.")
+	tickRate := flag.Int("tick", 60, "Frames per second the server plays.")
+	maxSessions := flag.Int("max-sessions", 64, "Number of game sessions that can be live at the same time.")
+	sessionIdle := flag.Duration("session-idle", 10*time.Minute, "Remove sessions that are not used for this long.")
+	replayDir := flag.String("replays", "", "Directory to store the replays of the played games in. Defaults to memory.")
+	maxReplays := flag.Int("max-replays", 100, "Number of replays that are kept, 0 for no limit.")
+	lockstep := flag.Bool("lockstep", false, "Advance one frame per request instead of at the tick rate. Defaults to true in AI player mode.")
 	flag.Parse()
-	humanPlayer := !*aibot
-	if humanPlayer {
+	humanPlayer := !*aibot && *botName == ""
+	lockstepSet := false
+	flag.Visit(func(f *flag.Flag) { lockstepSet = lockstepSet || f.Name == "lockstep" })
+	if *aibot && !lockstepSet {
+		*lockstep = true
+	}
+	var policy bot.Policy
+	if *botName != "" {
+		if *aibot || *lockstep {
+			log.Fatal("-bot cannot be combined with -aibot or -lockstep")
+		}
+		var err error
+		if policy, err = bot.ByName(*botName, *seed); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *tickRate <= 0 {
+		log.Fatalf("Invalid tick rate %d", *tickRate)
+	}
+	switch {
+	case policy != nil:
+		fmt.Printf("Running in bot mode with the %s bot\n", *botName)
+	case humanPlayer:
 		fmt.Println("Running in human player mode")
-	} else {
+	default:
 		fmt.Println("Running in AI player mode")
 	}
 
-	game := breakout.NewBreakout()
+	config, err := breakout.LoadConfig(*configFile, *levelsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	newGame := func() *breakout.Breakout {
+		gameSeed := *seed
+		if gameSeed == 0 {
+			gameSeed = time.Now().UnixNano()
+		}
+		log.Printf("Starting game with seed %d", gameSeed)
+		return breakout.NewBreakout(config, gameSeed)
+	}
+	loop := newGameLoop(newGame)
+	loop.SetPolicy(policy)
+	replays, err := replay.NewStore(*replayDir, *maxReplays)
+	if err != nil {
+		log.Fatalf("Failed to open the replay store: %v", err)
+	}
+	loop.OnReplay(func(rp *replay.Replay) {
+		info, err := replays.Save(rp)
+		if err != nil {
+			log.Printf("Failed to store the replay: %v", err)
+			return
+		}
+		log.Printf("Stored replay %s of %d frames, score %d", info.ID, info.Frames, info.Score)
+	})
+	registerReplays(http.DefaultServeMux, replays)
+	if *lockstep {
+		fmt.Println("Advancing one frame per request (lock-step)")
+	} else {
+		fmt.Printf("Playing %d frames per second\n", *tickRate)
+		go loop.Run(*tickRate, nil)
+	}
+	// frameTimeout bounds how long a request waits for the next frame
+	frameTimeout := 10 * time.Second / time.Duration(*tickRate)
+
+	// games of their own for every client
+	sessions := session.NewManager(config, *maxSessions, *sessionIdle)
+	go sessions.Run(nil)
+	registerSessions(http.DefaultServeMux, sessions)
+	registerEnv(http.DefaultServeMux, sessions)
 
 	// Serve the static HTML file
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -66,12 +188,46 @@ func main() {
 
 	// reset the game state
 	http.HandleFunc("/reset", func(w http.ResponseWriter, r *http.Request) {
-		game = breakout.NewBreakout()
+		loop.Reset()
 		w.WriteHeader(http.StatusOK)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"message": "Game reset"})
 	})
 
+	// stream the game over a WebSocket
+	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		if humanPlayer && *lockstep {
+			http.Error(w, "Not available in lock-step mode", http.StatusConflict)
+			return
+		}
+		serveWS(loop, humanPlayer)(w, r)
+	})
+
+	// save (GET) and resume (POST) the game
+	http.HandleFunc("/snapshot", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(loop.Snapshot())
+		case http.MethodPost:
+			var snapshot breakout.Snapshot
+			if err := json.NewDecoder(r.Body).Decode(&snapshot); err != nil {
+				http.Error(w, "Failed to parse JSON", http.StatusBadRequest)
+				return
+			}
+			restored := new(breakout.Breakout)
+			if err := restored.Restore(snapshot); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			loop.Replace(restored)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"message": "Game restored"})
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// Handle game state updates via POST requests
 	http.HandleFunc("/game-state", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
@@ -79,6 +235,7 @@ func main() {
 			var input struct {
 				Left  bool `json:"left"`
 				Right bool `json:"right"`
+				Fire  bool `json:"fire"` // release the ball held by the sticky paddle
 			}
 
 			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -86,19 +243,20 @@ func main() {
 				return
 			}
 
-			// Log the action received from the client
+			// only the human player controls the paddle, otherwise the page
+			// just watches the AI play
 			if humanPlayer {
-				if input.Left && !input.Right {
-					game.PaddleLeft()
-				} else if input.Right && !input.Left {
-					game.PaddleRight()
+				loop.SetInput(input.Left, input.Right, input.Fire)
+				if *lockstep {
+					loop.Step()
 				}
-				game.MoveBall()
 			}
-			// time.Sleep(20 * time.Millisecond)
+			if !*lockstep {
+				loop.Wait(frameTimeout)
+			}
 		}
 		// Serve the game state as JSON
-		state := game.GetState()
+		state, _ := loop.State()
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(state)
 	})
@@ -106,8 +264,14 @@ func main() {
 	// serve AI player
 	// add AI handle at /ai-state
 	http.HandleFunc("/ai-state", func(w http.ResponseWriter, r *http.Request) {
+		format, err := requestFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		action := 0
-		score := game.GetState().Score
+		before, _ := loop.State()
+		score := before.Score
 		if r.Method == http.MethodPost {
 			// Parse the form data
 			var input struct {
@@ -120,27 +284,30 @@ func main() {
 			// Log the action received from the client
 			// only action 1 and 2 is valid to move paddle
 			// action 0 is no action
-			if !humanPlayer {
+			if *aibot {
 				action = input.Action
-				if action == 1 {
-					game.PaddleLeft()
-				} else if action == 2 {
-					game.PaddleRight()
+				loop.SetInput(action == 1, action == 2, false)
+				if *lockstep {
+					loop.Step()
+				} else {
+					loop.Wait(frameTimeout)
 				}
-				game.MoveBall()
 			}
 		}
 
 		var aiState struct {
 			Action int     `json:"action"`
 			Reward float64 `json:"reward"`
-			State  [][]int `json:"state"`
+			State  [][]int `json:"state,omitempty"`
 			Done   bool    `json:"done"`
 			Lives  int     `json:"lives"`
 		}
 		// Serve the game state as JSON
-		state := game.GetState()
-		aiState.State = BreakoutState2Bitmap(&state)
+		state, _ := loop.State()
+		bitmap := env.BreakoutState2Bitmap(&state)
+		if format == formatJSON {
+			aiState.State = bitmap
+		}
 		aiState.Action = action
 		if state.Score > score {
 			aiState.Reward = 1.0
@@ -148,9 +315,9 @@ func main() {
 			aiState.Reward = 0.0
 		}
 		aiState.Done = state.Done
-		aiState.Lives = 5 - state.Live + 1
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(aiState)
+		aiState.Lives = state.Lives
+		obs := env.BitmapObservation(bitmap)
+		writeObservation(w, format, &obs, env.DTYPE_UINT8, aiState)
 	})
 
 	// Start the server
@@ -165,96 +332,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-// BreakoutState2Bitmap converts the state of a Breakout game into a 2D bitmap representation.
-// Each element in the bitmap corresponds to a specific part of the game:
-// - 0: Empty space
-// - 1: Yellow brick
-// - 2: Green brick
-// - 3: Orange brick
-// - 4: Red brick
-// - 5: Paddle
-// - 6: Ball
-//
-// The function scales down the game state by a factor to fit into a fixed bitmap size.
-// It processes the ball, paddle, and bricks, mapping their positions and dimensions
-// to the bitmap grid.
-//
-// Parameters:
-// - state: A pointer to a BreakoutState struct containing the current game state.
-//
-// Returns:
-// - A 2D slice of integers representing the bitmap of the game state.
-//   size of the slice is 80x60 (height x width) - compression factor around 3
-
-func BreakoutState2Bitmap(state *breakout.BreakoutState) [][]int {
-	colormap := map[string]int{
-		"red":    4,
-		"orange": 3,
-		"green":  2,
-		"yellow": 1,
-	}
-	bitH := 80
-	bitW := 60
-	factor := 3
-	// Initialize the bitmap with empty spaces
-	bitmap := make([][]int, bitH)
-	for i := range bitmap {
-		bitmap[i] = make([]int, bitW)
-		for j := range bitmap[i] {
-			bitmap[i][j] = 0 // Empty space
-		}
-	}
-	// Draw the ball
-	ballX := state.BallX / factor
-	ballY := state.BallY / factor
-	ballRadius := state.BallRadius / factor
-	for i := -ballRadius; i <= ballRadius; i++ {
-		for j := -ballRadius; j <= ballRadius; j++ {
-			if i*i+j*j <= ballRadius*ballRadius {
-				x := ballX + i
-				y := ballY + j
-				if x >= 0 && x < bitW && y >= 0 && y < bitH {
-					bitmap[y][x] = 6 // Ball
-				}
-			}
-		}
-	}
-	// Draw the paddle
-	paddleX := state.PaddleX / factor
-	paddleY := bitH - state.PaddleHeight/factor
-	paddleWidth := state.PaddleWidth / factor
-	for i := 0; i < paddleWidth; i++ {
-		for j := 0; j < state.PaddleHeight/factor; j++ {
-			x := paddleX + i
-			y := paddleY + j
-			if x >= 0 && x < bitW && y >= 0 && y < bitH {
-				bitmap[y][x] = 5 // Paddle
-			}
-		}
-	}
-
-	// Draw the bricks
-	for _, brick := range state.Bricks {
-		brickX := float64(brick.X) / float64(factor)
-		brickY := float64(brick.Y) / float64(factor)
-		brickWidth := float64(brick.Width) / float64(factor)
-		brickHeight := float64(brick.Height) / float64(factor)
-		color := brick.Color
-		colorValue := 0
-		if val, ok := colormap[color]; ok {
-			colorValue = val
-		}
-		for i := 0.0; i < brickWidth; i++ {
-			for j := 0.0; j < brickHeight; j++ {
-				x := int(math.Round(brickX + i))
-				y := int(math.Round(brickY + j))
-				if x >= 0 && x < bitW && y >= 0 && y < bitH {
-					bitmap[y][x] = colorValue // Brick
-				}
-			}
-		}
-	}
-
-	return bitmap
-}
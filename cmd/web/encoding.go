@@ -0,0 +1,111 @@
+package main
+
+import (
+	"breakout-go/internal/env"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// obsFormat is the encoding of the observation in an AI response
+type obsFormat int
+
+const (
+	formatJSON obsFormat = iota // the whole response as JSON
+	formatRaw                   // uint8 bytes, see env.EncodeRaw
+	formatNPY                   // a .npy file, see env.EncodeNPY
+)
+
+// Content types of the binary formats
+const (
+	CONTENT_TYPE_RAW = "application/octet-stream"
+	CONTENT_TYPE_NPY = "application/x-npy"
+)
+
+// HEADER_SHAPE is the header with the observation shape of a binary
+// response, e.g. "3,80,60"
+const HEADER_SHAPE = "X-Observation-Shape"
+
+// META_LENGTH_SIZE is the size of the length of the metadata that starts the
+// body of a binary response, a little endian uint32
+const META_LENGTH_SIZE = 4
+
+// requestFormat returns the observation format asked for by the query
+// parameter "format" (json, raw or npy) or else by the Accept header.
+// Unknown Accept types fall back to JSON.
+func requestFormat(r *http.Request) (obsFormat, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+	case "json":
+		return formatJSON, nil
+	case "raw":
+		return formatRaw, nil
+	case "npy":
+		return formatNPY, nil
+	default:
+		return formatJSON, fmt.Errorf("unknown format %q", format)
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			return formatJSON, nil
+		case CONTENT_TYPE_RAW:
+			return formatRaw, nil
+		case CONTENT_TYPE_NPY:
+			return formatNPY, nil
+		}
+	}
+	return formatJSON, nil
+}
+
+// writeObservation writes resp as JSON, or in a binary format the
+// observation obs of the dtype of its space. obs points into resp; for a
+// binary format it is cleared and the rest of resp is written as JSON before
+// the observation, so the observation field of resp should be tagged
+// omitzero. The body of a binary response is the length of the JSON
+// metadata (META_LENGTH_SIZE bytes), the metadata and the observation. The
+// metadata of a large vector batch does not fit into a header.
+func writeObservation(w http.ResponseWriter, format obsFormat, obs *env.Observation, dtype string, resp any) {
+	if format == formatJSON {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	var body []byte
+	var err error
+	contentType := CONTENT_TYPE_NPY
+	if format == formatRaw {
+		body, err = env.EncodeRaw(*obs, dtype)
+		contentType = CONTENT_TYPE_RAW
+	} else {
+		body, err = env.EncodeNPY(*obs, dtype)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		return
+	}
+	dims := make([]string, len(obs.Shape))
+	for i, d := range obs.Shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	*obs = env.Observation{}
+	meta, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(META_LENGTH_SIZE+len(meta)+len(body)))
+	w.Header().Set(HEADER_SHAPE, strings.Join(dims, ","))
+	w.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(meta))))
+	w.Write(meta)
+	w.Write(body)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"breakout-go/internal/env"
+	"breakout-go/internal/session"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestFormat(t *testing.T) {
+	tests := []struct {
+		url, accept string
+		want        obsFormat
+	}{
+		{"/env/step", "", formatJSON},
+		{"/env/step?format=npy", "", formatNPY},
+		{"/env/step?format=raw", CONTENT_TYPE_NPY, formatRaw},
+		{"/env/step", "application/x-npy; q=0.9, application/json", formatNPY},
+		{"/env/step", "text/html, application/octet-stream", formatRaw},
+		{"/env/step", "*/*", formatJSON},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, test.url, nil)
+		r.Header.Set("Accept", test.accept)
+		got, err := requestFormat(r)
+		if err != nil || got != test.want {
+			t.Errorf("Expected format %d for %s with Accept %q, got %d (%v)", test.want, test.url, test.accept, got, err)
+		}
+	}
+	if _, err := requestFormat(httptest.NewRequest(http.MethodPost, "/env/step?format=png", nil)); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
+
+// postBinary posts body with the Accept header and returns the response, the
+// JSON metadata and the observation of its body
+func postBinary(t *testing.T, url, accept, body string) (*http.Response, []byte, []byte) {
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Accept", accept)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected POST %s to succeed, got %v", url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for %s, got %d: %s", url, resp.StatusCode, data)
+	}
+	if len(data) < META_LENGTH_SIZE {
+		t.Fatalf("Expected the metadata length in the body of %s, got %d bytes", url, len(data))
+	}
+	n := int(binary.LittleEndian.Uint32(data))
+	if len(data) < META_LENGTH_SIZE+n {
+		t.Fatalf("Expected %d bytes of metadata in the body of %s, got %d bytes", n, url, len(data))
+	}
+	return resp, data[META_LENGTH_SIZE : META_LENGTH_SIZE+n], data[META_LENGTH_SIZE+n:]
+}
+
+func TestEnvBinaryObservation(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset envResetResponse
+	postJSON(t, srv.URL+"/env/reset", `{"seed": 3}`, http.StatusOK, &reset)
+
+	resp, metaData, data := postBinary(t, srv.URL+"/env/step", CONTENT_TYPE_RAW, `{"session": "`+reset.Session+`", "action": 1}`)
+	if resp.Header.Get("Content-Type") != CONTENT_TYPE_RAW {
+		t.Errorf("Expected content type %s, got %s", CONTENT_TYPE_RAW, resp.Header.Get("Content-Type"))
+	}
+	if len(data) != len(reset.Observation.Data) {
+		t.Errorf("Expected %d bytes, got %d", len(reset.Observation.Data), len(data))
+	}
+	if resp.Header.Get(HEADER_SHAPE) != "80,60" {
+		t.Errorf("Expected shape 80,60, got %q", resp.Header.Get(HEADER_SHAPE))
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(metaData, &meta); err != nil {
+		t.Fatalf("Expected JSON metadata, got %v", err)
+	}
+	if _, ok := meta["observation"]; ok {
+		t.Error("Expected the metadata without the observation")
+	}
+	if _, ok := meta["reward"]; !ok {
+		t.Error("Expected the reward in the metadata")
+	}
+}
+
+func TestVecBinaryObservation(t *testing.T) {
+	srv := newEnvServer(t)
+
+	_, metaData, data := postBinary(t, srv.URL+"/vec/reset?format=npy", "", `{"num_envs": 2, "seed": 4}`)
+	obs, err := env.DecodeNPY(data)
+	if err != nil {
+		t.Fatalf("Expected a .npy body, got %v", err)
+	}
+	if len(obs.Shape) != 3 || obs.Shape[0] != 2 {
+		t.Errorf("Expected a batch of 2 observations, got shape %v", obs.Shape)
+	}
+	var meta vecResetResponse
+	if err := json.Unmarshal(metaData, &meta); err != nil || meta.Session == "" {
+		t.Fatalf("Expected the session in the metadata, got %v", err)
+	}
+
+	_, _, data = postBinary(t, srv.URL+"/vec/step?format=npy", "", `{"session": "`+meta.Session+`", "actions": [0, 2]}`)
+	if obs, err = env.DecodeNPY(data); err != nil || obs.Shape[0] != 2 {
+		t.Errorf("Expected a batch of 2 observations, got %v", err)
+	}
+}
+
+func TestVecBinaryObservationMaxEnvs(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset vecResetResponse
+	postJSON(t, srv.URL+"/vec/reset", fmt.Sprintf(`{"num_envs": %d, "seed": 4}`, session.MAX_VEC_ENVS), http.StatusOK, &reset)
+
+	// the infos of the biggest batch are too large for a header
+	actions := strings.TrimSuffix(strings.Repeat("1, ", session.MAX_VEC_ENVS), ", ")
+	_, metaData, data := postBinary(t, srv.URL+"/vec/step?format=npy", "", `{"session": "`+reset.Session+`", "actions": [`+actions+`]}`)
+	var meta vecStepResponse
+	if err := json.Unmarshal(metaData, &meta); err != nil || len(meta.Infos) != session.MAX_VEC_ENVS {
+		t.Fatalf("Expected the infos of %d envs in the metadata, got %d (%v)", session.MAX_VEC_ENVS, len(meta.Infos), err)
+	}
+	if obs, err := env.DecodeNPY(data); err != nil || obs.Shape[0] != session.MAX_VEC_ENVS {
+		t.Errorf("Expected a batch of %d observations, got %v", session.MAX_VEC_ENVS, err)
+	}
+}
+
+func TestFeaturesBinaryObservation(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset envResetResponse
+	postJSON(t, srv.URL+"/env/reset", `{"seed": 3, "observation": {"mode": "features"}}`, http.StatusOK, &reset)
+
+	// the npy type follows the space, not the values
+	_, _, data := postBinary(t, srv.URL+"/env/step?format=npy", "", `{"session": "`+reset.Session+`", "action": 0}`)
+	if !strings.Contains(string(data), "'descr': '<f4'") {
+		t.Error("Expected float32 values for the features observation")
+	}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/env/step?format=raw", strings.NewReader(`{"session": "`+reset.Session+`", "action": 0}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("Expected status %d for raw features, got %d", http.StatusNotAcceptable, resp.StatusCode)
+	}
+}
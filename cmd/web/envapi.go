@@ -0,0 +1,218 @@
+package main
+
+import (
+	"breakout-go/internal/env"
+	"breakout-go/internal/session"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// envResetResponse is the answer of /env/reset
+type envResetResponse struct {
+	Session     string          `json:"session"`
+	Space       env.Space       `json:"space"`
+	Observation env.Observation `json:"observation,omitzero"`
+	Info        env.Info        `json:"info"`
+}
+
+// envStepResponse is the answer of /env/step
+type envStepResponse struct {
+	Observation env.Observation `json:"observation,omitzero"`
+	Reward      float64         `json:"reward"`
+	Terminated  bool            `json:"terminated"` // the game is over
+	Truncated   bool            `json:"truncated"`  // the episode reached the step limit
+	Info        env.Info        `json:"info"`
+}
+
+// vecResetResponse is the answer of /vec/reset
+type vecResetResponse struct {
+	Session      string          `json:"session"`
+	Space        env.Space       `json:"space"`                 // of the stacked observations
+	Observations env.Observation `json:"observations,omitzero"` // stacked, shape [N, ...]
+}
+
+// vecStepResponse is the answer of /vec/step
+type vecStepResponse struct {
+	Observations env.Observation `json:"observations,omitzero"` // stacked, shape [N, ...]
+	Rewards      []float64       `json:"rewards"`
+	Terminated   []bool          `json:"terminated"`
+	Truncated    []bool          `json:"truncated"`
+	Infos        []env.Info      `json:"infos"`
+}
+
+// registerEnv adds the Gym-style endpoints to mux. They play the games of
+// the session manager, see internal/env:
+//   - "POST /env/reset": Starts a new episode. Without a "session" in the body
+//     a new session is created with the options of "POST /sessions", e.g.
+//     {"seed": 5, "max_steps": 1000, "observation": {"mode": "features"},
+//     "wrappers": {"frame_skip": 4, "frame_stack": 4, "sticky_actions": 0.25}}.
+//     With a "session" its game is reset, a "seed" of 0 replays the seed of
+//     the session. The answer describes the observations by their "space".
+//   - "POST /env/step": Applies {"session": id, "action": 0|1|2} and returns
+//     the observation, reward, terminated, truncated and info.
+//   - "POST /vec/reset": Like /env/reset for a vector session (env.VecEnv) of
+//     "num_envs" games, stepped by up to "workers" goroutines (0 for one per
+//     CPU). The observations are stacked into one of shape [N, ...].
+//   - "POST /vec/step": Applies {"session": id, "actions": [...]}, one action
+//     per game, and returns N observations (stacked), rewards, terminated,
+//     truncated flags and infos. Finished episodes are reset automatically.
+//
+// The observations are JSON by default. With "?format=raw|npy" or an Accept
+// header of CONTENT_TYPE_RAW or CONTENT_TYPE_NPY the body is the binary
+// observation instead and the other fields move into headers, see
+// writeObservation.
+func registerEnv(mux *http.ServeMux, sessions *session.Manager) {
+	mux.HandleFunc("POST /env/reset", func(w http.ResponseWriter, r *http.Request) {
+		format, err := requestFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var input struct {
+			Session string `json:"session"`
+			sessionOptions
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && err != io.EOF {
+			http.Error(w, "Failed to parse JSON", http.StatusBadRequest)
+			return
+		}
+		var s *session.Session
+		var obs env.Observation
+		if input.Session == "" {
+			var ok bool
+			if s, ok = createSession(w, sessions, input.sessionOptions); !ok {
+				return
+			}
+			obs = s.Observation() // Create started the first game
+		} else {
+			var ok bool
+			if s, ok = sessions.Get(input.Session); !ok {
+				http.Error(w, "Unknown session", http.StatusNotFound)
+				return
+			}
+			obs = s.Reset(input.Seed)
+		}
+		resp := envResetResponse{Session: s.ID(), Space: s.Space(), Observation: obs, Info: s.Info()}
+		writeObservation(w, format, &resp.Observation, resp.Space.DType, &resp)
+	})
+
+	mux.HandleFunc("POST /env/step", func(w http.ResponseWriter, r *http.Request) {
+		format, err := requestFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var input struct {
+			Session string `json:"session"`
+			Action  int    `json:"action"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Failed to parse JSON", http.StatusBadRequest)
+			return
+		}
+		s, ok := sessions.Get(input.Session)
+		if !ok {
+			http.Error(w, "Unknown session", http.StatusNotFound)
+			return
+		}
+		res, err := s.Step(env.Action(input.Action))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := envStepResponse{
+			Observation: res.Observation,
+			Reward:      res.Reward,
+			Terminated:  res.Terminated,
+			Truncated:   res.Truncated,
+			Info:        res.Info,
+		}
+		writeObservation(w, format, &resp.Observation, s.Space().DType, &resp)
+	})
+
+	mux.HandleFunc("POST /vec/reset", func(w http.ResponseWriter, r *http.Request) {
+		format, err := requestFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var input struct {
+			Session string `json:"session"`
+			NumEnvs int    `json:"num_envs"`
+			Workers int    `json:"workers"`
+			sessionOptions
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && err != io.EOF {
+			http.Error(w, "Failed to parse JSON", http.StatusBadRequest)
+			return
+		}
+		var s *session.VecSession
+		var obs []env.Observation
+		if input.Session == "" {
+			if input.MaxSteps < 0 {
+				http.Error(w, "Invalid max_steps", http.StatusBadRequest)
+				return
+			}
+			var err error
+			if s, err = sessions.CreateVec(input.options(), input.NumEnvs, input.Workers); err != nil {
+				createError(w, err)
+				return
+			}
+			obs = s.Observations() // CreateVec started the first games
+		} else {
+			var ok bool
+			if s, ok = sessions.GetVec(input.Session); !ok {
+				http.Error(w, "Unknown session", http.StatusNotFound)
+				return
+			}
+			obs = s.Reset(input.Seed)
+		}
+		stacked, err := env.Stack(obs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp := vecResetResponse{Session: s.ID(), Space: s.Space(), Observations: stacked}
+		writeObservation(w, format, &resp.Observations, resp.Space.DType, &resp)
+	})
+
+	mux.HandleFunc("POST /vec/step", func(w http.ResponseWriter, r *http.Request) {
+		format, err := requestFormat(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var input struct {
+			Session string       `json:"session"`
+			Actions []env.Action `json:"actions"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Failed to parse JSON", http.StatusBadRequest)
+			return
+		}
+		s, ok := sessions.GetVec(input.Session)
+		if !ok {
+			http.Error(w, "Unknown session", http.StatusNotFound)
+			return
+		}
+		res, err := s.Step(input.Actions)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stacked, err := env.Stack(res.Observations)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp := vecStepResponse{
+			Observations: stacked,
+			Rewards:      res.Rewards,
+			Terminated:   res.Terminated,
+			Truncated:    res.Truncated,
+			Infos:        res.Infos,
+		}
+		writeObservation(w, format, &resp.Observations, s.Space().DType, &resp)
+	})
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/session"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEnvServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	registerEnv(mux, session.NewManager(breakout.DefaultConfig(), 4, time.Minute))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// postJSON posts body and decodes the answer into v
+func postJSON(t *testing.T, url, body string, wantStatus int, v any) {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Expected POST %s to succeed, got %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("Expected status %d for %s, got %d", wantStatus, url, resp.StatusCode)
+	}
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("Expected JSON response, got %v", err)
+		}
+	}
+}
+
+func TestEnvResetAndStep(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset envResetResponse
+	postJSON(t, srv.URL+"/env/reset", `{"seed": 3, "max_steps": 2}`, http.StatusOK, &reset)
+	if reset.Session == "" || len(reset.Observation.Shape) != 2 {
+		t.Fatalf("Expected a session and a 2D observation, got %+v", reset)
+	}
+	if reset.Info.Lives != breakout.MAX_LIVES || reset.Info.Frame != 0 {
+		t.Errorf("Expected a fresh episode, got %+v", reset.Info)
+	}
+
+	var step envStepResponse
+	body := `{"session": "` + reset.Session + `", "action": 2}`
+	postJSON(t, srv.URL+"/env/step", body, http.StatusOK, &step)
+	if step.Info.Frame != 1 || step.Truncated {
+		t.Errorf("Expected frame 1 without truncation, got %+v", step.Info)
+	}
+	postJSON(t, srv.URL+"/env/step", body, http.StatusOK, &step)
+	if !step.Truncated {
+		t.Error("Expected truncation at max_steps")
+	}
+
+	// resetting the session starts its episode over
+	postJSON(t, srv.URL+"/env/reset", `{"session": "`+reset.Session+`"}`, http.StatusOK, &reset)
+	if reset.Info.Frame != 0 {
+		t.Errorf("Expected frame 0 after reset, got %d", reset.Info.Frame)
+	}
+}
+
+func TestEnvObservationModes(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset envResetResponse
+	postJSON(t, srv.URL+"/env/reset", `{"observation": {"mode": "features"}}`, http.StatusOK, &reset)
+	if reset.Space.DType != "float32" || len(reset.Space.Shape) != 1 {
+		t.Errorf("Expected a float32 feature vector space, got %+v", reset.Space)
+	}
+	if len(reset.Observation.Data) != reset.Space.Shape[0] {
+		t.Errorf("Expected %d features, got %d", reset.Space.Shape[0], len(reset.Observation.Data))
+	}
+
+	var vec vecResetResponse
+	postJSON(t, srv.URL+"/vec/reset", `{"num_envs": 2, "observation": {"height": 20, "width": 15}}`, http.StatusOK, &vec)
+	if want := []int{2, 20, 15}; !reflect.DeepEqual(vec.Space.Shape, want) || !reflect.DeepEqual(vec.Observations.Shape, want) {
+		t.Errorf("Expected space and observations of shape %v, got %v and %v", want, vec.Space.Shape, vec.Observations.Shape)
+	}
+
+	postJSON(t, srv.URL+"/env/reset", `{"observation": {"mode": "pixels"}}`, http.StatusBadRequest, nil)
+}
+
+func TestEnvWrappers(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset envResetResponse
+	postJSON(t, srv.URL+"/env/reset", `{"seed": 2, "wrappers": {"frame_skip": 4, "frame_stack": 2}}`, http.StatusOK, &reset)
+	if len(reset.Observation.Shape) != 3 || reset.Observation.Shape[0] != 2 || reset.Space.Shape[0] != 2 {
+		t.Errorf("Expected 2 stacked observations, got %v", reset.Observation.Shape)
+	}
+	var step envStepResponse
+	postJSON(t, srv.URL+"/env/step", `{"session": "`+reset.Session+`", "action": 1}`, http.StatusOK, &step)
+	if step.Info.Frame != 4 {
+		t.Errorf("Expected 4 frames per step, got %d", step.Info.Frame)
+	}
+
+	var vec vecResetResponse
+	postJSON(t, srv.URL+"/vec/reset", `{"num_envs": 2, "wrappers": {"frame_stack": 3, "sticky_actions": 0.25}}`, http.StatusOK, &vec)
+	if want := []int{2, 3}; !reflect.DeepEqual(vec.Observations.Shape[:2], want) {
+		t.Errorf("Expected a batch of 3 stacked observations, got %v", vec.Observations.Shape)
+	}
+
+	postJSON(t, srv.URL+"/env/reset", `{"wrappers": {"sticky_actions": 1.5}}`, http.StatusBadRequest, nil)
+}
+
+func TestEnvRewardPreset(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset envResetResponse
+	postJSON(t, srv.URL+"/env/reset", `{"seed": 1, "reward": "survival"}`, http.StatusOK, &reset)
+	var step envStepResponse
+	for range 2000 {
+		postJSON(t, srv.URL+"/env/step", `{"session": "`+reset.Session+`", "action": 0}`, http.StatusOK, &step)
+		if step.Reward != 0 {
+			break
+		}
+	}
+	if step.Reward != -1 {
+		t.Errorf("Expected -1 for the first lost life, got %v", step.Reward)
+	}
+	postJSON(t, srv.URL+"/env/reset", `{"reward": "bonus"}`, http.StatusBadRequest, nil)
+}
+
+func TestEnvErrors(t *testing.T) {
+	srv := newEnvServer(t)
+	postJSON(t, srv.URL+"/env/step", `{"session": "unknown", "action": 0}`, http.StatusNotFound, nil)
+	postJSON(t, srv.URL+"/env/reset", `{"session": "unknown"}`, http.StatusNotFound, nil)
+
+	var reset envResetResponse
+	postJSON(t, srv.URL+"/env/reset", "", http.StatusOK, &reset)
+	postJSON(t, srv.URL+"/env/step", `{"session": "`+reset.Session+`", "action": 9}`, http.StatusBadRequest, nil)
+}
+
+func TestVecResetAndStep(t *testing.T) {
+	srv := newEnvServer(t)
+
+	var reset vecResetResponse
+	postJSON(t, srv.URL+"/vec/reset", `{"num_envs": 3, "seed": 2, "max_steps": 1}`, http.StatusOK, &reset)
+	if reset.Session == "" || len(reset.Observations.Shape) != 3 || reset.Observations.Shape[0] != 3 {
+		t.Fatalf("Expected a session with 3 stacked observations, got %v", reset.Observations.Shape)
+	}
+
+	var step vecStepResponse
+	postJSON(t, srv.URL+"/vec/step", `{"session": "`+reset.Session+`", "actions": [0, 1, 2]}`, http.StatusOK, &step)
+	if len(step.Rewards) != 3 || len(step.Infos) != 3 || step.Observations.Shape[0] != 3 {
+		t.Fatalf("Expected 3 results, got %d rewards and %d infos", len(step.Rewards), len(step.Infos))
+	}
+	for i, truncated := range step.Truncated {
+		if !truncated {
+			t.Errorf("Expected environment %d to be truncated after max_steps", i)
+		}
+	}
+
+	postJSON(t, srv.URL+"/vec/step", `{"session": "`+reset.Session+`", "actions": [0]}`, http.StatusBadRequest, nil)
+	postJSON(t, srv.URL+"/vec/reset", `{"num_envs": 0}`, http.StatusBadRequest, nil)
+	postJSON(t, srv.URL+"/vec/step", `{"session": "unknown", "actions": [0]}`, http.StatusNotFound, nil)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"sync"
+	"time"
+)
+
+// gameLoop owns the game of the server and serializes every access to it.
+//
+// In real-time mode Run advances the game on its own goroutine at a fixed
+// tick rate: clients only set the input, which is applied on every tick, and
+// read the state, so the game speed no longer depends on how often clients
+// send requests. In lock-step mode Run is not started and the game advances
+// one frame on every Step call.
+//
+// Every game started by the loop is recorded. When it ends, or is reset
+// after its first frame, its replay is handed to onReplay.
+//
+// With a policy the bot plays instead of the input, and a new game is
+// started GAME_OVER_FRAMES frames after a game over, as an attract mode.
+type gameLoop struct {
+	mu       sync.Mutex
+	game     *breakout.Breakout
+	newGame  func() *breakout.Breakout
+	left     bool             // left key held
+	right    bool             // right key held
+	fire     bool             // release the held balls on the next frame
+	frame    int              // frames played since the last reset
+	games    int              // games started or replaced, identifies the current game
+	tick     chan struct{}    // closed and replaced after every frame
+	recorder *replay.Recorder // records the game, nil if it is not recorded
+	onReplay func(*replay.Replay)
+	policy   bot.Policy // plays the game instead of the input, nil for the input
+	overFor  int        // frames since the game is over
+}
+
+// GAME_OVER_FRAMES is the number of frames the policy waits after a game
+// over before a new game is started
+const GAME_OVER_FRAMES = 180
+
+// newGameLoop creates a game loop playing games created by newGame
+func newGameLoop(newGame func() *breakout.Breakout) *gameLoop {
+	game := newGame()
+	return &gameLoop{
+		game:     game,
+		newGame:  newGame,
+		tick:     make(chan struct{}),
+		recorder: replay.NewRecorder(game.GetConfig(), game.Seed()),
+	}
+}
+
+// OnReplay sets the function that gets the replay of every recorded game,
+// it is called without the loop locked
+func (l *gameLoop) OnReplay(f func(*replay.Replay)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onReplay = f
+}
+
+// SetPolicy lets the policy play the game instead of the input, nil to play
+// with the input again
+func (l *gameLoop) SetPolicy(p bot.Policy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.policy = p
+}
+
+// SetInput sets the keys that are held from the next frame on. Fire is kept
+// until the next frame, so a short key press is not lost between two ticks.
+func (l *gameLoop) SetInput(left, right, fire bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.left, l.right = left, right
+	l.fire = l.fire || fire
+}
+
+// Step advances the game by one frame with the current input, or the input
+// of the policy
+func (l *gameLoop) Step() {
+	l.mu.Lock()
+	in := replay.NewInput(l.left, l.right, l.fire)
+	if l.policy != nil {
+		state := l.game.GetState()
+		if state.Done {
+			l.overFor++
+			restart := l.overFor >= GAME_OVER_FRAMES
+			l.mu.Unlock()
+			if restart {
+				l.Reset()
+			}
+			return
+		}
+		in = l.policy.Act(&state)
+	}
+	l.fire = false
+	replay.Play(l.game, in)
+	l.frame++
+	var done *replay.Replay
+	if l.recorder != nil {
+		state := l.game.GetState()
+		l.recorder.Record(in, state.Score)
+		if state.Done {
+			done = l.stopRecording()
+		}
+	}
+	l.notify()
+	l.mu.Unlock()
+	l.save(done)
+}
+
+// Reset starts a new game, which is recorded
+func (l *gameLoop) Reset() {
+	game := l.newGame()
+	done := l.replace(game, replay.NewRecorder(game.GetConfig(), game.Seed()))
+	l.save(done)
+}
+
+// Replace continues with the given game, e.g. restored from a snapshot.
+// It is not recorded, a replay has to start with a new game.
+func (l *gameLoop) Replace(game *breakout.Breakout) {
+	l.save(l.replace(game, nil))
+}
+
+// replace continues with the given game and recorder and returns the
+// replay of the previous game
+func (l *gameLoop) replace(game *breakout.Breakout, recorder *replay.Recorder) *replay.Replay {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	done := l.stopRecording()
+	l.game = game
+	l.left, l.right, l.fire = false, false, false
+	l.frame, l.overFor = 0, 0
+	l.games++
+	l.recorder = recorder
+	l.notify()
+	return done
+}
+
+// stopRecording ends the recording and returns its replay, nil without a
+// recorded frame. l.mu must be held.
+func (l *gameLoop) stopRecording() *replay.Replay {
+	rec := l.recorder
+	l.recorder = nil
+	if rec == nil || rec.Len() == 0 || l.onReplay == nil {
+		return nil
+	}
+	return rec.Replay()
+}
+
+// save hands a replay to onReplay, l.mu must not be held
+func (l *gameLoop) save(rp *replay.Replay) {
+	if rp == nil {
+		return
+	}
+	l.mu.Lock()
+	f := l.onReplay
+	l.mu.Unlock()
+	f(rp)
+}
+
+// State returns the state of the game and the number of the current frame
+func (l *gameLoop) State() (breakout.BreakoutState, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.game.GetState(), l.frame
+}
+
+// Snapshot returns the full state of the game
+func (l *gameLoop) Snapshot() breakout.Snapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.game.Snapshot()
+}
+
+// Observe returns the state of the game, the number of the game, the number
+// of the current frame and a channel that is closed when the next frame is
+// played
+func (l *gameLoop) Observe() (breakout.BreakoutState, int, int, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.game.GetState(), l.games, l.frame, l.tick
+}
+
+// Wait blocks until the next frame is played or the timeout expires
+func (l *gameLoop) Wait(timeout time.Duration) {
+	l.mu.Lock()
+	tick := l.tick
+	l.mu.Unlock()
+	select {
+	case <-tick:
+	case <-time.After(timeout):
+	}
+}
+
+// Run advances the game rate times per second until stop is closed
+func (l *gameLoop) Run(rate int, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.Step()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// notify wakes up everyone waiting for the next frame, l.mu must be held
+func (l *gameLoop) notify() {
+	close(l.tick)
+	l.tick = make(chan struct{})
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"breakout-go/internal/bot"
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestLoop() *gameLoop {
+	return newGameLoop(func() *breakout.Breakout {
+		return breakout.NewBreakout(breakout.DefaultConfig(), 1)
+	})
+}
+
+func TestGameLoopStepAppliesInput(t *testing.T) {
+	loop := newTestLoop()
+	before, _ := loop.State()
+
+	loop.SetInput(true, false, false)
+	loop.Step()
+	loop.Step()
+
+	state, frame := loop.State()
+	if frame != 2 {
+		t.Errorf("Expected frame 2, got %d", frame)
+	}
+	if want := before.PaddleX - 2*breakout.PADDLE_STEP; state.PaddleX != want {
+		t.Errorf("Expected paddle at %d, got %d", want, state.PaddleX)
+	}
+}
+
+func TestGameLoopRunsAtTickRate(t *testing.T) {
+	loop := newTestLoop()
+	stop := make(chan struct{})
+	go loop.Run(100, stop)
+	defer close(stop)
+
+	time.Sleep(250 * time.Millisecond)
+	_, frame := loop.State()
+	if frame < 10 || frame > 40 {
+		t.Errorf("Expected about 25 frames at 100 ticks per second, got %d", frame)
+	}
+}
+
+func TestGameLoopWaitReturnsAfterNextFrame(t *testing.T) {
+	loop := newTestLoop()
+	done := make(chan struct{})
+	go func() {
+		loop.Wait(time.Minute)
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	loop.Step()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expected Wait to return after the next frame")
+	}
+}
+
+func TestGameLoopReset(t *testing.T) {
+	loop := newTestLoop()
+	loop.SetInput(false, true, false)
+	loop.Step()
+
+	loop.Reset()
+	loop.Step()
+
+	state, frame := loop.State()
+	if frame != 1 {
+		t.Errorf("Expected frame 1 after reset, got %d", frame)
+	}
+	if want := (breakout.AREA_WIDTH - breakout.PADDLE_WIDTH) / 2; state.PaddleX != want {
+		t.Errorf("Expected input to be cleared on reset, paddle at %d, got %d", want, state.PaddleX)
+	}
+}
+
+// recordLoop plays n frames on a game loop with seed 1 and returns the
+// replay it hands over on reset
+func recordLoop(n int) (*replay.Replay, *breakout.Breakout) {
+	loop := newTestLoop()
+	var rp *replay.Replay
+	loop.OnReplay(func(r *replay.Replay) { rp = r })
+	for i := range n {
+		loop.SetInput(i%80 < 40, i%80 >= 40, i == 0)
+		loop.Step()
+	}
+	played := loop.game.Clone()
+	loop.Reset()
+	return rp, played
+}
+
+func TestGameLoopRecordsReplay(t *testing.T) {
+	rp, played := recordLoop(500)
+	if rp == nil || len(rp.Inputs) != 500 || rp.Seed != 1 {
+		t.Fatalf("Expected a replay of 500 frames with seed 1, got %+v", rp)
+	}
+	p, err := replay.NewPlayer(rp)
+	if err != nil {
+		t.Fatalf("Expected a player, got %v", err)
+	}
+	p.Seek(p.Len())
+	if !reflect.DeepEqual(p.Game().Snapshot(), played.Snapshot()) {
+		t.Error("Expected the replay to reproduce the played game")
+	}
+
+	// a restored game is not recorded
+	loop := newTestLoop()
+	saved := 0
+	loop.OnReplay(func(*replay.Replay) { saved++ })
+	loop.Replace(breakout.NewBreakout(breakout.DefaultConfig(), 2))
+	loop.Step()
+	loop.Reset()
+	if saved != 0 {
+		t.Errorf("Expected no replay of a restored game, got %d", saved)
+	}
+}
+
+func TestGameLoopPolicyPlaysAndRestarts(t *testing.T) {
+	loop := newTestLoop()
+	var rp *replay.Replay
+	loop.OnReplay(func(r *replay.Replay) { rp = r })
+	loop.SetPolicy(bot.NewRandom(1))
+	loop.SetInput(true, false, false) // ignored while the policy plays
+
+	for range 100_000 {
+		if state, _ := loop.State(); state.Done {
+			break
+		}
+		loop.Step()
+	}
+	state, frames := loop.State()
+	if !state.Done {
+		t.Fatal("Expected the random bot to lose the game")
+	}
+	if rp == nil || len(rp.Inputs) != frames {
+		t.Fatalf("Expected the replay of the %d frames of the bot", frames)
+	}
+	if slices.ContainsFunc(rp.Inputs, func(in replay.Input) bool { return in&replay.InputFire == 0 }) {
+		t.Error("Expected the inputs of the bot, which always fires")
+	}
+
+	for range GAME_OVER_FRAMES - 1 {
+		loop.Step()
+	}
+	if state, frame := loop.State(); !state.Done || frame != frames {
+		t.Errorf("Expected the game over to be shown for %d frames", GAME_OVER_FRAMES)
+	}
+	loop.Step()
+	if state, frame := loop.State(); state.Done || frame != 0 {
+		t.Errorf("Expected a new game after %d frames, got frame %d", GAME_OVER_FRAMES, frame)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// MAX_REPLAY_BYTES limits the size of an uploaded replay file
+const MAX_REPLAY_BYTES = 16 << 20
+
+// MAX_REPLAY_BATCH limits the frames of one "/replays/{id}/frames" request
+const MAX_REPLAY_BATCH = 600
+
+// MAX_CACHED_PLAYERS limits the replay players kept between
+// "/replays/{id}/frames" requests
+const MAX_CACHED_PLAYERS = 8
+
+// replayFramesResponse is the answer of "/replays/{id}/frames"
+type replayFramesResponse struct {
+	ID     string                   `json:"id"`
+	Frames int                      `json:"frames"` // frames of the replay
+	From   int                      `json:"from"`   // frame of the first state
+	States []breakout.BreakoutState `json:"states"`
+}
+
+// registerReplays adds the replay endpoints to mux:
+//   - "GET /replays": Lists the stored replays (replay.Info), newest first.
+//   - "POST /replays": Stores the replay file in the body and returns its
+//     replay.Info with 201. Replays of another engine version are refused.
+//     The replay is played once to build the keyframes of its player.
+//   - "GET /replays/{id}": Downloads the replay file.
+//   - "GET /replays/{id}/frames?from=0&count=300": Re-simulates the replay
+//     and returns the game states of frames from to from+count-1, frame 0
+//     being the state before the first input. The players of the recently
+//     watched replays are kept with their keyframes, so a replay fetched
+//     batch by batch is simulated once.
+func registerReplays(mux *http.ServeMux, store *replay.Store) {
+	players := &playerCache{store: store, players: map[string]*cachedPlayer{}}
+
+	mux.HandleFunc("GET /replays", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(store.List())
+	})
+
+	mux.HandleFunc("POST /replays", func(w http.ResponseWriter, r *http.Request) {
+		rp, err := replay.Read(http.MaxBytesReader(w, r.Body, MAX_REPLAY_BYTES))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p, err := replay.NewPlayer(rp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		info, err := store.Save(rp)
+		if err != nil {
+			http.Error(w, "Failed to store the replay", http.StatusInternalServerError)
+			return
+		}
+		// replay.MAX_FRAMES bounds this, frame requests then seek from the
+		// keyframes
+		p.Seek(p.Len())
+		players.put(info.ID, p)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(info)
+	})
+
+	mux.HandleFunc("GET /replays/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		data, err := store.Get(id)
+		if err != nil {
+			replayError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", CONTENT_TYPE_RAW)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+id+replay.FILE_EXT+`"`)
+		w.Write(data)
+	})
+
+	mux.HandleFunc("GET /replays/{id}/frames", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		from, err := queryInt(r, "from", 0)
+		if err != nil || from < 0 {
+			http.Error(w, "Invalid from", http.StatusBadRequest)
+			return
+		}
+		count, err := queryInt(r, "count", 300)
+		if err != nil || count < 1 || count > MAX_REPLAY_BATCH {
+			http.Error(w, "Invalid count", http.StatusBadRequest)
+			return
+		}
+		cp, ok := players.get(id)
+		if !ok {
+			rp, err := store.Load(id)
+			if err != nil {
+				replayError(w, err)
+				return
+			}
+			p, err := replay.NewPlayer(rp)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			cp = players.put(id, p)
+		}
+		cp.mu.Lock()
+		defer cp.mu.Unlock()
+		p := cp.player
+		if err := p.Seek(from); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res := replayFramesResponse{ID: id, Frames: p.Len(), From: from}
+		for {
+			res.States = append(res.States, p.Game().GetState())
+			if len(res.States) == count {
+				break
+			}
+			if _, ok := p.Step(); !ok {
+				break
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	})
+}
+
+// playerCache keeps the players of the replays watched last
+type playerCache struct {
+	mu      sync.Mutex
+	store   *replay.Store
+	players map[string]*cachedPlayer
+	order   []string // ids of the players, least recently used first
+}
+
+type cachedPlayer struct {
+	mu     sync.Mutex // held while the player seeks and steps
+	player *replay.Player
+}
+
+// get returns the player of the replay and marks it as used, false if it
+// is not cached or the replay is no longer stored
+func (c *playerCache) get(id string) (*cachedPlayer, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp, ok := c.players[id]
+	if !ok {
+		return nil, false
+	}
+	c.order = slices.DeleteFunc(c.order, func(other string) bool { return other == id })
+	if !c.store.Has(id) {
+		delete(c.players, id)
+		return nil, false
+	}
+	c.order = append(c.order, id)
+	return cp, true
+}
+
+// put caches the player of the replay, dropping the least recently used
+// players beyond MAX_CACHED_PLAYERS, and returns the cached player
+func (c *playerCache) put(id string, p *replay.Player) *cachedPlayer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cp, ok := c.players[id]; ok {
+		// another request loaded it first
+		return cp
+	}
+	cp := &cachedPlayer{player: p}
+	c.players[id] = cp
+	c.order = append(c.order, id)
+	for len(c.order) > MAX_CACHED_PLAYERS {
+		delete(c.players, c.order[0])
+		c.order = c.order[1:]
+	}
+	return cp
+}
+
+// replayError answers a failed store access, 404 for unknown replays
+func replayError(w http.ResponseWriter, err error) {
+	if errors.Is(err, replay.ErrNotFound) {
+		http.Error(w, "Unknown replay", http.StatusNotFound)
+		return
+	}
+	http.Error(w, "Failed to read the replay", http.StatusInternalServerError)
+}
+
+// queryInt returns the integer query parameter, def if it is missing
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
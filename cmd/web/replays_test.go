@@ -0,0 +1,132 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newReplayServer(t *testing.T) (*httptest.Server, *replay.Store) {
+	store, _ := replay.NewStore("", 10)
+	mux := http.NewServeMux()
+	registerReplays(mux, store)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, store
+}
+
+func TestReplayEndpoints(t *testing.T) {
+	srv, _ := newReplayServer(t)
+	rp, _ := recordLoop(400)
+	var buf bytes.Buffer
+	rp.WriteTo(&buf)
+	file := buf.Bytes()
+
+	resp, err := http.Post(srv.URL+"/replays", CONTENT_TYPE_RAW, bytes.NewReader(file))
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the upload to be created, got %v %v", resp, err)
+	}
+	var info replay.Info
+	json.NewDecoder(resp.Body).Decode(&info)
+	resp.Body.Close()
+	if info.ID == "" || info.Frames != 400 {
+		t.Fatalf("Expected the info of a replay of 400 frames, got %+v", info)
+	}
+
+	resp, _ = http.Get(srv.URL + "/replays")
+	var list []replay.Info
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list) != 1 || list[0].ID != info.ID {
+		t.Errorf("Expected the uploaded replay in the list, got %+v", list)
+	}
+
+	resp, _ = http.Get(srv.URL + "/replays/" + info.ID)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(data, file) {
+		t.Error("Expected to download the uploaded file")
+	}
+
+	resp, _ = http.Get(srv.URL + "/replays/" + info.ID + "/frames?from=350&count=100")
+	var frames replayFramesResponse
+	json.NewDecoder(resp.Body).Decode(&frames)
+	resp.Body.Close()
+	if frames.Frames != 400 || frames.From != 350 || len(frames.States) != 51 {
+		t.Errorf("Expected frames 350 to 400 of 400, got %d states from %d of %d", len(frames.States), frames.From, frames.Frames)
+	}
+	p, _ := replay.NewPlayer(rp)
+	p.Seek(360)
+	if !reflect.DeepEqual(frames.States[10], p.Game().GetState()) {
+		t.Error("Expected the state of frame 360")
+	}
+}
+
+func TestReplayEndpointErrors(t *testing.T) {
+	srv, _ := newReplayServer(t)
+	rp, _ := recordLoop(10)
+	rp.EngineVersion = breakout.ENGINE_VERSION + 1
+	var buf bytes.Buffer
+	rp.WriteTo(&buf)
+
+	tests := []struct {
+		method, path string
+		body         []byte
+		want         int
+	}{
+		{"POST", "/replays", []byte("not a replay"), http.StatusBadRequest},
+		{"POST", "/replays", buf.Bytes(), http.StatusUnprocessableEntity},
+		{"GET", "/replays/0123456789abcdef", nil, http.StatusNotFound},
+		{"GET", "/replays/0123456789abcdef/frames", nil, http.StatusNotFound},
+		{"GET", "/replays/x/frames?count=0", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, srv.URL+tt.path, bytes.NewReader(tt.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Expected %s %s to succeed, got %v", tt.method, tt.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("Expected status %d for %s %s, got %d", tt.want, tt.method, tt.path, resp.StatusCode)
+		}
+	}
+}
+
+func TestPlayerCache(t *testing.T) {
+	store, _ := replay.NewStore("", MAX_CACHED_PLAYERS+2)
+	c := &playerCache{store: store, players: map[string]*cachedPlayer{}}
+	rp, _ := recordLoop(10)
+	var ids []string
+	for range MAX_CACHED_PLAYERS + 1 {
+		info, _ := store.Save(rp)
+		p, _ := replay.NewPlayer(rp)
+		c.put(info.ID, p)
+		ids = append(ids, info.ID)
+	}
+	if _, ok := c.get(ids[0]); ok {
+		t.Error("Expected the least recently used player to be dropped")
+	}
+	cp, ok := c.get(ids[1])
+	if !ok {
+		t.Fatal("Expected the player to be cached")
+	}
+	p, _ := replay.NewPlayer(rp)
+	if c.put(ids[1], p) != cp {
+		t.Error("Expected put to keep the cached player")
+	}
+
+	// saving more replays prunes the first ones from the store
+	for range 3 {
+		store.Save(rp)
+	}
+	if _, ok := c.get(ids[1]); ok {
+		t.Error("Expected no player of a replay the store dropped")
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"breakout-go/internal/session"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// sessionResponse is the answer of the session endpoints
+type sessionResponse struct {
+	ID        string                 `json:"id"`
+	Seed      int64                  `json:"seed"`
+	Frame     int                    `json:"frame"`
+	State     breakout.BreakoutState `json:"state"`
+	Reward    float64                `json:"reward"`    // reward of the step
+	Done      bool                   `json:"done"`      // the game is over
+	Truncated bool                   `json:"truncated"` // the episode reached the step limit
+}
+
+// registerSessions adds the session endpoints to mux:
+//   - "POST /sessions": Creates a session, the optional body
+//     {"seed": 5, "config": {...}, "max_steps": 1000, "observation": {...},
+//     "wrappers": {...}, "reward": "shaped"} sets its seed, game
+//     configuration, step limit, the observation mode of /env/step
+//     (env.ObsOptions), the frame skip, frame stack and sticky actions
+//     (env.Wrappers) and the reward preset (env.RewardPreset).
+//   - "GET /sessions/{id}/state": Returns the game state of the session.
+//   - "POST /sessions/{id}/step": Applies {"action": 0|1|2} (none, left,
+//     right) and advances the game of the session by one step, one frame
+//     unless the wrappers skip frames.
+//   - "POST /sessions/{id}/reset": Starts a new game in the session.
+//   - "DELETE /sessions/{id}": Ends the session.
+func registerSessions(mux *http.ServeMux, sessions *session.Manager) {
+	mux.HandleFunc("POST /sessions", func(w http.ResponseWriter, r *http.Request) {
+		var input sessionOptions
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil && err != io.EOF {
+			http.Error(w, "Failed to parse JSON", http.StatusBadRequest)
+			return
+		}
+		s, ok := createSession(w, sessions, input)
+		if !ok {
+			return
+		}
+		state, frame := s.State()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(sessionResponse{ID: s.ID(), Seed: s.Seed(), Frame: frame, State: state, Done: state.Done})
+	})
+
+	mux.HandleFunc("GET /sessions/{id}/state", withSession(sessions, func(w http.ResponseWriter, r *http.Request, s *session.Session) {
+		state, frame := s.State()
+		writeSession(w, sessionResponse{ID: s.ID(), Seed: s.Seed(), Frame: frame, State: state, Done: state.Done})
+	}))
+
+	mux.HandleFunc("POST /sessions/{id}/step", withSession(sessions, func(w http.ResponseWriter, r *http.Request, s *session.Session) {
+		var input struct {
+			Action int `json:"action"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Failed to parse JSON", http.StatusBadRequest)
+			return
+		}
+		res, err := s.Step(env.Action(input.Action))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeSession(w, sessionResponse{
+			ID:        s.ID(),
+			Seed:      s.Seed(),
+			Frame:     res.Info.Frame,
+			State:     res.State,
+			Reward:    res.Reward,
+			Done:      res.Terminated,
+			Truncated: res.Truncated,
+		})
+	}))
+
+	mux.HandleFunc("POST /sessions/{id}/reset", withSession(sessions, func(w http.ResponseWriter, r *http.Request, s *session.Session) {
+		s.Reset(0)
+		state, frame := s.State()
+		writeSession(w, sessionResponse{ID: s.ID(), Seed: s.Seed(), Frame: frame, State: state, Done: state.Done})
+	}))
+
+	mux.HandleFunc("DELETE /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !sessions.Delete(r.PathValue("id")) {
+			http.Error(w, "Unknown session", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// sessionOptions are the options of a new session in a request body
+type sessionOptions struct {
+	Seed        int64            `json:"seed"`
+	Config      *breakout.Config `json:"config"`
+	MaxSteps    int              `json:"max_steps"`
+	Observation env.ObsOptions   `json:"observation"`
+	Wrappers    env.Wrappers     `json:"wrappers"`
+	Reward      string           `json:"reward"`
+}
+
+// createSession creates a session with the options, errors are answered
+// with 503 for too many sessions and 400 otherwise
+func createSession(w http.ResponseWriter, sessions *session.Manager, opts sessionOptions) (*session.Session, bool) {
+	if opts.MaxSteps < 0 {
+		http.Error(w, "Invalid max_steps", http.StatusBadRequest)
+		return nil, false
+	}
+	s, err := sessions.Create(opts.options())
+	if err != nil {
+		createError(w, err)
+		return nil, false
+	}
+	return s, true
+}
+
+// options returns the session options
+func (o sessionOptions) options() session.Options {
+	return session.Options{Seed: o.Seed, Config: o.Config, MaxSteps: o.MaxSteps, Observation: o.Observation, Wrappers: o.Wrappers, Reward: o.Reward}
+}
+
+// createError answers a failed session creation, with 503 for too many
+// sessions and 400 otherwise
+func createError(w http.ResponseWriter, err error) {
+	if errors.Is(err, session.ErrTooManySessions) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// withSession looks up the session of the {id} path value, unknown sessions
+// are answered with 404
+func withSession(sessions *session.Manager, h func(http.ResponseWriter, *http.Request, *session.Session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, ok := sessions.Get(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "Unknown session", http.StatusNotFound)
+			return
+		}
+		h(w, r, s)
+	}
+}
+
+// writeSession writes a session response as JSON
+func writeSession(w http.ResponseWriter, res sessionResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
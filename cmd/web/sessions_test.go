@@ -0,0 +1,79 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/session"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSessionServer(t *testing.T, maxSessions int) *httptest.Server {
+	mux := http.NewServeMux()
+	registerSessions(mux, session.NewManager(breakout.DefaultConfig(), maxSessions, time.Minute))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// doSession sends a request and decodes the session response
+func doSession(t *testing.T, method, url, body string, wantStatus int) sessionResponse {
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected %s %s to succeed, got %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("Expected status %d for %s %s, got %d", wantStatus, method, url, resp.StatusCode)
+	}
+	var res sessionResponse
+	if wantStatus < 300 && wantStatus != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatalf("Expected JSON response, got %v", err)
+		}
+	}
+	return res
+}
+
+func TestSessionEndpoints(t *testing.T) {
+	srv := newSessionServer(t, 4)
+
+	created := doSession(t, "POST", srv.URL+"/sessions", `{"seed": 7}`, http.StatusCreated)
+	if created.ID == "" || created.Seed != 7 {
+		t.Fatalf("Expected session with seed 7, got %+v", created)
+	}
+	base := srv.URL + "/sessions/" + created.ID
+
+	step := doSession(t, "POST", base+"/step", `{"action": 1}`, http.StatusOK)
+	if step.Frame != 1 || step.State.PaddleX != created.State.PaddleX-breakout.PADDLE_STEP {
+		t.Errorf("Expected frame 1 with the paddle moved left, got frame %d paddle %d", step.Frame, step.State.PaddleX)
+	}
+	state := doSession(t, "GET", base+"/state", "", http.StatusOK)
+	if state.Frame != 1 {
+		t.Errorf("Expected frame 1, got %d", state.Frame)
+	}
+	reset := doSession(t, "POST", base+"/reset", "", http.StatusOK)
+	if reset.Frame != 0 || reset.State.PaddleX != created.State.PaddleX {
+		t.Errorf("Expected a new game after reset, got frame %d", reset.Frame)
+	}
+
+	doSession(t, "POST", base+"/step", `{"action": 7}`, http.StatusBadRequest)
+	doSession(t, "DELETE", base, "", http.StatusNoContent)
+	doSession(t, "GET", base+"/state", "", http.StatusNotFound)
+}
+
+func TestSessionEndpointsConfigAndCap(t *testing.T) {
+	srv := newSessionServer(t, 1)
+
+	created := doSession(t, "POST", srv.URL+"/sessions", `{"config": {"AreaWidth": 120, "BricksPerRow": 12}}`, http.StatusCreated)
+	if created.State.Width != 120 {
+		t.Errorf("Expected width 120, got %d", created.State.Width)
+	}
+	doSession(t, "POST", srv.URL+"/sessions", "", http.StatusServiceUnavailable)
+	doSession(t, "DELETE", srv.URL+"/sessions/"+created.ID, "", http.StatusNoContent)
+	doSession(t, "POST", srv.URL+"/sessions", `{"config": {"PaddleWidth": -1}}`, http.StatusBadRequest)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/websocket"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// wsMessage is a message of the /ws stream. The first message, and every
+// message after a new or restored game, a level change or a frame counter
+// going back, carries the full state. The other messages only carry the
+// changes since the previous message.
+type wsMessage struct {
+	Type  string                  // "state" for a full state, "delta" for changes
+	Frame int                     // frame of the server the state belongs to
+	State *breakout.BreakoutState `json:",omitempty"`
+	Delta *stateDelta             `json:",omitempty"`
+}
+
+// stateDelta holds the changes of the game state between two messages.
+// Bricks are identified by their position.
+type stateDelta struct {
+	Balls    []breakout.BallState  // balls in play
+	Paddle   *paddleDelta          `json:",omitempty"` // paddle, if it moved or changed its width
+	Bricks   []breakout.BrickState `json:",omitempty"` // bricks that appeared or changed
+	Cleared  []brickPos            `json:",omitempty"` // bricks that were cleared
+	Score    int
+	Level    int
+	Live     int
+	Done     bool
+	Won      bool
+	Capsules []breakout.CapsuleState
+	Effects  []breakout.EffectState
+	Lasers   []breakout.LaserState
+}
+
+// wsView is the state of a frame of a game sent to a client
+type wsView struct {
+	state breakout.BreakoutState
+	game  int // number of the game of the loop, see gameLoop.Observe
+	frame int
+}
+
+type paddleDelta struct {
+	X, Width int
+}
+
+type brickPos struct {
+	X, Y int
+}
+
+// wsInput is a key event sent by the client
+type wsInput struct {
+	Key  string // "left", "right" or "fire"
+	Down bool   // true when the key is pressed, false when it is released
+}
+
+// serveWS streams the game to a WebSocket client, one message for every
+// frame the client can keep up with. If control is set, the key events of
+// the client are the player input, and the keys are released when the
+// connection closes so the paddle does not keep moving.
+func serveWS(loop *gameLoop, control bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			log.Printf("WebSocket upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if control {
+				defer loop.SetInput(false, false, false)
+			}
+			var left, right bool
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				var input wsInput
+				if err := json.Unmarshal(data, &input); err != nil || !control {
+					continue
+				}
+				switch input.Key {
+				case "left":
+					left = input.Down
+				case "right":
+					right = input.Down
+				}
+				loop.SetInput(left, right, input.Key == "fire" && input.Down)
+			}
+		}()
+
+		var prev *wsView
+		for {
+			state, game, frame, tick := loop.Observe()
+			cur := wsView{state: state, game: game, frame: frame}
+			if err := conn.WriteJSON(newWSMessage(prev, cur)); err != nil {
+				return
+			}
+			prev = &cur
+			select {
+			case <-tick:
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+// newWSMessage returns the message that brings a client from the view prev
+// (nil for a new client) to the view cur. Deltas are only sent between the
+// frames of one game and level, everything else gets the full state.
+func newWSMessage(prevView *wsView, curView wsView) wsMessage {
+	cur, frame := curView.state, curView.frame
+	if prevView == nil || prevView.game != curView.game || curView.frame < prevView.frame ||
+		prevView.state.Level != cur.Level {
+		return wsMessage{Type: "state", Frame: frame, State: &cur}
+	}
+	prev := &prevView.state
+	d := &stateDelta{
+		Balls:    cur.Balls,
+		Score:    cur.Score,
+		Level:    cur.Level,
+		Live:     cur.Live,
+		Done:     cur.Done,
+		Won:      cur.Won,
+		Capsules: cur.Capsules,
+		Effects:  cur.Effects,
+		Lasers:   cur.Lasers,
+	}
+	if prev.PaddleX != cur.PaddleX || prev.PaddleWidth != cur.PaddleWidth {
+		d.Paddle = &paddleDelta{X: cur.PaddleX, Width: cur.PaddleWidth}
+	}
+	bricks := make(map[brickPos]breakout.BrickState, len(prev.Bricks))
+	for _, br := range prev.Bricks {
+		bricks[brickPos{br.X, br.Y}] = br
+	}
+	for _, br := range cur.Bricks {
+		pos := brickPos{br.X, br.Y}
+		if old, ok := bricks[pos]; !ok || old != br {
+			d.Bricks = append(d.Bricks, br)
+		}
+		delete(bricks, pos)
+	}
+	for _, br := range prev.Bricks {
+		if _, ok := bricks[brickPos{br.X, br.Y}]; ok {
+			d.Cleared = append(d.Cleared, brickPos{br.X, br.Y})
+		}
+	}
+	return wsMessage{Type: "delta", Frame: frame, Delta: d}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/websocket"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dialTestWS(t *testing.T, loop *gameLoop, control bool) *websocket.Conn {
+	srv := httptest.NewServer(serveWS(loop, control))
+	t.Cleanup(srv.Close)
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(srv.URL, "http"))
+	if err != nil {
+		t.Fatalf("Expected dial to succeed, got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestWSStreamsStateAndDeltas(t *testing.T) {
+	loop := newTestLoop()
+	conn := dialTestWS(t, loop, true)
+
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Expected first message, got %v", err)
+	}
+	if msg.Type != "state" || msg.State == nil {
+		t.Fatalf("Expected full state first, got %q", msg.Type)
+	}
+	paddleX := msg.State.PaddleX
+
+	if err := conn.WriteJSON(wsInput{Key: "left", Down: true}); err != nil {
+		t.Fatalf("Expected key event to be sent, got %v", err)
+	}
+	// the key event is applied asynchronously, step until the paddle moves
+	for range 100 {
+		loop.Step()
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Expected delta message, got %v", err)
+		}
+		if msg.Type != "delta" || msg.Delta == nil {
+			t.Fatalf("Expected delta, got %q", msg.Type)
+		}
+		if msg.Delta.Paddle != nil {
+			break
+		}
+	}
+	if msg.Delta.Paddle == nil || msg.Delta.Paddle.X >= paddleX {
+		t.Errorf("Expected paddle to move left from %d", paddleX)
+	}
+}
+
+func TestWSReleasesKeysOnClose(t *testing.T) {
+	loop := newTestLoop()
+	conn := dialTestWS(t, loop, true)
+	held := func() bool {
+		loop.mu.Lock()
+		defer loop.mu.Unlock()
+		return loop.left
+	}
+
+	conn.WriteJSON(wsInput{Key: "left", Down: true})
+	for i := 0; !held(); i++ {
+		if i == 100 {
+			t.Fatal("Expected the left key to be held")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	conn.Close()
+	for i := 0; held(); i++ {
+		if i == 100 {
+			t.Fatal("Expected the left key to be released when the connection closes")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWSIgnoresInputWithoutControl(t *testing.T) {
+	loop := newTestLoop()
+	conn := dialTestWS(t, loop, false)
+
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Expected first message, got %v", err)
+	}
+	conn.WriteJSON(wsInput{Key: "left", Down: true})
+	for range 10 {
+		loop.Step()
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Expected delta message, got %v", err)
+		}
+		if msg.Delta != nil && msg.Delta.Paddle != nil {
+			t.Fatal("Expected paddle not to move without control")
+		}
+	}
+}
+
+func TestWSSendsStateOnReset(t *testing.T) {
+	// a sparse level, a reset brings back less than half of the bricks
+	levels, err := breakout.LoadLevels(strings.NewReader(`{"levels": [{"rows": ["r............r"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := breakout.DefaultConfig()
+	cfg.Levels = levels
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	loop := newGameLoop(func() *breakout.Breakout { return breakout.NewBreakout(cfg, 1) })
+	conn := dialTestWS(t, loop, false)
+
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "state" {
+		t.Fatalf("Expected full state first, got %q, %v", msg.Type, err)
+	}
+	for range 3 {
+		loop.Step()
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != "delta" {
+			t.Fatalf("Expected delta, got %q, %v", msg.Type, err)
+		}
+	}
+	loop.Reset()
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Expected message after the reset, got %v", err)
+	}
+	if msg.Type != "state" || msg.Frame != 0 {
+		t.Errorf("Expected full state of frame 0 after the reset, got %q of frame %d", msg.Type, msg.Frame)
+	}
+}
+
+func TestNewWSMessage(t *testing.T) {
+	game := breakout.NewBreakout(breakout.DefaultConfig(), 1)
+	prev := wsView{state: game.GetState(), game: 1, frame: 1}
+
+	cur := prev
+	cur.state.Bricks = cur.state.Bricks[1:]
+	cur.frame = 2
+	msg := newWSMessage(&prev, cur)
+	if msg.Type != "delta" {
+		t.Fatalf("Expected delta, got %q", msg.Type)
+	}
+	first := prev.state.Bricks[0]
+	if len(msg.Delta.Cleared) != 1 || msg.Delta.Cleared[0] != (brickPos{first.X, first.Y}) {
+		t.Errorf("Expected the first brick to be cleared, got %v", msg.Delta.Cleared)
+	}
+	if len(msg.Delta.Bricks) != 0 || msg.Delta.Paddle != nil {
+		t.Errorf("Expected no changed bricks and no paddle, got %v and %v", msg.Delta.Bricks, msg.Delta.Paddle)
+	}
+
+	if msg := newWSMessage(nil, prev); msg.Type != "state" {
+		t.Errorf("Expected full state for a new client, got %q", msg.Type)
+	}
+	next := cur
+	next.game++
+	if msg := newWSMessage(&cur, next); msg.Type != "state" {
+		t.Errorf("Expected full state for a new game, got %q", msg.Type)
+	}
+	back := cur
+	back.frame = 0
+	if msg := newWSMessage(&cur, back); msg.Type != "state" {
+		t.Errorf("Expected full state for a frame counter going back, got %q", msg.Type)
+	}
+	level := cur
+	level.state.Level++
+	if msg := newWSMessage(&cur, level); msg.Type != "state" {
+		t.Errorf("Expected full state for a new level, got %q", msg.Type)
+	}
+}
@@ -0,0 +1,108 @@
+// Package bot provides scripted players of the game.
+//
+// A Policy looks at the state of a frame and returns the input for the next
+// one, so it can drive a breakout.Breakout directly (see replay.Play) or any
+// client that shows the game state.
+//
+// The built-in policies, by name:
+// - tracker: Keeps the paddle under the ball.
+// - predictive: Moves the paddle to where the ball will cross the paddle line.
+// - random: Presses random keys, the baseline every agent should beat.
+//
+// Types:
+// - Policy: Chooses the input of the next frame.
+// - Tracker: Keeps the paddle under the ball.
+//
+// Functions:
+// - NewTracker: Creates a Tracker.
+// - ByName: Creates a built-in policy by name.
+// - Names: Returns the names of the built-in policies.
+package bot
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"fmt"
+	"slices"
+)
+
+// Policy chooses the input of the next frame from the state of the game
+type Policy interface {
+	Act(state *breakout.BreakoutState) replay.Input
+}
+
+// policies creates the built-in policies by name, seed is used by the
+// policies that draw random numbers
+var policies = map[string]func(seed int64) Policy{
+	"tracker":    func(int64) Policy { return NewTracker() },
+	"predictive": func(int64) Policy { return NewPredictor() },
+	"random":     func(seed int64) Policy { return NewRandom(seed) },
+}
+
+// ByName returns a new built-in policy, seeded with seed if it draws random
+// numbers
+func ByName(name string, seed int64) (Policy, error) {
+	f, ok := policies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot %q, expected one of %v", name, Names())
+	}
+	return f(seed), nil
+}
+
+// Names returns the sorted names of the built-in policies
+func Names() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Tracker moves the paddle towards the ball that is closest to it, among
+// the balls falling down if there are any. It fires every frame, so balls
+// held by the sticky paddle are released at once.
+type Tracker struct{}
+
+// NewTracker returns a ball-tracking policy
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Act returns the input that moves the paddle center towards the ball
+func (t *Tracker) Act(state *breakout.BreakoutState) replay.Input {
+	ball, ok := nearestBall(state)
+	if !ok {
+		return replay.InputFire
+	}
+	return steer(state, float64(ball.X)) | replay.InputFire
+}
+
+// nearestBall returns the ball the paddle has to catch next: the lowest of
+// the falling balls, or the lowest ball if none is falling
+func nearestBall(state *breakout.BreakoutState) (breakout.BallState, bool) {
+	var best breakout.BallState
+	found, falling := false, false
+	for _, bl := range state.Balls {
+		down := bl.VY > 0
+		if !found || down && !falling || down == falling && bl.Y > best.Y {
+			best, found, falling = bl, true, down
+		}
+	}
+	return best, found
+}
+
+// steer returns the input that moves the paddle center towards x, none if
+// x is above the middle third of the paddle
+func steer(state *breakout.BreakoutState, x float64) replay.Input {
+	center := float64(state.PaddleX) + float64(state.PaddleWidth)/2
+	// the dead zone keeps the paddle from jittering around the ball
+	dead := float64(state.PaddleWidth) / 6
+	switch {
+	case x < center-dead:
+		return replay.InputLeft
+	case x > center+dead:
+		return replay.InputRight
+	}
+	return 0
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"reflect"
+	"testing"
+)
+
+func TestTrackerFollowsBall(t *testing.T) {
+	state := &breakout.BreakoutState{PaddleX: 50, PaddleWidth: 24}
+	tests := []struct {
+		balls []breakout.BallState
+		want  replay.Input
+	}{
+		{nil, replay.InputFire},
+		{[]breakout.BallState{{X: 10, Y: 100, VY: 1}}, replay.InputLeft | replay.InputFire},
+		{[]breakout.BallState{{X: 90, Y: 100, VY: 1}}, replay.InputRight | replay.InputFire},
+		{[]breakout.BallState{{X: 63, Y: 100, VY: 1}}, replay.InputFire},
+		// the falling ball wins over the lower rising one
+		{[]breakout.BallState{{X: 90, Y: 200, VY: -1}, {X: 10, Y: 100, VY: 1}}, replay.InputLeft | replay.InputFire},
+		// the lowest of the falling balls
+		{[]breakout.BallState{{X: 10, Y: 100, VY: 1}, {X: 90, Y: 150, VY: 1}}, replay.InputRight | replay.InputFire},
+	}
+	for _, tt := range tests {
+		state.Balls = tt.balls
+		if got := NewTracker().Act(state); got != tt.want {
+			t.Errorf("Expected input %b for balls %+v, got %b", tt.want, tt.balls, got)
+		}
+	}
+}
+
+func TestTrackerPlays(t *testing.T) {
+	game := breakout.NewBreakout(breakout.DefaultConfig(), 1)
+	tracker := NewTracker()
+	for range 3000 {
+		state := game.GetState()
+		if state.Done {
+			break
+		}
+		replay.Play(game, tracker.Act(&state))
+	}
+	if state := game.GetState(); state.Score == 0 {
+		t.Errorf("Expected the tracker to score, got %+v", state.Score)
+	}
+}
+
+func TestByName(t *testing.T) {
+	if got := Names(); !reflect.DeepEqual(got, []string{"predictive", "random", "tracker"}) {
+		t.Errorf("Expected the sorted policy names, got %v", got)
+	}
+	for _, name := range Names() {
+		if p, err := ByName(name, 1); err != nil || p == nil {
+			t.Errorf("Expected policy %s, got %v", name, err)
+		}
+	}
+	if _, err := ByName("unknown", 1); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
+
+// playScore lets the policy play a game of the seed for at most n frames
+// and returns its score
+func playScore(p Policy, seed int64, n int) int {
+	game := breakout.NewBreakout(breakout.DefaultConfig(), seed)
+	for range n {
+		state := game.GetState()
+		if state.Done {
+			break
+		}
+		replay.Play(game, p.Act(&state))
+	}
+	return game.GetState().Score
+}
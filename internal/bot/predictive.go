@@ -0,0 +1,72 @@
+// Package bot provides the trajectory-predicting policy.
+//
+// Types:
+// - Predictor: Moves the paddle to where the ball will cross the paddle line.
+//
+// Functions:
+// - NewPredictor: Creates a Predictor.
+package bot
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"math"
+)
+
+// Predictor projects the flight of the ball the paddle has to catch next,
+// with its bounces off the side walls and the top wall, to the line the
+// paddle hits it on, and moves the paddle there. Bricks are ignored: a
+// rising ball that hits one comes back earlier, and the prediction follows
+// once it falls. It fires every frame like Tracker.
+type Predictor struct{}
+
+// NewPredictor returns a trajectory-predicting policy
+func NewPredictor() *Predictor {
+	return &Predictor{}
+}
+
+// Act returns the input that moves the paddle center towards the predicted
+// landing point of the ball
+func (p *Predictor) Act(state *breakout.BreakoutState) replay.Input {
+	ball, ok := nearestBall(state)
+	if !ok {
+		return replay.InputFire
+	}
+	return steer(state, landing(state, ball)) | replay.InputFire
+}
+
+// landing returns the x coordinate at which the ball reaches the paddle line
+func landing(state *breakout.BreakoutState, ball breakout.BallState) float64 {
+	r := float64(ball.Radius)
+	x, y := float64(ball.X), float64(ball.Y)
+	paddleY := float64(state.Height-state.PaddleHeight) - r
+	if ball.VY == 0 {
+		// no vertical motion, the ball never reaches the line; guards the
+		// division below
+		return x
+	}
+	// distance to fly in y: up to the top wall and back for a rising ball
+	dy := paddleY - y
+	if ball.VY < 0 {
+		dy = (y - r) + (paddleY - r)
+	}
+	dx := dy / math.Abs(ball.VY) * ball.VX
+	return fold(x+dx, r, float64(state.Width)-r)
+}
+
+// fold returns x reflected into [lo, hi], like a ball bouncing between
+// walls at lo and hi
+func fold(x, lo, hi float64) float64 {
+	w := hi - lo
+	if w <= 0 {
+		return lo
+	}
+	m := math.Mod(x-lo, 2*w)
+	if m < 0 {
+		m += 2 * w
+	}
+	if m > w {
+		m = 2*w - m
+	}
+	return lo + m
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"breakout-go/internal/breakout"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct{ x, want float64 }{
+		{5, 5}, {12, 8}, {-3, 3}, {22, 2}, {-18, 2},
+	}
+	for _, tt := range tests {
+		if got := fold(tt.x, 0, 10); got != tt.want {
+			t.Errorf("Expected %g folded into [0, 10] to be %g, got %g", tt.x, tt.want, got)
+		}
+	}
+}
+
+func TestLanding(t *testing.T) {
+	state := &breakout.BreakoutState{Width: 100, Height: 100, PaddleHeight: 4}
+	tests := []struct {
+		name string
+		ball breakout.BallState
+		want float64
+	}{
+		// paddle line at 100-4-2 = 94
+		{"straight down", breakout.BallState{X: 30, Y: 54, Radius: 2, VX: 0, VY: 2}, 30},
+		{"diagonal", breakout.BallState{X: 30, Y: 54, Radius: 2, VX: 1, VY: 2}, 50},
+		{"off the right wall", breakout.BallState{X: 80, Y: 54, Radius: 2, VX: 1, VY: 1}, 76},
+		// rising: up 52 to the top wall and down 92 to the paddle line, 36 to
+		// the left and back off the left wall
+		{"off the top wall", breakout.BallState{X: 10, Y: 54, Radius: 2, VX: -0.25, VY: -1}, 30},
+	}
+	for _, tt := range tests {
+		if got := landing(state, tt.ball); got != tt.want {
+			t.Errorf("%s: expected landing at %g, got %g", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPredictorBeatsTracker(t *testing.T) {
+	predictor, tracker := 0, 0
+	for seed := range int64(5) {
+		predictor += playScore(NewPredictor(), seed+1, 5000)
+		tracker += playScore(NewTracker(), seed+1, 5000)
+	}
+	if predictor < tracker {
+		t.Errorf("Expected the predictor to score at least as much as the tracker, got %d and %d", predictor, tracker)
+	}
+}
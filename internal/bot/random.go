@@ -0,0 +1,45 @@
+// Package bot provides the random policy.
+//
+// Types:
+// - Random: Presses random keys.
+//
+// Functions:
+// - NewRandom: Creates a Random policy.
+package bot
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"math/rand/v2"
+	"time"
+)
+
+// randomStream is the stream of the PCG source of Random
+const randomStream = 0xb07
+
+// randomInputs are the inputs Random picks from, all with fire
+var randomInputs = []replay.Input{
+	replay.InputFire,
+	replay.InputLeft | replay.InputFire,
+	replay.InputRight | replay.InputFire,
+}
+
+// Random picks no key, left or right with equal probability every frame and
+// fires. It is the baseline every agent should beat.
+type Random struct {
+	rng *rand.Rand
+}
+
+// NewRandom returns a random policy drawing from a source seeded with seed,
+// 0 for a time based seed
+func NewRandom(seed int64) *Random {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Random{rng: rand.New(rand.NewPCG(uint64(seed), randomStream))}
+}
+
+// Act returns a random input, the state is ignored
+func (r *Random) Act(state *breakout.BreakoutState) replay.Input {
+	return randomInputs[r.rng.IntN(len(randomInputs))]
+}
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/replay"
+	"testing"
+)
+
+func TestRandomIsSeeded(t *testing.T) {
+	a, b := NewRandom(3), NewRandom(3)
+	state := &breakout.BreakoutState{}
+	counts := map[replay.Input]int{}
+	for range 300 {
+		in := a.Act(state)
+		if in != b.Act(state) {
+			t.Fatal("Expected the same inputs for the same seed")
+		}
+		counts[in]++
+	}
+	if len(counts) != len(randomInputs) {
+		t.Errorf("Expected all %d inputs, got %v", len(randomInputs), counts)
+	}
+}
+
+func TestRandomIsBaseline(t *testing.T) {
+	random, tracker := 0, 0
+	for seed := range int64(5) {
+		random += playScore(NewRandom(seed+1), seed+1, 5000)
+		tracker += playScore(NewTracker(), seed+1, 5000)
+	}
+	if random >= tracker {
+		t.Errorf("Expected the random bot to score less than the tracker, got %d and %d", random, tracker)
+	}
+}
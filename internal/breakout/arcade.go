@@ -0,0 +1,71 @@
+// Package breakout provides the opt-in arcade ruleset (Config.ArcadeRules)
+// that follows the difficulty curve of the original Atari Breakout.
+//
+// With the arcade rules the ball speeds up
+// - after it was returned 4 and 12 times with the paddle,
+// - on its first contact with an orange brick,
+// - on its first contact with a red brick,
+// and the paddle shrinks to half its width when the ball first hits the top
+// wall. The counters belong to the serve and count the contacts of all balls
+// in play, a speed-up speeds up every ball (see ballSpeed). A new serve
+// (after a lost life or on level advance) starts with the configured speed
+// and paddle.
+//
+// Types:
+// - arcadeState: Counters of the arcade ruleset for the current serve.
+//
+// Functions:
+// - (*Breakout) arcadeContact: Applies the arcade rules to a ball contact.
+// - (arcadeState) speedups: Counts the speed-ups triggered so far.
+package breakout
+
+// ARCADE_SPEEDUP is the fraction of the configured ball speed added on
+// every arcade speed-up
+const ARCADE_SPEEDUP = 0.25
+
+type arcadeState struct {
+	hits    int  // number of paddle hits of all balls
+	orange  bool // a ball has hit an orange brick
+	red     bool // a ball has hit a red brick
+	ceiling bool // a ball has hit the top wall and the paddle is shrunk
+}
+
+// arcadeContact updates the arcade counters with a contact of a ball and
+// speeds up all balls or shrinks the paddle when a rule is triggered.
+func (b *Breakout) arcadeContact(c contact) {
+	a := &b.arcade
+	speedUp := false
+	switch {
+	case c.paddle:
+		a.hits++
+		speedUp = a.hits == 4 || a.hits == 12
+	case c.brick != nil:
+		switch c.brick.GetColor() {
+		case "orange":
+			speedUp = !a.orange
+			a.orange = true
+		case "red":
+			speedUp = !a.red
+			a.red = true
+		}
+	case c.ceiling:
+		if !a.ceiling {
+			a.ceiling = true
+			b.paddle.SetWidth(b.paddleWidth())
+		}
+	}
+	if speedUp {
+		b.setBallSpeed()
+	}
+}
+
+// speedups returns the number of speed-ups the counters have triggered
+func (a arcadeState) speedups() int {
+	n := 0
+	for _, triggered := range []bool{a.hits >= 4, a.hits >= 12, a.orange, a.red} {
+		if triggered {
+			n++
+		}
+	}
+	return n
+}
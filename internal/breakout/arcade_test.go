@@ -0,0 +1,197 @@
+package breakout
+
+import (
+	"math"
+	"testing"
+)
+
+func newArcadeGame() *Breakout {
+	cfg := DefaultConfig()
+	cfg.ArcadeRules = true
+	return NewBreakout(cfg, 1)
+}
+
+func TestArcadeSpeedUpAfterPaddleHits(t *testing.T) {
+	breakout := newArcadeGame()
+	ball := breakout.balls[0]
+	base := ball.GetSpeed()
+
+	for i := 1; i <= 12; i++ {
+		breakout.arcadeContact(contact{paddle: true})
+		expected := base
+		if i >= 4 {
+			expected += base * ARCADE_SPEEDUP
+		}
+		if i >= 12 {
+			expected += base * ARCADE_SPEEDUP
+		}
+		if math.Abs(ball.GetSpeed()-expected) > 1e-9 {
+			t.Errorf("Expected speed %f after %d hits, got %f", expected, i, ball.GetSpeed())
+		}
+	}
+}
+
+func TestArcadeSpeedUpOnOrangeAndRedOnce(t *testing.T) {
+	breakout := newArcadeGame()
+	ball := breakout.balls[0]
+	base := ball.GetSpeed()
+	orange := breakout.bricks[4][0]
+	red := breakout.bricks[6][0]
+
+	breakout.arcadeContact(contact{brick: orange})
+	breakout.arcadeContact(contact{brick: orange})
+	breakout.arcadeContact(contact{brick: breakout.bricks[0][0]})
+	if math.Abs(ball.GetSpeed()-base*(1+ARCADE_SPEEDUP)) > 1e-9 {
+		t.Errorf("Expected one speed-up for the orange row, got speed %f", ball.GetSpeed())
+	}
+	breakout.arcadeContact(contact{brick: red})
+	breakout.arcadeContact(contact{brick: red})
+	if math.Abs(ball.GetSpeed()-base*(1+2*ARCADE_SPEEDUP)) > 1e-9 {
+		t.Errorf("Expected one more speed-up for the red row, got speed %f", ball.GetSpeed())
+	}
+}
+
+func TestArcadeSpeedUpKeepsDirection(t *testing.T) {
+	breakout := newArcadeGame()
+	ball := breakout.balls[0]
+	ball.SetDir(30)
+	ratio := ball.v_y / ball.v_x
+
+	breakout.arcadeContact(contact{brick: breakout.bricks[6][0]})
+
+	if math.Abs(ball.v_y/ball.v_x-ratio) > 1e-9 {
+		t.Error("Expected the speed-up to keep the direction of the ball")
+	}
+}
+
+func TestArcadeCeilingShrinksPaddle(t *testing.T) {
+	breakout := newArcadeGame()
+	ball := breakout.balls[0]
+	for _, row := range breakout.bricks {
+		for _, brick := range row {
+			brick.SetCleared(true)
+		}
+	}
+	// leave one brick so the level does not advance
+	breakout.bricks[0][0].SetCleared(false)
+	ball.SetDir(270)
+	ball.x = AREA_WIDTH / 2
+	ball.y = float64(ball.radius) + 1
+
+	breakout.MoveBall()
+
+	if breakout.paddle.GetWidth() != PADDLE_WIDTH/2 {
+		t.Errorf("Expected paddle to shrink to %d, got %d", PADDLE_WIDTH/2, breakout.paddle.GetWidth())
+	}
+}
+
+func TestArcadeRulesOptIn(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := breakout.balls[0]
+	base := ball.GetSpeed()
+	for _, row := range breakout.bricks {
+		for _, brick := range row {
+			brick.SetCleared(true)
+		}
+	}
+	breakout.bricks[0][0].SetCleared(false)
+	ball.SetDir(270)
+	ball.x = AREA_WIDTH / 2
+	ball.y = float64(ball.radius) + 1
+
+	breakout.MoveBall()
+
+	if breakout.paddle.GetWidth() != PADDLE_WIDTH || ball.GetSpeed() != base {
+		t.Error("Expected no arcade rules without Config.ArcadeRules")
+	}
+}
+
+func TestArcadeCountersResetOnServe(t *testing.T) {
+	breakout := newArcadeGame()
+	breakout.arcadeContact(contact{brick: breakout.bricks[6][0]})
+	breakout.arcadeContact(contact{ceiling: true})
+	breakout.balls[0].y = AREA_HEIGHT + 1 // Simulate ball falling out of bounds
+
+	breakout.MoveBall()
+
+	if breakout.balls[0].GetSpeed() != BALL_SPEED || breakout.paddle.GetWidth() != PADDLE_WIDTH {
+		t.Error("Expected a new serve to start with the configured speed and paddle")
+	}
+	if breakout.arcade != (arcadeState{}) {
+		t.Errorf("Expected arcade counters to be reset, got %+v", breakout.arcade)
+	}
+}
+
+func TestArcadeCountsStickyPaddleHits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ArcadeRules = true
+	cfg.PowerUps = true
+	breakout := NewBreakout(cfg, 1)
+	breakout.activate(PowerSticky)
+	ball := breakout.balls[0]
+	paddle := breakout.paddle
+	base := ball.GetSpeed()
+
+	for i := 1; i <= 4; i++ {
+		ball.SetDir(90)
+		ball.held = 0
+		ball.x = float64(paddle.x + paddle.width/2)
+		ball.y = float64(AREA_HEIGHT-paddle.height-ball.radius) - 1
+		breakout.MoveBall()
+		if ball.held == 0 {
+			t.Fatalf("Expected the sticky paddle to hold the ball on hit %d", i)
+		}
+	}
+	if breakout.arcade.hits != 4 {
+		t.Errorf("Expected 4 counted paddle hits, got %d", breakout.arcade.hits)
+	}
+	if expected := base + base*ARCADE_SPEEDUP; math.Abs(ball.GetSpeed()-expected) > 1e-9 {
+		t.Errorf("Expected speed %f after 4 sticky hits, got %f", expected, ball.GetSpeed())
+	}
+}
+
+func TestArcadeSpeedUpDuringSlow(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ArcadeRules = true
+	cfg.PowerUps = true
+	breakout := NewBreakout(cfg, 1)
+	ball := breakout.balls[0]
+	base := ball.GetSpeed()
+
+	breakout.activate(PowerSlow)
+	breakout.arcadeContact(contact{brick: breakout.bricks[6][0]})
+	if expected := base * (1 + ARCADE_SPEEDUP) * SLOW_FACTOR; math.Abs(ball.GetSpeed()-expected) > 1e-9 {
+		t.Errorf("Expected slowed speed %f after the speed-up, got %f", expected, ball.GetSpeed())
+	}
+	for range POWERUP_DURATION {
+		breakout.updatePowerUps()
+	}
+	if expected := base * (1 + ARCADE_SPEEDUP); math.Abs(ball.GetSpeed()-expected) > 1e-9 {
+		t.Errorf("Expected speed %f after the slow power-up, got %f", expected, ball.GetSpeed())
+	}
+}
+
+func TestArcadeSpeedUpAppliesToAllBalls(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ArcadeRules = true
+	cfg.PowerUps = true
+	breakout := NewBreakout(cfg, 1)
+	base := breakout.balls[0].GetSpeed()
+	breakout.activate(PowerMultiBall)
+
+	// the paddle hits of all balls add up
+	for range 4 {
+		breakout.arcadeContact(contact{paddle: true})
+	}
+	for i, ball := range breakout.balls {
+		if expected := base * (1 + ARCADE_SPEEDUP); math.Abs(ball.GetSpeed()-expected) > 1e-9 {
+			t.Errorf("Expected ball %d at speed %f after 4 paddle hits, got %f", i, expected, ball.GetSpeed())
+		}
+	}
+	breakout.activate(PowerMultiBall)
+	for i, ball := range breakout.balls {
+		if expected := base * (1 + ARCADE_SPEEDUP); math.Abs(ball.GetSpeed()-expected) > 1e-9 {
+			t.Errorf("Expected split ball %d at speed %f, got %f", i, expected, ball.GetSpeed())
+		}
+	}
+}
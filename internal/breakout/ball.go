@@ -3,32 +3,44 @@
 //
 // The Ball struct represents a ball with properties such as position, radius, speed, and direction.
 // It includes methods for creating a new ball, retrieving its properties, updating its direction,
-// moving it within the game area, and reversing its velocity components.
+// moving it within the game area, splitting it into more balls, and reversing its velocity components.
 package breakout
 
 import (
 	"fmt"
 	"math"
-	"math/rand"
+	"math/rand/v2"
 )
 
+type BallState struct {
+	X, Y   int     // coordinates of the ball
+	Radius int     // radius of the ball
+	VX, VY float64 // distance the ball moves on every frame in x and y
+}
+
 type Ball struct {
-	x, y     float64 // x and y coordinates of the ball
-	radius   int     // radius of the ball
-	dir      float64 // degree of speed vectore of the ball
-	speed    float64 // speed of the ball
-	v_x, v_y float64 // x and y speed of the ball
+	x, y       float64 // x and y coordinates of the ball
+	radius     int     // radius of the ball
+	dir        float64 // degree of speed vectore of the ball
+	speed      float64 // speed of the ball
+	v_x, v_y   float64 // x and y speed of the ball
+	cfg        *Config // game configuration, holds the game area dimensions
+	held       int     // frames the sticky paddle still holds the ball
+	heldOffset float64 // x offset of a held ball from the paddle x coordinate
 }
 
-// NewBall creates a new Ball with the given x, y coordinates, radius, and direction
-func NewBall() *Ball {
+// NewBall creates a new Ball in the middle of the game area described by cfg.
+// The x coordinate and the serve direction are drawn from rng, so the same
+// source always serves the same ball.
+func NewBall(cfg *Config, rng *rand.Rand) *Ball {
 	b := &Ball{
-		x:      float64(rand.Intn(AREA_WIDTH)-6) + 3,
-		y:      AREA_HEIGHT / 2,
-		radius: 2,
-		speed:  3,
+		x:      float64(rng.IntN(cfg.AreaWidth)-6) + 3,
+		y:      float64(cfg.AreaHeight / 2),
+		radius: cfg.BallRadius,
+		speed:  cfg.BallSpeed,
+		cfg:    cfg,
 	}
-	if rand.Intn(2) == 0 {
+	if rng.IntN(2) == 0 {
 		b.SetDir(45)
 	} else {
 		b.SetDir(135)
@@ -51,6 +63,20 @@ func (b *Ball) GetRadius() int {
 	return b.radius
 }
 
+// GetSpeed returns the speed of the Ball
+func (b *Ball) GetSpeed() float64 {
+	return b.speed
+}
+
+// SetSpeed sets the speed of the Ball keeping its current direction of motion
+func (b *Ball) SetSpeed(speed float64) {
+	if b.speed > 0 {
+		b.v_x *= speed / b.speed
+		b.v_y *= speed / b.speed
+	}
+	b.speed = speed
+}
+
 // GetDir returns the direction of the Ball
 func (b *Ball) GetDir() float64 {
 	return b.dir
@@ -61,22 +87,12 @@ func (b *Ball) SetDir(dir float64) {
 	b.dir = dir
 	b.v_x = b.speed * math.Cos(dir*math.Pi/180)
 	b.v_y = b.speed * math.Sin(dir*math.Pi/180)
-	if b.v_x > 10 {
-		b.v_x = 10
-	}
-	if b.v_x < -10 {
-		b.v_x = -10
-	}
-	if b.v_y > BRICK_HEIGHT {
-		b.v_y = BRICK_HEIGHT
-	}
-	if b.v_y < -BRICK_HEIGHT {
-		b.v_y = -BRICK_HEIGHT
-	}
 }
 
 // Move moves the ball in the direction of the dir value
-// with the given speed by updating the x and y coordinates
+// with the given speed by updating the x and y coordinates.
+// It only bounces off the walls, the game itself sweeps the ball
+// against walls, bricks and paddle in Breakout.MoveBall.
 func (b *Ball) Move() error {
 	b.x += b.v_x
 	b.y += b.v_y
@@ -86,9 +102,9 @@ func (b *Ball) Move() error {
 			b.v_x = -b.v_x
 		}
 	}
-	if int(b.x) >= AREA_WIDTH-b.radius {
+	if int(b.x) >= b.cfg.AreaWidth-b.radius {
 		if b.v_x > 0 {
-			b.x = float64(AREA_WIDTH - b.radius)
+			b.x = float64(b.cfg.AreaWidth - b.radius)
 			b.v_x = -b.v_x
 		}
 	}
@@ -98,9 +114,9 @@ func (b *Ball) Move() error {
 			b.v_y = -b.v_y
 		}
 	}
-	if int(b.y) > AREA_HEIGHT-b.radius {
+	if int(b.y) > b.cfg.AreaHeight-b.radius {
 		if b.v_y > 0 {
-			b.y = float64(AREA_HEIGHT - b.radius)
+			b.y = float64(b.cfg.AreaHeight - b.radius)
 			b.v_y = -b.v_y
 		}
 		return fmt.Errorf("Ball is out of bounds")
@@ -108,6 +124,20 @@ func (b *Ball) Move() error {
 	return nil
 }
 
+// GetState returns the state of the Ball
+func (b *Ball) GetState() BallState {
+	return BallState{X: b.GetX(), Y: b.GetY(), Radius: b.radius, VX: b.v_x, VY: b.v_y}
+}
+
+// Split returns a copy of the Ball moving at the given angle (in degrees)
+// to the direction of the Ball
+func (b *Ball) Split(angle float64) *Ball {
+	nb := *b
+	nb.held = 0
+	nb.SetDir(math.Atan2(b.v_y, b.v_x)*180/math.Pi + angle)
+	return &nb
+}
+
 // ReverseVX reverses the x direction of the Ball
 func (b *Ball) ReverseVX() {
 	b.v_x = -b.v_x
@@ -2,11 +2,17 @@ package breakout
 
 import (
 	"math"
+	"math/rand/v2"
 	"testing"
 )
 
+// testRand returns a fixed random source so ball tests are repeatable
+func testRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
 func TestBallNew(t *testing.T) {
-	ball := NewBall()
+	ball := NewBall(&testConfig, testRand())
 	ball.SetDir(45)
 
 	if ball.GetY() != AREA_HEIGHT/2 {
@@ -23,7 +29,7 @@ func TestBallNew(t *testing.T) {
 }
 
 func TestBallSetDir(t *testing.T) {
-	ball := NewBall()
+	ball := NewBall(&testConfig, testRand())
 	ball.SetDir(90)
 
 	if ball.GetDir() != 90 {
@@ -43,7 +49,7 @@ func TestBallSetDir(t *testing.T) {
 }
 
 func TestBallMove(t *testing.T) {
-	ball := NewBall()
+	ball := NewBall(&testConfig, testRand())
 	ball.x = AREA_WIDTH / 2
 	ball.SetDir(45) // Set direction to 45 degrees
 
@@ -58,7 +64,7 @@ func TestBallMove(t *testing.T) {
 }
 
 func TestBallReverseVX(t *testing.T) {
-	ball := NewBall()
+	ball := NewBall(&testConfig, testRand())
 	initialVX := ball.v_x
 	ball.ReverseVX()
 
@@ -68,7 +74,7 @@ func TestBallReverseVX(t *testing.T) {
 }
 
 func TestBallReverseVY(t *testing.T) {
-	ball := NewBall()
+	ball := NewBall(&testConfig, testRand())
 	initialVY := ball.v_y
 	ball.ReverseVY()
 
@@ -78,7 +84,7 @@ func TestBallReverseVY(t *testing.T) {
 }
 
 func TestBallCollisionWithWalls(t *testing.T) {
-	ball := NewBall()
+	ball := NewBall(&testConfig, testRand())
 
 	// Test collision with left wall
 	ball.x = float64(ball.radius)
@@ -106,7 +112,7 @@ func TestBallCollisionWithWalls(t *testing.T) {
 }
 
 func TestBallSetDirLimits(t *testing.T) {
-	ball := NewBall()
+	ball := NewBall(&testConfig, testRand())
 	ball.SetDir(45)
 
 	if ball.v_x > 10 {
@@ -118,28 +124,33 @@ func TestBallSetDirLimits(t *testing.T) {
 	}
 }
 
-func TestBallTooHighSpeed(t *testing.T) {
-	ball := NewBall()
+func TestBallHighSpeedNotCapped(t *testing.T) {
+	ball := NewBall(&testConfig, testRand())
 	ball.speed = 20
 	ball.SetDir(0)
 
-	if ball.v_x > 10 {
-		t.Errorf("Expected v_x to be <= 10, got %f", ball.v_x)
+	if ball.v_x != 20 {
+		t.Errorf("Expected v_x to be 20, got %f", ball.v_x)
 	}
 
 	ball.SetDir(90)
-	if ball.v_y > BRICK_HEIGHT {
-		t.Errorf("Expected v_y to be <= BRICK_HEIGHT, got %f", ball.v_y)
+	if math.Abs(ball.v_y-20) > 1e-9 {
+		t.Errorf("Expected v_y to be 20, got %f", ball.v_y)
 	}
+}
 
-	ball.SetDir(180)
-	if ball.v_x < -10 {
-		t.Errorf("Expected v_x to be >= -10, got %f", ball.v_x)
-	}
+func TestBallSplit(t *testing.T) {
+	ball := NewBall(&testConfig, testRand())
+	ball.SetDir(45)
+	split := ball.Split(30)
 
-	ball.SetDir(270)
-	if ball.v_y < -BRICK_HEIGHT {
-		t.Errorf("Expected v_y to be >= -BRICK_HEIGHT, got %f", ball.v_y)
+	if split == ball || split.x != ball.x || split.y != ball.y {
+		t.Fatal("Expected a new ball at the same position")
+	}
+	if math.Abs(split.GetDir()-75) > 1e-9 {
+		t.Errorf("Expected direction 75, got %f", split.GetDir())
+	}
+	if math.Abs(math.Hypot(split.v_x, split.v_y)-ball.speed) > 1e-9 {
+		t.Error("Expected the split ball to keep the speed")
 	}
-
 }
@@ -2,8 +2,7 @@
 // It includes the game state, mechanics, and interactions between game elements
 // such as the ball, paddle, and bricks.
 
-//
-// Constants:
+// Constants (defaults of the game Config):
 // - AREA_WIDTH: The width of the game area.
 // - AREA_HEIGHT: The height of the game area.
 // - BRICKS_PER_ROW: The number of bricks in each row.
@@ -11,6 +10,9 @@
 // - TOP_OFFSET: The vertical offset from the top of the game area.
 // - BRICK_HEIGHT: The height of each brick.
 //
+// Constants:
+// - ENGINE_VERSION: The version of the game rules, recorded in replays.
+//
 // Variables:
 // - ErrGameOver: An error indicating that the game is over.
 //
@@ -19,19 +21,26 @@
 // - BreakoutState: Represents the current state of the game, used for rendering or external interaction.
 //
 // Functions:
-// - NewBreakout: Creates and initializes a new Breakout game instance.
+// - NewBreakout: Creates and initializes a new Breakout game instance from a config and a seed.
+// - (*Breakout) GetConfig: Returns the configuration the game is played with.
 // - (*Breakout) GetState: Returns the current state of the game as a BreakoutState.
-// - (*Breakout) MoveBall: Updates the ball's position and handles collisions with bricks, the paddle, and the game area.
+// - (*Breakout) Lives: Returns the number of lives left.
+// - (*Breakout) LastFrame: Returns the summary of the last frame, see record.go.
+// - (*Breakout) Step, Events, AddListener: Play a frame and get its events, see events.go.
+// - (*Breakout) MoveBall: Sweeps the ball along its motion and handles collisions with bricks, the paddle, and the game area.
 // - (*Breakout) PaddleRight: Moves the paddle to the right.
 // - (*Breakout) PaddleLeft: Moves the paddle to the left.
 // - (*Breakout) PaddleShrink: Shrinks the paddle's width.
 // - (*Breakout) PaddleUnShrink: Restores the paddle's width to its original size.
-// - (*Breakout) CheckColision: Checks for collisions between the ball and a brick, returning whether the ball should reverse its x or y velocity.
-// - (*Breakout) CheckPaddleColision: Checks for collisions between the ball and the paddle, adjusting the ball's direction if necessary.
+// - (*Breakout) CheckColision: Checks for an overlap between the ball and a brick at the ball's current position, returning whether the ball should reverse its x or y velocity.
+// - (*Breakout) CheckPaddleColision: Checks for an overlap between the ball and the paddle, adjusting the ball's direction if necessary.
+//
+// MoveBall does not use the CheckColision functions, it uses the swept collision pass from collision.go.
 package breakout
 
 import (
 	"errors"
+	"math/rand/v2"
 )
 
 const (
@@ -45,74 +54,163 @@ const (
 
 var ErrGameOver = errors.New("game over")
 
+// ENGINE_VERSION is the version of the game rules. Change it whenever a game
+// plays out differently for the same config, seed and input, so recorded
+// games (see internal/replay) are not replayed with other rules.
+const ENGINE_VERSION = 2
+
+// pcgStream is the fixed second half of the PCG state, so a game is fully
+// described by a single seed value.
+const pcgStream = 0x9e3779b97f4a7c15
+
 type Breakout struct {
-	ball        *Ball
+	balls       []*Ball // balls in play, the first one is the served ball
 	bricks      [][]*Brick
 	score       int
 	level       int
 	live        int
 	paddle      *Paddle // Paddle is a struct that represents the paddle in the game
 	frameReward int     // reward for the current frame
+	events      []Event // events of the current frame, see events.go
+
+	cfg    *Config     // game configuration, shared with the ball, paddle and bricks
+	arcade arcadeState // counters of the arcade ruleset for the current serve
+
+	// Power-ups
+	capsules []*Capsule       // falling capsules
+	lasers   []*Laser         // laser shots
+	effects  [numPowerUps]int // frames left of the timed power-ups
+
+	// Random source owned by the game, every random decision is drawn from it
+	seed int64
+	src  *rand.PCG
+	rng  *rand.Rand
 
 	// Game state
 	gameOver bool
+	won      bool // all levels of the level sequence are cleared
+
+	// Listeners of the events, see events.go
+	listeners    []listener
+	nextListener int
 }
 
 type BreakoutState struct {
-	BallX, BallY  int          // current ball coordinates
-	BallRadius    int          // ball radius
-	Width, Height int          // screen dimensions
-	PaddleX       int          // paddle x coordinate
-	PaddleWidth   int          // paddle width
-	PaddleHeight  int          // paddle height
-	Bricks        []BrickState // bricks state
-	Level         int          // current level
-	Score         int          // current score
-	Live          int          // current live
-	FrameReward   int          // reward for the current frame
-	Done          bool         // game over
+	BallX, BallY  int            // current coordinates of the first ball
+	BallRadius    int            // radius of the first ball
+	Balls         []BallState    // all balls in play
+	Width, Height int            // screen dimensions
+	PaddleX       int            // paddle x coordinate
+	PaddleWidth   int            // paddle width
+	PaddleHeight  int            // paddle height
+	Bricks        []BrickState   // bricks state
+	Level         int            // current level
+	Score         int            // current score
+	Live          int            // current live
+	Lives         int            // lives left, including the ball in play (see Breakout.Lives)
+	FrameReward   int            // reward for the current frame
+	Done          bool           // game over
+	Won           bool           // game over by clearing the last level of the level sequence
+	Capsules      []CapsuleState // falling power-up capsules
+	Effects       []EffectState  // active timed power-ups
+	Lasers        []LaserState   // laser shots
 }
 
-func NewBreakout() *Breakout {
-	bricks := make([][]*Brick, BRICK_ROWS)
-	for i := 0; i < BRICK_ROWS; i++ {
-		bricks[i] = make([]*Brick, BRICKS_PER_ROW)
-		for j := 0; j < BRICKS_PER_ROW; j++ {
-			bricks[i][j] = NewBrick(i, j)
-		}
-	}
+// NewBreakout creates a new game played on the playfield described by cfg.
+// Zero sizes of cfg are replaced by their defaults (see Config.WithDefaults),
+// callers accepting configs from outside should check them with
+// Config.Validate first.
+// All random decisions (serves, resets after a lost life, level advance) are
+// drawn from a source seeded with seed. The same config, seed and input
+// sequence always give the same game, for every seed including 0. The
+// commands and environments take 0 to mean "pick a time based seed", so seed
+// 0 itself is only reachable through NewBreakout; the seed they picked is
+// logged or reported (see Seed), so those games can be replayed too.
+func NewBreakout(cfg Config, seed int64) *Breakout {
+	cfg = cfg.WithDefaults()
+	src := rand.NewPCG(uint64(seed), pcgStream)
+	rng := rand.New(src)
+	bricks, _ := levelBricks(&cfg, 1)
 	// Initialize the paddle
-	paddle := NewPaddle()
+	paddle := NewPaddle(&cfg)
 
 	return &Breakout{
-		ball:     NewBall(),
+		cfg:      &cfg,
+		balls:    []*Ball{NewBall(&cfg, rng)},
 		bricks:   bricks,
 		paddle:   paddle,
 		score:    0,
 		level:    1,
 		live:     1,
 		gameOver: false,
+		seed:     seed,
+		src:      src,
+		rng:      rng,
+	}
+}
+
+// levelBricks builds the brick grid of the given level. Without level
+// layouts every level is the full brick grid. It returns false if the level
+// sequence has no layout for the level.
+func levelBricks(cfg *Config, level int) ([][]*Brick, bool) {
+	if cfg.Levels == nil {
+		return newBricks(cfg), true
+	}
+	layout, ok := cfg.Levels.Layout(level)
+	if !ok {
+		return nil, false
 	}
+	return layout.Bricks(cfg), true
+}
+
+// newBricks builds the full brick grid described by cfg
+func newBricks(cfg *Config) [][]*Brick {
+	bricks := make([][]*Brick, cfg.BrickRows)
+	for i := range cfg.BrickRows {
+		bricks[i] = make([]*Brick, cfg.BricksPerRow)
+		for j := range cfg.BricksPerRow {
+			bricks[i][j] = NewBrick(cfg, i, j)
+		}
+	}
+	return bricks
+}
+
+// Seed returns the seed the game was created with.
+func (b *Breakout) Seed() int64 {
+	return b.seed
+}
+
+// GetConfig returns the configuration the game is played with.
+func (b *Breakout) GetConfig() Config {
+	return *b.cfg
+}
+
+// Lives returns the number of lives left, including the ball in play. It is
+// 0 once the game is lost.
+func (b *Breakout) Lives() int {
+	return max(MAX_LIVES+1-b.live, 0)
 }
 
 func (b *Breakout) GetState() BreakoutState {
 	state := BreakoutState{
-		BallX:        b.ball.GetX(),
-		BallY:        b.ball.GetY(),
-		BallRadius:   b.ball.GetRadius(),
-		Width:        AREA_WIDTH,
-		Height:       AREA_HEIGHT,
+		BallX:        b.balls[0].GetX(),
+		BallY:        b.balls[0].GetY(),
+		BallRadius:   b.balls[0].GetRadius(),
+		Width:        b.cfg.AreaWidth,
+		Height:       b.cfg.AreaHeight,
 		PaddleX:      b.paddle.GetX(),
 		PaddleWidth:  b.paddle.GetWidth(),
 		PaddleHeight: b.paddle.GetHeight(),
 		Level:        b.level,
 		Score:        b.score,
 		Live:         b.live,
+		Lives:        b.Lives(),
 		Done:         b.gameOver,
+		Won:          b.won,
 		FrameReward:  b.frameReward,
 	}
-	for i := range BRICK_ROWS {
-		for j := range BRICKS_PER_ROW {
+	for i := range b.bricks {
+		for j := range b.bricks[i] {
 			if b.bricks[i][j] != nil {
 				if !b.bricks[i][j].IsCleared() {
 					state.Bricks = append(state.Bricks, b.bricks[i][j].GetState())
@@ -120,77 +218,143 @@ func (b *Breakout) GetState() BreakoutState {
 			}
 		}
 	}
+	for _, bl := range b.balls {
+		state.Balls = append(state.Balls, bl.GetState())
+	}
+	state.Capsules, state.Effects, state.Lasers = b.powerUpState()
 	return state
 }
 
+// MoveBall advances the game by one frame. Every ball is swept along its
+// motion (see sweepBall), bricks it hits on the way are cleared and scored,
+// and the ball bounces off walls, bricks and the paddle within the frame.
+// A ball that drops out of bounds is removed, a life is lost when the last
+// ball drains.
 func (b *Breakout) MoveBall() {
+	b.events = nil
 	if b.gameOver {
 		return
 	}
-	err := b.ball.Move()
-	if err != nil {
+	defer b.dispatch()
+	hitPaddle := false
+	balls := make([]*Ball, 0, len(b.balls))
+	for _, bl := range b.balls {
+		if !b.moveHeldBall(bl) {
+			hitPaddle = b.sweepBall(bl) || hitPaddle
+		}
+		if bl.y+float64(bl.radius) <= float64(b.cfg.AreaHeight) {
+			balls = append(balls, bl)
+		}
+	}
+	b.balls = balls
+	if len(b.balls) == 0 {
+		// the last ball is out of bounds
 		b.live++
 		// b.score--
-		if b.live > 5 {
+		b.emit(LifeLost{Lives: b.Lives()})
+		if b.live > MAX_LIVES {
 			b.gameOver = true
+			b.emit(GameOver{Score: b.score})
 		}
 		b.frameReward = -10
-		b.ball = NewBall()
-		b.paddle = NewPaddle()
+		b.serve()
 		return
 	}
-	// check ball collisions with bricks
-	cleared := 0
-	xrev := false
-	yrev := false
+	if hitPaddle {
+		b.frameReward = 10
+	} else {
+		b.frameReward = 0
+	}
+	b.updatePowerUps()
+
+	// check if there is no more bricks left
+	// all bricks except the indestructible ones are cleared
+	if b.levelCleared() {
+		b.emit(LevelCleared{Level: b.level})
+		bricks, ok := levelBricks(b.cfg, b.level+1)
+		if !ok {
+			// the last level of the sequence is cleared
+			b.won = true
+			b.gameOver = true
+			b.emit(GameOver{Won: true, Score: b.score})
+			return
+		}
+		b.level++
+		b.bricks = bricks
+		b.serve()
+	}
+
+}
+
+// levelCleared returns true if all bricks except the indestructible ones
+// are cleared
+func (b *Breakout) levelCleared() bool {
 	for i := range b.bricks {
 		for j := range b.bricks[i] {
-			if b.bricks[i][j] != nil {
-				if !b.bricks[i][j].IsCleared() {
-					if !xrev && !yrev {
-						xr, yr := b.CheckColision(b.bricks[i][j], b.ball)
-						if xr || yr {
-							b.bricks[i][j].SetCleared(true)
-							b.score += b.bricks[i][j].GetPoints() * b.level
-							cleared++
-						}
-						xrev = xrev || xr
-						yrev = yrev || yr
-					}
-				} else {
-					cleared++
-				}
+			br := b.bricks[i][j]
+			if br != nil && !br.IsCleared() && br.GetKind() != BrickIndestructible {
+				return false
 			}
 		}
 	}
-	if xrev {
-		b.ball.ReverseVX()
-	}
-	if yrev {
-		b.ball.ReverseVY()
+	return true
+}
+
+// hitBrick applies a ball hit to the brick. A brick cleared by the hit is
+// scored and, if it is explosive, clears its neighbours.
+func (b *Breakout) hitBrick(br *Brick) {
+	if !br.Hit() {
+		b.emitBrickHit(br, false)
+		return
 	}
-	// check ball collisions with paddle
-	if b.CheckPaddleColision(b.paddle, b.ball) {
-		b.frameReward = 10
-	} else {
-		b.frameReward = 0
+	b.score += br.GetPoints() * b.level
+	b.emitBrickHit(br, true)
+	b.dropCapsule(br)
+	if br.GetKind() == BrickExplosive {
+		b.explode(br)
 	}
+}
 
-	// check if there is no more bricks left
-	// all bricks are cleared
-	if cleared == BRICKS_PER_ROW*BRICK_ROWS {
-		b.level++
-		b.bricks = make([][]*Brick, BRICK_ROWS)
-		for i := range BRICK_ROWS {
-			b.bricks[i] = make([]*Brick, BRICKS_PER_ROW)
-			for j := range BRICKS_PER_ROW {
-				b.bricks[i][j] = NewBrick(i, j)
+// explode clears and scores the up to eight neighbours of an explosive brick,
+// indestructible bricks survive and explosive neighbours explode in turn
+func (b *Breakout) explode(br *Brick) {
+	for i := br.GetRow() - 1; i <= br.GetRow()+1; i++ {
+		for j := br.GetCol() - 1; j <= br.GetCol()+1; j++ {
+			if i < 0 || i >= len(b.bricks) || j < 0 || j >= len(b.bricks[i]) {
+				continue
+			}
+			nb := b.bricks[i][j]
+			if nb == nil || nb.IsCleared() || nb.GetKind() == BrickIndestructible {
+				continue
+			}
+			nb.SetCleared(true)
+			b.score += nb.GetPoints() * b.level
+			b.emitBrickHit(nb, true)
+			b.dropCapsule(nb)
+			if nb.GetKind() == BrickExplosive {
+				b.explode(nb)
 			}
 		}
-		b.ball = NewBall()
-		b.paddle = NewPaddle()
 	}
+}
+
+// emitBrickHit emits the hit of a brick, a cleared brick scores its points
+// times the level
+func (b *Breakout) emitBrickHit(br *Brick, cleared bool) {
+	e := BrickHit{Row: br.GetRow(), Col: br.GetCol(), Color: br.GetColor(), Kind: br.GetKind().String(), Cleared: cleared}
+	if cleared {
+		e.Points = br.GetPoints() * b.level
+	}
+	b.emit(e)
+}
 
+// serve puts a single new ball and a new paddle into play, starts the arcade
+// counters over and ends all power-ups
+func (b *Breakout) serve() {
+	b.balls = []*Ball{NewBall(b.cfg, b.rng)}
+	b.paddle = NewPaddle(b.cfg)
+	b.arcade = arcadeState{}
+	b.clearPowerUps()
 }
 
 func (b *Breakout) PaddleRight() {
@@ -224,48 +388,48 @@ func (b *Breakout) CheckColision(br *Brick, bl *Ball) (bool, bool) {
 	yrev := false
 	if blX+blR > brX && blX-blR <= brX { //might be hiting the left side
 		if blY+blR > brY && blY+blR <= brY+brH { // right hight so colision is there and ball bounces
-			if b.ball.v_x > 0 {
+			if bl.v_x > 0 {
 				xrev = true
 			}
 		}
 		if blY-blR > brY && blY-blR <= brY+brH {
-			if b.ball.v_x > 0 {
+			if bl.v_x > 0 {
 				xrev = true
 			}
 		}
 	}
 	if blX-blR < brX+brW && blX+blR >= brX+brW { //might be hiting the right side
 		if blY+blR > brY && blY+blR <= brY+brH { // right hight so colision is there and ball bounces
-			if b.ball.v_x < 0 {
+			if bl.v_x < 0 {
 				xrev = true
 			}
 		}
 		if blY-blR > brY && blY-blR <= brY+brH {
-			if b.ball.v_x < 0 {
+			if bl.v_x < 0 {
 				xrev = true
 			}
 		}
 	}
 	if blY+blR > brY && blY-blR <= brY { //might be hiting the top side
 		if blX+blR > brX && blX+blR <= brX+brW { // right x position so colision is there and ball bounces
-			if b.ball.v_y > 0 {
+			if bl.v_y > 0 {
 				yrev = true
 			}
 		}
 		if blX-blR > brX && blX-blR <= brX+brW {
-			if b.ball.v_y > 0 {
+			if bl.v_y > 0 {
 				yrev = true
 			}
 		}
 	}
 	if blY-blR < brY+brH && blY+blR >= brY+brH { //might be hiting the bottom side
 		if blX+blR > brX && blX+blR <= brX+brW { // right x position so colision is there and ball bounces
-			if b.ball.v_y < 0 {
+			if bl.v_y < 0 {
 				yrev = true
 			}
 		}
 		if blX-blR > brX && blX-blR <= brX+brW {
-			if b.ball.v_y < 0 {
+			if bl.v_y < 0 {
 				yrev = true
 			}
 		}
@@ -280,7 +444,7 @@ func (b *Breakout) CheckPaddleColision(pa *Paddle, bl *Ball) bool {
 	blY := bl.GetY()
 	blR := bl.GetRadius()
 	paX := pa.GetX()
-	paY := AREA_HEIGHT - pa.GetHeight()
+	paY := b.cfg.AreaHeight - pa.GetHeight()
 	paW := pa.GetWidth()
 	paH := pa.GetHeight()
 	col := false
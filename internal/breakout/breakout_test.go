@@ -1,17 +1,18 @@
 package breakout
 
 import (
+	"reflect"
 	"testing"
 )
 
 func TestNewBreakout(t *testing.T) {
-	breakout := NewBreakout()
+	breakout := NewBreakout(DefaultConfig(), 1)
 
 	if breakout == nil {
 		t.Fatal("Expected Breakout instance, got nil")
 	}
 
-	if breakout.ball == nil {
+	if breakout.balls[0] == nil {
 		t.Error("Expected ball to be initialized")
 	}
 
@@ -31,19 +32,19 @@ func TestNewBreakout(t *testing.T) {
 }
 
 func TestMoveBall_GameOver(t *testing.T) {
-	breakout := NewBreakout()
+	breakout := NewBreakout(DefaultConfig(), 1)
 	breakout.gameOver = true
 
 	breakout.MoveBall()
 
-	if breakout.ball == nil {
+	if breakout.balls[0] == nil {
 		t.Error("Expected ball to remain unchanged when game is over")
 	}
 }
 
 func TestMoveBall_LifeLost(t *testing.T) {
-	breakout := NewBreakout()
-	breakout.ball.y = AREA_HEIGHT + 1 // Simulate ball falling out of bounds
+	breakout := NewBreakout(DefaultConfig(), 1)
+	breakout.balls[0].y = AREA_HEIGHT + 1 // Simulate ball falling out of bounds
 
 	breakout.MoveBall()
 
@@ -56,8 +57,23 @@ func TestMoveBall_LifeLost(t *testing.T) {
 	}
 }
 
+func TestLives(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	if breakout.Lives() != MAX_LIVES || breakout.GetState().Lives != MAX_LIVES {
+		t.Errorf("Expected %d lives, got %d", MAX_LIVES, breakout.Lives())
+	}
+
+	for range MAX_LIVES {
+		breakout.balls[0].y = AREA_HEIGHT + 1
+		breakout.MoveBall()
+	}
+	if breakout.Lives() != 0 || breakout.GetState().Lives != 0 || !breakout.gameOver {
+		t.Errorf("Expected game over with 0 lives, got %d", breakout.Lives())
+	}
+}
+
 func TestPaddleMovement(t *testing.T) {
-	breakout := NewBreakout()
+	breakout := NewBreakout(DefaultConfig(), 1)
 	initialX := breakout.paddle.GetX()
 
 	breakout.PaddleRight()
@@ -73,7 +89,7 @@ func TestPaddleMovement(t *testing.T) {
 }
 
 func TestPaddleShrinkUnshrink(t *testing.T) {
-	breakout := NewBreakout()
+	breakout := NewBreakout(DefaultConfig(), 1)
 	initialWidth := breakout.paddle.GetWidth()
 
 	breakout.PaddleShrink()
@@ -88,8 +104,8 @@ func TestPaddleShrinkUnshrink(t *testing.T) {
 }
 
 func TestCheckPaddleCollision(t *testing.T) {
-	breakout := NewBreakout()
-	ball := NewBall()
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := NewBall(breakout.cfg, breakout.rng)
 	paddle := breakout.paddle
 
 	ball.x = float64(paddle.x + paddle.width/2)
@@ -102,7 +118,7 @@ func TestCheckPaddleCollision(t *testing.T) {
 }
 
 func TestGetState(t *testing.T) {
-	breakout := NewBreakout()
+	breakout := NewBreakout(DefaultConfig(), 1)
 	state := breakout.GetState()
 
 	if state.Width != AREA_WIDTH || state.Height != AREA_HEIGHT {
@@ -122,10 +138,10 @@ func TestGetState(t *testing.T) {
 	}
 }
 func TestCheckCollision_BrickHit_FromRight(t *testing.T) {
-	breakout := NewBreakout()
-	ball := NewBall()
-	breakout.ball = ball
-	brick := NewBrick(1, 1)
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := NewBall(breakout.cfg, breakout.rng)
+	breakout.balls[0] = ball
+	brick := NewBrick(&testConfig, 1, 1)
 
 	// Simulate ball hitting the brick from right
 	ball.SetDir(180)
@@ -144,10 +160,10 @@ func TestCheckCollision_BrickHit_FromRight(t *testing.T) {
 }
 
 func TestCheckCollision_BrickHit_FromLeft(t *testing.T) {
-	breakout := NewBreakout()
-	ball := NewBall()
-	breakout.ball = ball
-	brick := NewBrick(1, 1)
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := NewBall(breakout.cfg, breakout.rng)
+	breakout.balls[0] = ball
+	brick := NewBrick(&testConfig, 1, 1)
 
 	// Simulate ball hitting the brick from left
 	ball.SetDir(0)
@@ -166,10 +182,10 @@ func TestCheckCollision_BrickHit_FromLeft(t *testing.T) {
 }
 
 func TestCheckCollision_BrickHit_FromTop(t *testing.T) {
-	breakout := NewBreakout()
-	ball := NewBall()
-	breakout.ball = ball
-	brick := NewBrick(1, 1)
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := NewBall(breakout.cfg, breakout.rng)
+	breakout.balls[0] = ball
+	brick := NewBrick(&testConfig, 1, 1)
 
 	// Simulate ball hitting the brick from top
 	ball.SetDir(90)
@@ -188,10 +204,10 @@ func TestCheckCollision_BrickHit_FromTop(t *testing.T) {
 }
 
 func TestCheckCollision_BrickHit_FromBottom(t *testing.T) {
-	breakout := NewBreakout()
-	ball := NewBall()
-	breakout.ball = ball
-	brick := NewBrick(1, 1)
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := NewBall(breakout.cfg, breakout.rng)
+	breakout.balls[0] = ball
+	brick := NewBrick(&testConfig, 1, 1)
 
 	// Simulate ball hitting the brick from bottom
 	ball.SetDir(270)
@@ -210,10 +226,10 @@ func TestCheckCollision_BrickHit_FromBottom(t *testing.T) {
 }
 
 func TestCheckCollision_BrickHit_AlreadyCleared(t *testing.T) {
-	breakout := NewBreakout()
-	ball := NewBall()
-	breakout.ball = ball
-	brick := NewBrick(1, 1)
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := NewBall(breakout.cfg, breakout.rng)
+	breakout.balls[0] = ball
+	brick := NewBrick(&testConfig, 1, 1)
 	brick.SetCleared(true)
 
 	// Simulate ball hitting the already cleared brick
@@ -233,9 +249,9 @@ func TestCheckCollision_BrickHit_AlreadyCleared(t *testing.T) {
 }
 
 func TestMoveBallAndClearBrick(t *testing.T) {
-	breakout := NewBreakout()
-	breakout.ball.x = float64(0)
-	breakout.ball.SetDir(45)
+	breakout := NewBreakout(DefaultConfig(), 1)
+	breakout.balls[0].x = float64(0)
+	breakout.balls[0].SetDir(45)
 	breakout.paddle.x = 110
 	for i := 0; i < 150; i++ {
 		breakout.MoveBall()
@@ -253,3 +269,153 @@ func TestMoveBallAndClearBrick(t *testing.T) {
 		t.Error("Expected one brick to be cleared")
 	}
 }
+
+func TestSameSeedSameTrajectory(t *testing.T) {
+	first := NewBreakout(DefaultConfig(), 42)
+	second := NewBreakout(DefaultConfig(), 42)
+
+	for i := 0; i < 3000; i++ {
+		// same scripted input for both games
+		if (i/40)%2 == 0 {
+			first.PaddleLeft()
+			second.PaddleLeft()
+		} else {
+			first.PaddleRight()
+			second.PaddleRight()
+		}
+		first.MoveBall()
+		second.MoveBall()
+		if !reflect.DeepEqual(first.GetState(), second.GetState()) {
+			t.Fatalf("Expected identical states for the same seed, diverged at frame %d", i)
+		}
+	}
+}
+
+func TestDifferentSeedsDifferentServe(t *testing.T) {
+	serves := map[int]bool{}
+	for seed := int64(0); seed < 10; seed++ {
+		serves[NewBreakout(DefaultConfig(), seed).GetState().BallX] = true
+	}
+	if len(serves) < 2 {
+		t.Error("Expected different seeds to serve the ball from different positions")
+	}
+}
+
+func TestExplosiveBrickClearsNeighbours(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	breakout.bricks[3][3].SetKind(BrickExplosive, 0)
+	breakout.bricks[3][4].SetKind(BrickExplosive, 0) // chain reaction to column 5
+	breakout.bricks[2][2].SetKind(BrickIndestructible, 0)
+
+	breakout.hitBrick(breakout.bricks[3][3])
+
+	for i := 2; i <= 4; i++ {
+		for j := 2; j <= 5; j++ {
+			brick := breakout.bricks[i][j]
+			if i == 2 && j == 2 {
+				if brick.IsCleared() {
+					t.Error("Expected indestructible brick to survive the explosion")
+				}
+				continue
+			}
+			if !brick.IsCleared() {
+				t.Errorf("Expected brick %d,%d to be cleared by the explosion", i, j)
+			}
+		}
+	}
+	if breakout.bricks[3][6].IsCleared() {
+		t.Error("Expected brick 3,6 outside the explosions to survive")
+	}
+	// row 2 bricks are worth 3 points, row 3 and 4 bricks 3 and 5 points
+	if expected := 3*3 + 4*3 + 4*5; breakout.score != expected {
+		t.Errorf("Expected score %d, got %d", expected, breakout.score)
+	}
+}
+
+func TestIndestructibleBricksDoNotBlockLevel(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	for _, row := range breakout.bricks {
+		for _, brick := range row {
+			brick.SetCleared(true)
+		}
+	}
+	breakout.bricks[7][0].SetCleared(false)
+	breakout.bricks[7][0].SetKind(BrickIndestructible, 0)
+
+	breakout.MoveBall()
+
+	if breakout.level != 2 {
+		t.Errorf("Expected level 2 with only indestructible bricks left, got %d", breakout.level)
+	}
+}
+
+func TestMultiHitBrickInGame(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	brick := clearBricksExcept(breakout, 0, 5)
+	brick.SetKind(BrickMultiHit, 2)
+
+	breakout.hitBrick(brick)
+	if brick.IsCleared() || breakout.score != 0 {
+		t.Error("Expected the first hit to leave the brick unscored")
+	}
+	state := breakout.GetState()
+	if len(state.Bricks) != 1 || state.Bricks[0].Kind != "multi" || state.Bricks[0].Hits != 1 {
+		t.Errorf("Expected state of a damaged multi-hit brick, got %+v", state.Bricks)
+	}
+	breakout.hitBrick(brick)
+	if !brick.IsCleared() || breakout.score != 1 {
+		t.Errorf("Expected the second hit to clear and score the brick, score %d", breakout.score)
+	}
+}
+
+func TestLifeLostOnlyWithLastBall(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	second := breakout.balls[0].Split(30)
+	breakout.balls = append(breakout.balls, second)
+	breakout.balls[0].y = AREA_HEIGHT + 1 // Simulate first ball falling out of bounds
+
+	breakout.MoveBall()
+
+	if breakout.live != 1 {
+		t.Errorf("Expected no life lost while a ball is in play, got live %d", breakout.live)
+	}
+	if len(breakout.balls) != 1 || breakout.balls[0] != second {
+		t.Fatalf("Expected the drained ball to be removed, got %d balls", len(breakout.balls))
+	}
+
+	second.y = AREA_HEIGHT + 1
+	breakout.MoveBall()
+
+	if breakout.live != 2 || len(breakout.balls) != 1 {
+		t.Errorf("Expected a life lost and a new serve with the last ball, got live %d with %d balls", breakout.live, len(breakout.balls))
+	}
+}
+
+func TestGetStateBalls(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	breakout.balls = append(breakout.balls, breakout.balls[0].Split(-30))
+	breakout.balls[1].x = 10
+	state := breakout.GetState()
+
+	if len(state.Balls) != 2 {
+		t.Fatalf("Expected 2 balls in the state, got %d", len(state.Balls))
+	}
+	if state.BallX != state.Balls[0].X || state.BallY != state.Balls[0].Y {
+		t.Error("Expected BallX and BallY to be the first ball")
+	}
+	if state.Balls[1].X != 10 || state.Balls[1].Radius != BALL_RADIUS {
+		t.Errorf("Unexpected state of the second ball %+v", state.Balls[1])
+	}
+}
+
+func TestSeedZeroIsReproducible(t *testing.T) {
+	a := NewBreakout(DefaultConfig(), 0)
+	b := NewBreakout(DefaultConfig(), 0)
+	for range 500 {
+		a.MoveBall()
+		b.MoveBall()
+	}
+	if !reflect.DeepEqual(a.GetState(), b.GetState()) {
+		t.Error("Expected seed 0 to give the same game every time")
+	}
+}
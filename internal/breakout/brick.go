@@ -6,8 +6,8 @@
 // has been cleared). The BrickState struct is used to encapsulate the
 // visual and positional state of a brick for rendering purposes.
 //
-// Constants such as AREA_WIDTH, BRICKS_PER_ROW, BRICK_HEIGHT, and TOP_OFFSET
-// are assumed to define the layout and dimensions of the game area.
+// The layout and dimensions of the bricks are taken from the game Config
+// (AreaWidth, BricksPerRow, BrickRows, BrickHeight and TopOffset).
 //
 // Functions and methods provided:
 // - NewBrick: Creates a new Brick instance with calculated dimensions and position.
@@ -17,40 +17,80 @@
 // - GetX, GetY: Get the x and y coordinates of the brick.
 // - CalcWidth: Calculate the width of the brick based on its column and layout.
 // - GetWidth, GetHeight: Get the width and height of the brick.
-// - GetColor: Determine the color of the brick based on its row.
-// - GetPoints: Determine the points awarded for clearing the brick based on its row.
+// - GetColor, SetColor: Get the color of the brick (based on its row unless set) or set it.
+// - GetPoints: Determine the points awarded for clearing the brick based on its color.
+// - GetKind, SetKind: Get or set the kind of the brick and its hit points.
+// - GetHits: Get the number of hits the brick still takes before it is cleared.
+// - Hit: Apply a ball hit to the brick, returning whether it got cleared.
 // - GetState: Retrieve the current state of the brick as a BrickState struct.
 //
 // The Brick struct uses the row index to determine the brick's color and
 // point value, with higher rows corresponding to higher point values and
-// different colors. The layout of bricks is calculated dynamically based
+// different colors.
+//
+// Bricks come in kinds (BrickKind): normal bricks are cleared by one hit,
+// multi-hit bricks hold a number of hit points, indestructible bricks are
+// never cleared (and do not count for clearing a level) and explosive bricks
+// also clear their neighbours when they are cleared. The layout of bricks is calculated dynamically based
 // on the game area's dimensions and the number of bricks per row.
 package breakout
 
+// BrickKind is the kind of a brick, it defines how the brick reacts to hits
+type BrickKind int
+
+const (
+	BrickNormal         BrickKind = iota // cleared by one hit
+	BrickMultiHit                        // cleared when its hit points are used up
+	BrickIndestructible                  // never cleared
+	BrickExplosive                       // cleared by one hit, clears its neighbours
+	numBrickKinds
+)
+
+// String returns the name of the brick kind as used in BrickState
+func (k BrickKind) String() string {
+	switch k {
+	case BrickMultiHit:
+		return "multi"
+	case BrickIndestructible:
+		return "indestructible"
+	case BrickExplosive:
+		return "explosive"
+	default:
+		return "normal"
+	}
+}
+
 type Brick struct {
-	row, col      int  // row and column of the brick
-	cleared       bool // cleared is true if the brick has been hit
-	x, y          int  // x and y coordinates of the brick
-	width, height int  // width and height of the brick
+	row, col      int       // row and column of the brick
+	cleared       bool      // cleared is true if the brick has been hit
+	x, y          int       // x and y coordinates of the brick
+	width, height int       // width and height of the brick
+	kind          BrickKind // kind of the brick
+	hits          int       // hits left before the brick is cleared
+	color         string    // color of the brick, empty for the color of its row
 }
 
 type BrickState struct {
+	Row, Col      int    // row and column of the brick in the brick grid
 	X, Y          int    // coordinates of the brick
 	Width, Height int    // dimensions of the brick
 	Color         string // color of the brick
+	Kind          string // kind of the brick, see BrickKind
+	Hits          int    // hits left before the brick is cleared, 0 for indestructible bricks
 }
 
-// NewBrick creates a new Brick with the given row and column
-func NewBrick(row, col int) *Brick {
-	br := &Brick{row: row, col: col}
-	br.width = br.CalcWidth()
-	br.height = BRICK_HEIGHT
-	br.y = TOP_OFFSET + (BRICK_ROWS-row-1)*BRICK_HEIGHT
-	br.x = col * (AREA_WIDTH / BRICKS_PER_ROW)
+// NewBrick creates a new Brick with the given row and column of the brick
+// grid described by cfg
+func NewBrick(cfg *Config, row, col int) *Brick {
+	br := &Brick{row: row, col: col, hits: 1}
+	br.width = br.CalcWidth(cfg)
+	br.height = cfg.BrickHeight
+	br.y = cfg.TopOffset + (cfg.BrickRows-row-1)*cfg.BrickHeight
+	br.x = col * (cfg.AreaWidth / cfg.BricksPerRow)
 	if col > 0 {
-		realWidth := float64(AREA_WIDTH) / float64(BRICKS_PER_ROW)
+		realWidth := float64(cfg.AreaWidth) / float64(cfg.BricksPerRow)
 		intWidth := int(realWidth)
-		rest := AREA_WIDTH - intWidth*BRICKS_PER_ROW
+		rest := cfg.AreaWidth - intWidth*cfg.BricksPerRow
 		br.x += rest / 2
 	}
 	return br
@@ -86,6 +126,43 @@ func (b *Brick) SetCleared(cleared bool) {
 	b.cleared = cleared
 }
 
+// GetKind returns the kind of the Brick
+func (b *Brick) GetKind() BrickKind {
+	return b.kind
+}
+
+// SetKind sets the kind of the Brick. Multi-hit bricks take the given
+// number of hits (at least 2), all other kinds ignore hits.
+func (b *Brick) SetKind(kind BrickKind, hits int) {
+	b.kind = kind
+	switch kind {
+	case BrickMultiHit:
+		b.hits = max(hits, 2)
+	case BrickIndestructible:
+		b.hits = 0
+	default:
+		b.hits = 1
+	}
+}
+
+// GetHits returns the number of hits left before the Brick is cleared
+func (b *Brick) GetHits() int {
+	return b.hits
+}
+
+// Hit applies a ball hit to the Brick and returns true if the hit cleared it
+func (b *Brick) Hit() bool {
+	if b.cleared || b.kind == BrickIndestructible {
+		return false
+	}
+	b.hits--
+	if b.hits <= 0 {
+		b.hits = 0
+		b.cleared = true
+	}
+	return b.cleared
+}
+
 // GetX returns the x coordinate of the Brick
 func (b *Brick) GetX() int {
 	return b.x
@@ -96,16 +173,17 @@ func (b *Brick) GetY() int {
 	return b.y
 }
 
-// GetWidth returns the width of the Brick
-func (b *Brick) CalcWidth() int {
-	realWidth := float64(AREA_WIDTH) / float64(BRICKS_PER_ROW)
+// CalcWidth calculates the width of the Brick in the brick grid described
+// by cfg, the outer bricks take up the rest of the area width
+func (b *Brick) CalcWidth(cfg *Config) int {
+	realWidth := float64(cfg.AreaWidth) / float64(cfg.BricksPerRow)
 	intWidth := int(realWidth)
-	rest := AREA_WIDTH - intWidth*BRICKS_PER_ROW
+	rest := cfg.AreaWidth - intWidth*cfg.BricksPerRow
 	if b.col == 0 {
 		return intWidth + rest/2
 	}
-	if b.col == BRICKS_PER_ROW-1 {
-		return AREA_WIDTH - (BRICKS_PER_ROW-1)*intWidth - rest/2
+	if b.col == cfg.BricksPerRow-1 {
+		return cfg.AreaWidth - (cfg.BricksPerRow-1)*intWidth - rest/2
 	}
 	return intWidth
 }
@@ -120,8 +198,20 @@ func (b *Brick) GetHeight() int {
 	return b.height
 }
 
+// SetColor sets the color of the Brick, an empty color stands for the
+// color of its row
+func (b *Brick) SetColor(color string) {
+	b.color = color
+}
+
 // GetColor returns the color of the Brick
 func (b *Brick) GetColor() string {
+	if b.kind == BrickIndestructible {
+		return "gray"
+	}
+	if b.color != "" {
+		return b.color
+	}
 	switch b.row {
 	case 0, 1:
 		return "yellow"
@@ -138,14 +228,17 @@ func (b *Brick) GetColor() string {
 
 // GetPoints returns the points of the Brick
 func (b *Brick) GetPoints() int {
-	switch b.row {
-	case 0, 1:
+	if b.kind == BrickIndestructible {
+		return 0
+	}
+	switch b.GetColor() {
+	case "yellow":
 		return 1
-	case 2, 3:
+	case "green":
 		return 3
-	case 4, 5:
+	case "orange":
 		return 5
-	case 6, 7:
+	case "red":
 		return 7
 	default: // should never happen
 		return 7
@@ -155,10 +248,14 @@ func (b *Brick) GetPoints() int {
 // GetState of the Brick
 func (b *Brick) GetState() BrickState {
 	return BrickState{
+		Row:    b.row,
+		Col:    b.col,
 		X:      b.x,
 		Y:      b.y,
 		Width:  b.width,
 		Height: b.height,
 		Color:  b.GetColor(),
+		Kind:   b.kind.String(),
+		Hits:   b.hits,
 	}
 }
@@ -3,7 +3,7 @@ package breakout
 import "testing"
 
 func TestNewBrick(t *testing.T) {
-	brick := NewBrick(2, 3)
+	brick := NewBrick(&testConfig, 2, 3)
 	if brick.GetRow() != 2 {
 		t.Errorf("expected row to be 2, got %d", brick.GetRow())
 	}
@@ -13,7 +13,7 @@ func TestNewBrick(t *testing.T) {
 }
 
 func TestBrickSetRow(t *testing.T) {
-	brick := NewBrick(0, 0)
+	brick := NewBrick(&testConfig, 0, 0)
 	brick.SetRow(5)
 	if brick.GetRow() != 5 {
 		t.Errorf("expected row to be 5, got %d", brick.GetRow())
@@ -21,7 +21,7 @@ func TestBrickSetRow(t *testing.T) {
 }
 
 func TestBrickSetCol(t *testing.T) {
-	brick := NewBrick(0, 0)
+	brick := NewBrick(&testConfig, 0, 0)
 	brick.SetCol(7)
 	if brick.GetCol() != 7 {
 		t.Errorf("expected col to be 7, got %d", brick.GetCol())
@@ -29,9 +29,9 @@ func TestBrickSetCol(t *testing.T) {
 }
 
 func TestBrickGetWidth(t *testing.T) {
-	firstBrick := NewBrick(0, 0)
-	lastBrick := NewBrick(0, BRICKS_PER_ROW-1)
-	middleBrick := NewBrick(0, 1)
+	firstBrick := NewBrick(&testConfig, 0, 0)
+	lastBrick := NewBrick(&testConfig, 0, BRICKS_PER_ROW-1)
+	middleBrick := NewBrick(&testConfig, 0, 1)
 
 	firstWidth := firstBrick.GetWidth()
 	lastWidth := lastBrick.GetWidth()
@@ -53,7 +53,7 @@ func TestBrickGetWidth(t *testing.T) {
 }
 
 func TestBrickGetHeight(t *testing.T) {
-	brick := NewBrick(0, 0)
+	brick := NewBrick(&testConfig, 0, 0)
 	height := brick.GetHeight()
 	expectedHeight := 7
 
@@ -62,7 +62,7 @@ func TestBrickGetHeight(t *testing.T) {
 	}
 }
 func TestBrickGetColor(t *testing.T) {
-	brick := NewBrick(0, 0)
+	brick := NewBrick(&testConfig, 0, 0)
 	color := brick.GetColor()
 	expectedColor := "yellow"
 
@@ -103,7 +103,7 @@ func TestBrickGetColor(t *testing.T) {
 }
 
 func TestBrickGetPoints(t *testing.T) {
-	brick := NewBrick(0, 1)
+	brick := NewBrick(&testConfig, 0, 1)
 	points := brick.GetPoints()
 	expectedPoints := 1
 
@@ -135,7 +135,7 @@ func TestBrickGetPoints(t *testing.T) {
 	}
 }
 func TestBrickGetPointsWithInvalidRow(t *testing.T) {
-	brick := NewBrick(0, 1)
+	brick := NewBrick(&testConfig, 0, 1)
 	brick.SetRow(8) // Invalid row
 	points := brick.GetPoints()
 	expectedPoints := 7 // Default to last row points
@@ -145,7 +145,7 @@ func TestBrickGetPointsWithInvalidRow(t *testing.T) {
 	}
 }
 func TestBrickIsCleared(t *testing.T) {
-	brick := NewBrick(0, 0)
+	brick := NewBrick(&testConfig, 0, 0)
 	if brick.IsCleared() {
 		t.Errorf("expected brick to not be cleared, but it is")
 	}
@@ -157,7 +157,7 @@ func TestBrickIsCleared(t *testing.T) {
 }
 
 func TestBrickGetState(t *testing.T) {
-	brick := NewBrick(2, 3)
+	brick := NewBrick(&testConfig, 2, 3)
 	state := brick.GetState()
 
 	if state.X != brick.GetX() {
@@ -178,7 +178,7 @@ func TestBrickGetState(t *testing.T) {
 }
 
 func TestBrickSetCleared(t *testing.T) {
-	brick := NewBrick(0, 0)
+	brick := NewBrick(&testConfig, 0, 0)
 	brick.SetCleared(true)
 	if !brick.IsCleared() {
 		t.Errorf("expected brick to be cleared, but it is not")
@@ -189,3 +189,54 @@ func TestBrickSetCleared(t *testing.T) {
 		t.Errorf("expected brick to not be cleared, but it is")
 	}
 }
+
+func TestBrickKindDefaults(t *testing.T) {
+	brick := NewBrick(&testConfig, 0, 0)
+	if brick.GetKind() != BrickNormal || brick.GetHits() != 1 {
+		t.Errorf("expected normal brick with 1 hit, got %s with %d hits", brick.GetKind(), brick.GetHits())
+	}
+	if !brick.Hit() || !brick.IsCleared() {
+		t.Error("expected normal brick to be cleared by one hit")
+	}
+	if brick.Hit() {
+		t.Error("expected cleared brick not to be cleared again")
+	}
+}
+
+func TestBrickMultiHit(t *testing.T) {
+	brick := NewBrick(&testConfig, 0, 0)
+	brick.SetKind(BrickMultiHit, 3)
+	for i := 2; i >= 1; i-- {
+		if brick.Hit() {
+			t.Fatalf("expected multi-hit brick to survive, %d hits left", i)
+		}
+		if brick.GetHits() != i {
+			t.Errorf("expected %d hits left, got %d", i, brick.GetHits())
+		}
+	}
+	if !brick.Hit() || !brick.IsCleared() {
+		t.Error("expected multi-hit brick to be cleared by the last hit")
+	}
+
+	brick.SetKind(BrickMultiHit, 1)
+	if brick.GetHits() != 2 {
+		t.Errorf("expected multi-hit brick to take at least 2 hits, got %d", brick.GetHits())
+	}
+}
+
+func TestBrickIndestructible(t *testing.T) {
+	brick := NewBrick(&testConfig, 6, 0)
+	brick.SetKind(BrickIndestructible, 0)
+	for i := 0; i < 10; i++ {
+		if brick.Hit() {
+			t.Fatal("expected indestructible brick never to be cleared")
+		}
+	}
+	state := brick.GetState()
+	if state.Kind != "indestructible" || state.Hits != 0 || state.Color != "gray" {
+		t.Errorf("unexpected indestructible brick state %+v", state)
+	}
+	if brick.GetPoints() != 0 {
+		t.Errorf("expected no points for an indestructible brick, got %d", brick.GetPoints())
+	}
+}
@@ -0,0 +1,240 @@
+// Package breakout provides the continuous (swept) collision detection used
+// by MoveBall.
+//
+// Instead of testing where the ball ends up after a frame, the ball is swept
+// along its motion segment. The earliest contact with a wall, a brick or the
+// paddle is found, the ball is moved to the contact point, the bounce is
+// resolved on the face (or corner) that was hit, and the leftover motion is
+// continued within the same frame. A fast ball can therefore neither tunnel
+// through a brick nor bounce off two faces at once.
+//
+// A brick or the paddle is treated as a rectangle grown by the ball radius
+// with rounded corners (the Minkowski sum of the rectangle and the ball), so
+// the ball center can be traced as a ray.
+//
+// Types:
+// - contact: The earliest contact along the motion of the ball.
+//
+// Functions:
+// - sweepRect: Finds where a moving circle first touches a rectangle.
+// - (*Breakout) sweepBall: Moves the ball for one frame resolving all contacts.
+// - (*Breakout) earliestContact: Finds the first contact within the remaining motion.
+package breakout
+
+import "math"
+
+// maxContacts limits the number of bounces resolved within one frame
+const maxContacts = 8
+
+// epsilon absorbs float rounding when a contact lies exactly on the start
+const epsilon = 1e-9
+
+type contact struct {
+	t       float64 // time of the contact, in frames from the current position
+	nx, ny  float64 // surface normal at the contact, pointing towards the ball
+	brick   *Brick  // brick that was hit, nil for walls and the paddle
+	paddle  bool    // true if the paddle was hit
+	ceiling bool    // true if the top wall was hit
+}
+
+// sweepRect returns the time in [0, tmax] at which a circle of radius r at
+// (px, py) moving with (vx, vy) per frame first touches the rectangle
+// (x0, y0)-(x1, y1), and the surface normal at that point. Only contacts
+// where the circle moves into the surface are reported.
+func sweepRect(px, py, vx, vy, r, x0, y0, x1, y1, tmax float64) (float64, float64, float64, bool) {
+	// slab test against the rectangle grown by the radius
+	tEnter, tExit := math.Inf(-1), math.Inf(1)
+	var nx, ny float64
+	if vx == 0 {
+		if px <= x0-r || px >= x1+r {
+			return 0, 0, 0, false
+		}
+	} else {
+		t1, t2 := (x0-r-px)/vx, (x1+r-px)/vx
+		n := -1.0 // moving right enters through the left side
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			n = 1
+		}
+		if t1 > tEnter {
+			tEnter, nx, ny = t1, n, 0
+		}
+		tExit = min(tExit, t2)
+	}
+	if vy == 0 {
+		if py <= y0-r || py >= y1+r {
+			return 0, 0, 0, false
+		}
+	} else {
+		t1, t2 := (y0-r-py)/vy, (y1+r-py)/vy
+		n := -1.0 // moving down enters through the top side
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			n = 1
+		}
+		if t1 > tEnter {
+			tEnter, nx, ny = t1, 0, n
+		}
+		tExit = min(tExit, t2)
+	}
+	if tEnter > tExit || tEnter < -epsilon || tEnter > tmax {
+		return 0, 0, 0, false
+	}
+	tEnter = max(tEnter, 0)
+	hx, hy := px+vx*tEnter, py+vy*tEnter
+	if (hx >= x0 && hx <= x1) || (hy >= y0 && hy <= y1) {
+		// hit a face
+		if vx*nx+vy*ny >= 0 {
+			return 0, 0, 0, false
+		}
+		return tEnter, nx, ny, true
+	}
+	// the grown rectangle was entered next to a corner, the real contact
+	// is with the circle of radius r around that corner
+	cx, cy := x0, y0
+	if hx > x1 {
+		cx = x1
+	}
+	if hy > y1 {
+		cy = y1
+	}
+	dx, dy := px-cx, py-cy
+	a := vx*vx + vy*vy
+	bq := dx*vx + dy*vy
+	c := dx*dx + dy*dy - r*r
+	disc := bq*bq - a*c
+	if disc < 0 {
+		return 0, 0, 0, false // passes by the corner
+	}
+	t := (-bq - math.Sqrt(disc)) / a
+	if t < -epsilon || t > tmax {
+		return 0, 0, 0, false
+	}
+	t = max(t, 0)
+	nx, ny = (px+vx*t-cx)/r, (py+vy*t-cy)/r
+	if vx*nx+vy*ny >= 0 {
+		return 0, 0, 0, false
+	}
+	return t, nx, ny, true
+}
+
+// earliestContact returns the first contact of the ball with a wall, a brick
+// or the paddle within tmax frames.
+func (b *Breakout) earliestContact(bl *Ball, tmax float64) (contact, bool) {
+	best := contact{t: math.Inf(1)}
+	found := false
+	consider := func(c contact) {
+		if c.t < best.t {
+			best = c
+			found = true
+		}
+	}
+	r := float64(bl.radius)
+	w := float64(b.cfg.AreaWidth)
+
+	// walls, a ball that is already past a wall bounces right away
+	if bl.v_x < 0 {
+		consider(contact{t: max((r-bl.x)/bl.v_x, 0), nx: 1})
+	}
+	if bl.v_x > 0 {
+		consider(contact{t: max((w-r-bl.x)/bl.v_x, 0), nx: -1})
+	}
+	if bl.v_y < 0 {
+		consider(contact{t: max((r-bl.y)/bl.v_y, 0), ny: 1, ceiling: true})
+	}
+
+	for i := range b.bricks {
+		for j := range b.bricks[i] {
+			br := b.bricks[i][j]
+			if br == nil || br.IsCleared() {
+				continue
+			}
+			x0, y0 := float64(br.GetX()), float64(br.GetY())
+			x1, y1 := x0+float64(br.GetWidth()), y0+float64(br.GetHeight())
+			if t, nx, ny, ok := sweepRect(bl.x, bl.y, bl.v_x, bl.v_y, r, x0, y0, x1, y1, tmax); ok {
+				consider(contact{t: t, nx: nx, ny: ny, brick: br})
+			}
+		}
+	}
+
+	pa := b.paddle
+	x0, y0 := float64(pa.GetX()), float64(b.cfg.AreaHeight-pa.GetHeight())
+	x1, y1 := x0+float64(pa.GetWidth()), float64(b.cfg.AreaHeight)
+	if t, nx, ny, ok := sweepRect(bl.x, bl.y, bl.v_x, bl.v_y, r, x0, y0, x1, y1, tmax); ok {
+		consider(contact{t: t, nx: nx, ny: ny, paddle: true})
+	} else if bl.v_y > 0 && bl.x+r >= x0 && bl.x-r <= x1 && bl.y+r >= y0 && bl.y-r <= y1 {
+		// the paddle moved onto a falling ball, bounce right away
+		consider(contact{t: 0, ny: -1, paddle: true})
+	}
+
+	if !found || best.t > tmax {
+		return best, false
+	}
+	return best, true
+}
+
+// sweepBall moves the ball along its velocity for one frame, resolving every
+// contact on the way. Hit bricks are cleared and scored (see hitBrick). It returns true if
+// the ball bounced off the paddle. After maxContacts contacts the ball stays
+// at the last one and the rest of the motion of the frame is dropped, it is
+// not swept anymore.
+func (b *Breakout) sweepBall(bl *Ball) bool {
+	hitPaddle := false
+	remaining := 1.0
+	for range maxContacts {
+		c, ok := b.earliestContact(bl, remaining)
+		if !ok {
+			bl.x += bl.v_x * remaining
+			bl.y += bl.v_y * remaining
+			return hitPaddle
+		}
+		bl.x += bl.v_x * c.t
+		bl.y += bl.v_y * c.t
+		remaining -= c.t
+		if c.paddle {
+			hitPaddle = true
+			b.bouncePaddle(bl)
+		} else {
+			if c.brick != nil {
+				b.hitBrick(c.brick)
+			} else {
+				b.emit(WallBounce{Side: wallSide(c)})
+			}
+			// reflect the velocity on the surface normal
+			dot := bl.v_x*c.nx + bl.v_y*c.ny
+			bl.v_x -= 2 * dot * c.nx
+			bl.v_y -= 2 * dot * c.ny
+		}
+		if b.cfg.ArcadeRules {
+			b.arcadeContact(c)
+		}
+		if c.paddle && b.holdBall(bl) {
+			// the sticky paddle caught the ball, it stays there
+			return hitPaddle
+		}
+	}
+	return hitPaddle
+}
+
+// bouncePaddle sends the ball back up with an angle that depends on where
+// it hit the paddle, the further from the center the flatter the angle.
+func (b *Breakout) bouncePaddle(bl *Ball) {
+	pa := b.paddle
+	xpaddle := float64(pa.GetX()) + float64(pa.GetWidth())/2
+	h := (bl.x - xpaddle) / (float64(pa.GetWidth()) / 2)
+	h = max(-1, min(1, h))
+	bl.SetDir(270 + h*60 + 1) // plus one to avoid 0 degree
+	b.emit(PaddleHit{Offset: h, Angle: bl.GetDir()})
+}
+
+// wallSide returns the side of the wall of a contact
+func wallSide(c contact) string {
+	switch {
+	case c.ceiling:
+		return WALL_TOP
+	case c.nx > 0:
+		return WALL_LEFT
+	default:
+		return WALL_RIGHT
+	}
+}
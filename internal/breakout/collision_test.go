@@ -0,0 +1,166 @@
+package breakout
+
+import (
+	"math"
+	"testing"
+)
+
+// clearBricks removes all bricks except the one at row, col
+func clearBricksExcept(b *Breakout, row, col int) *Brick {
+	for i := range b.bricks {
+		for j := range b.bricks[i] {
+			if i != row || j != col {
+				b.bricks[i][j].SetCleared(true)
+			}
+		}
+	}
+	return b.bricks[row][col]
+}
+
+func TestSweepRectFaces(t *testing.T) {
+	// rectangle (10,10)-(20,20), ball radius 2
+	cases := []struct {
+		name           string
+		px, py, vx, vy float64
+		t, nx, ny      float64
+	}{
+		{"left", 0, 15, 10, 0, 0.8, -1, 0},
+		{"right", 30, 15, -10, 0, 0.8, 1, 0},
+		{"top", 15, 0, 0, 10, 0.8, 0, -1},
+		{"bottom", 15, 30, 0, -10, 0.8, 0, 1},
+	}
+	for _, c := range cases {
+		tt, nx, ny, ok := sweepRect(c.px, c.py, c.vx, c.vy, 2, 10, 10, 20, 20, 1)
+		if !ok {
+			t.Errorf("%s: expected contact", c.name)
+			continue
+		}
+		if math.Abs(tt-c.t) > 1e-9 || nx != c.nx || ny != c.ny {
+			t.Errorf("%s: expected contact at %f with normal (%f, %f), got %f (%f, %f)", c.name, c.t, c.nx, c.ny, tt, nx, ny)
+		}
+	}
+}
+
+func TestSweepRectCorner(t *testing.T) {
+	// moving diagonally onto the top left corner (10,10)
+	tt, nx, ny, ok := sweepRect(0, 0, 10, 10, 2, 10, 10, 20, 20, 1)
+	if !ok {
+		t.Fatal("Expected contact with the corner")
+	}
+	hx, hy := 10*tt, 10*tt
+	if d := math.Hypot(hx-10, hy-10); math.Abs(d-2) > 1e-9 {
+		t.Errorf("Expected contact at distance 2 from the corner, got %f", d)
+	}
+	if math.Abs(nx-ny) > 1e-9 || nx >= 0 {
+		t.Errorf("Expected diagonal normal pointing up left, got (%f, %f)", nx, ny)
+	}
+
+	// passing by the corner must not be a contact
+	if _, _, _, ok := sweepRect(0, 6.5, 10, -1, 2, 10, 10, 20, 20, 1); ok {
+		t.Error("Expected the ball to pass by the corner")
+	}
+}
+
+func TestSweepRectMovingAway(t *testing.T) {
+	// touching the left face but moving away from it
+	if _, _, _, ok := sweepRect(8, 15, -10, 0, 2, 10, 10, 20, 20, 1); ok {
+		t.Error("Expected no contact when moving away from the face")
+	}
+}
+
+func TestFastBallDoesNotTunnel(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	brick := clearBricksExcept(breakout, 0, 5)
+	ball := breakout.balls[0]
+	// a ball below the brick going straight up, faster than the brick height
+	ball.speed = 3 * BRICK_HEIGHT
+	ball.SetDir(270)
+	ball.x = float64(brick.GetX() + brick.GetWidth()/2)
+	ball.y = float64(brick.GetY() + brick.GetHeight() + ball.radius + 5)
+
+	breakout.MoveBall()
+
+	if !brick.IsCleared() {
+		t.Error("Expected the fast ball to hit the brick")
+	}
+	if ball.v_y <= 0 {
+		t.Errorf("Expected the ball to bounce down, got v_y %f", ball.v_y)
+	}
+	if ball.GetY() <= brick.GetY()+brick.GetHeight() {
+		t.Errorf("Expected the ball to stay below the brick, got y %d", ball.GetY())
+	}
+}
+
+func TestBallBouncesOffCorner(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	brick := clearBricksExcept(breakout, 0, 5)
+	ball := breakout.balls[0]
+	// aim exactly at the bottom left corner from below left
+	ball.SetDir(315)
+	d := float64(ball.radius)/math.Sqrt2 + ball.v_x/2
+	ball.x = float64(brick.GetX()) - d
+	ball.y = float64(brick.GetY()+brick.GetHeight()) + d
+
+	breakout.MoveBall()
+
+	if !brick.IsCleared() {
+		t.Fatal("Expected the ball to hit the corner")
+	}
+	if ball.v_x >= 0 || ball.v_y <= 0 {
+		t.Errorf("Expected the ball to bounce back down left, got (%f, %f)", ball.v_x, ball.v_y)
+	}
+}
+
+func TestBallContinuesAfterBounce(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := breakout.balls[0]
+	ball.SetDir(180)
+	ball.x = float64(ball.radius) + 1
+	ball.y = AREA_HEIGHT / 2
+
+	breakout.MoveBall()
+
+	// one unit to the wall, the leftover motion goes back to the right
+	expected := float64(ball.radius) + ball.speed - 1
+	if math.Abs(ball.x-expected) > 1e-9 {
+		t.Errorf("Expected ball at x %f after bouncing off the wall, got %f", expected, ball.x)
+	}
+}
+
+func TestBallStopsAfterMaxContacts(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := breakout.balls[0]
+	// fast enough to bounce between the side walls more than maxContacts times
+	ball.speed = 2 * maxContacts * AREA_WIDTH
+	ball.SetDir(180)
+	ball.x = AREA_WIDTH / 2
+	ball.y = AREA_HEIGHT / 2
+
+	breakout.MoveBall()
+
+	r := float64(ball.radius)
+	if ball.x < r-1e-9 || ball.x > AREA_WIDTH-r+1e-9 {
+		t.Errorf("Expected the ball to stay between the walls, got x %f", ball.x)
+	}
+}
+
+func TestPaddleBounceWithinFrame(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	ball := breakout.balls[0]
+	paddle := breakout.paddle
+	ball.SetDir(90)
+	ball.x = float64(paddle.x + paddle.width/2)
+	ball.y = float64(AREA_HEIGHT-paddle.height-ball.radius) - 1
+
+	breakout.MoveBall()
+
+	if breakout.frameReward != 10 {
+		t.Errorf("Expected paddle hit reward 10, got %d", breakout.frameReward)
+	}
+	if ball.v_y >= 0 {
+		t.Errorf("Expected the ball to go up after the paddle hit, got v_y %f", ball.v_y)
+	}
+	if breakout.live != 1 {
+		t.Errorf("Expected no life lost, got live %d", breakout.live)
+	}
+}
@@ -0,0 +1,206 @@
+// Package breakout provides the Config struct that describes the geometry of
+// the playfield and the sizes and speeds of the paddle and the ball.
+//
+// The package level constants (AREA_WIDTH, AREA_HEIGHT, BRICKS_PER_ROW,
+// BRICK_ROWS, TOP_OFFSET, BRICK_HEIGHT) are the defaults returned by
+// DefaultConfig. A Config is passed to NewBreakout, which hands it to the
+// ball, the paddle and the bricks, so narrow, tall or dense-brick variants
+// can be played from the same binary.
+//
+// Types:
+//   - Config: The playfield dimensions plus paddle and ball parameters, the
+//     ruleset, the level layouts and the power-ups.
+//
+// Functions:
+// - DefaultConfig: Returns the classic configuration.
+// - (Config) WithDefaults: Fills zero fields with the classic values.
+// - (*Config) UnmarshalJSON: Decodes JSON onto the classic configuration.
+// - (Config) Validate: Reports configurations the engine cannot play.
+// - ReadConfig: Reads a configuration from a JSON file.
+// - LoadConfig: Reads the configuration and level files of a command.
+package breakout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Default paddle and ball parameters
+const (
+	PADDLE_WIDTH  = 24
+	PADDLE_HEIGHT = 4
+	PADDLE_STEP   = 3
+	BALL_RADIUS   = 2
+	BALL_SPEED    = 3
+)
+
+type Config struct {
+	AreaWidth     int       // width of the game area
+	AreaHeight    int       // height of the game area
+	BricksPerRow  int       // number of bricks in each row
+	BrickRows     int       // number of rows of bricks
+	TopOffset     int       // vertical offset of the bricks from the top of the game area
+	BrickHeight   int       // height of each brick
+	PaddleWidth   int       // paddle width
+	PaddleHeight  int       // paddle height
+	PaddleStep    int       // distance the paddle moves on every left or right input
+	BallRadius    int       // ball radius
+	BallSpeed     float64   // distance the ball moves on every frame
+	ArcadeRules   bool      // play with the original arcade speed-ups and paddle shrink, see arcade.go
+	Levels        *LevelSet // level layouts, nil for the full brick grid on every level, see level.go
+	PowerUps      bool      // cleared bricks drop power-up capsules, see powerup.go
+	PowerUpChance float64   // chance that a cleared brick drops a capsule
+}
+
+// DefaultConfig returns the classic configuration built from the package
+// level constants.
+func DefaultConfig() Config {
+	return Config{
+		AreaWidth:    AREA_WIDTH,
+		AreaHeight:   AREA_HEIGHT,
+		BricksPerRow: BRICKS_PER_ROW,
+		BrickRows:    BRICK_ROWS,
+		TopOffset:    TOP_OFFSET,
+		BrickHeight:  BRICK_HEIGHT,
+		PaddleWidth:  PADDLE_WIDTH,
+		PaddleHeight: PADDLE_HEIGHT,
+		PaddleStep:   PADDLE_STEP,
+		BallRadius:   BALL_RADIUS,
+		BallSpeed:    BALL_SPEED,
+
+		PowerUpChance: POWERUP_CHANCE,
+	}
+}
+
+// WithDefaults returns a copy of the config where every zero field that
+// must be positive is replaced by its default value, so a partial config
+// only has to name the sizes it changes. TopOffset and PowerUpChance are
+// valid at zero and kept, start from DefaultConfig to get their defaults.
+func (c Config) WithDefaults() Config {
+	d := DefaultConfig()
+	if c.AreaWidth == 0 {
+		c.AreaWidth = d.AreaWidth
+	}
+	if c.AreaHeight == 0 {
+		c.AreaHeight = d.AreaHeight
+	}
+	if c.BricksPerRow == 0 {
+		c.BricksPerRow = d.BricksPerRow
+	}
+	if c.BrickRows == 0 {
+		c.BrickRows = d.BrickRows
+	}
+	if c.BrickHeight == 0 {
+		c.BrickHeight = d.BrickHeight
+	}
+	if c.PaddleWidth == 0 {
+		c.PaddleWidth = d.PaddleWidth
+	}
+	if c.PaddleHeight == 0 {
+		c.PaddleHeight = d.PaddleHeight
+	}
+	if c.PaddleStep == 0 {
+		c.PaddleStep = d.PaddleStep
+	}
+	if c.BallRadius == 0 {
+		c.BallRadius = d.BallRadius
+	}
+	if c.BallSpeed == 0 {
+		c.BallSpeed = d.BallSpeed
+	}
+	return c
+}
+
+// Validate returns an error if the config describes a playfield the engine
+// cannot play, e.g. bricks that do not fit into the game area.
+func (c Config) Validate() error {
+	if c.AreaWidth <= 0 || c.AreaHeight <= 0 {
+		return fmt.Errorf("invalid game area %dx%d", c.AreaWidth, c.AreaHeight)
+	}
+	if c.BricksPerRow <= 0 || c.BrickRows <= 0 || c.BrickHeight <= 0 {
+		return fmt.Errorf("invalid brick grid %dx%d with brick height %d", c.BrickRows, c.BricksPerRow, c.BrickHeight)
+	}
+	if c.BricksPerRow > c.AreaWidth {
+		return fmt.Errorf("%d bricks per row do not fit into width %d", c.BricksPerRow, c.AreaWidth)
+	}
+	if c.TopOffset < 0 {
+		return fmt.Errorf("invalid top offset %d", c.TopOffset)
+	}
+	if c.PaddleWidth <= 0 || c.PaddleWidth > c.AreaWidth || c.PaddleHeight <= 0 || c.PaddleStep <= 0 {
+		return fmt.Errorf("invalid paddle %dx%d with step %d", c.PaddleWidth, c.PaddleHeight, c.PaddleStep)
+	}
+	if c.BallRadius <= 0 || c.BallSpeed <= 0 {
+		return fmt.Errorf("invalid ball radius %d or speed %f", c.BallRadius, c.BallSpeed)
+	}
+	// the ball is served from the middle of the area, it must start below the
+	// bricks and above the paddle
+	bricksBottom := c.TopOffset + c.BrickRows*c.BrickHeight
+	if bricksBottom+c.BallRadius >= c.AreaHeight/2 {
+		return fmt.Errorf("bricks reach down to %d, below the serve line %d", bricksBottom, c.AreaHeight/2)
+	}
+	if c.PowerUpChance < 0 || c.PowerUpChance > 1 {
+		return fmt.Errorf("invalid power-up chance %f", c.PowerUpChance)
+	}
+	if c.Levels != nil {
+		if err := c.Levels.Validate(c); err != nil {
+			return fmt.Errorf("invalid levels: %w", err)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the JSON object onto DefaultConfig, so fields
+// missing from it keep their default values and fields set to zero stay
+// zero
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config // without this method
+	p := plain(DefaultConfig())
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*c = Config(p)
+	return nil
+}
+
+// ReadConfig reads a configuration from a JSON file. Fields missing from the
+// file keep their default values.
+func ReadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	cfg = cfg.WithDefaults()
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadConfig returns the game configuration of the config and level files
+// given to a command, the default configuration without a config file and
+// the full brick grid without a level file
+func LoadConfig(configFile, levelsFile string) (Config, error) {
+	cfg := DefaultConfig()
+	if configFile != "" {
+		var err error
+		if cfg, err = ReadConfig(configFile); err != nil {
+			return cfg, fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+	if levelsFile != "" {
+		levels, err := ReadLevels(levelsFile)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read levels: %w", err)
+		}
+		cfg.Levels = levels
+		if err := cfg.Validate(); err != nil {
+			return cfg, fmt.Errorf("levels do not fit the game: %w", err)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,147 @@
+package breakout
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testConfig is the default configuration shared by the element tests
+var testConfig = DefaultConfig()
+
+func TestDefaultConfigIsValid(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Expected default config to be valid, got %v", err)
+	}
+}
+
+func TestConfigWithDefaults(t *testing.T) {
+	cfg := Config{BrickRows: 4, AreaWidth: 120}.WithDefaults()
+
+	if cfg.BrickRows != 4 || cfg.AreaWidth != 120 {
+		t.Errorf("Expected set fields to be kept, got %d rows and width %d", cfg.BrickRows, cfg.AreaWidth)
+	}
+	if cfg.AreaHeight != AREA_HEIGHT || cfg.PaddleWidth != PADDLE_WIDTH || cfg.BallSpeed != BALL_SPEED {
+		t.Error("Expected zero fields to be replaced by defaults")
+	}
+	if cfg.TopOffset != 0 || cfg.PowerUpChance != 0 {
+		t.Errorf("Expected the valid zero top offset and power-up chance to be kept, got %+v", cfg)
+	}
+}
+
+func TestConfigUnmarshalJSON(t *testing.T) {
+	var cfg Config
+	if err := json.Unmarshal([]byte(`{"TopOffset": 0, "BrickRows": 4}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TopOffset != 0 || cfg.BrickRows != 4 {
+		t.Errorf("Expected the fields of the JSON, got top offset %d and %d rows", cfg.TopOffset, cfg.BrickRows)
+	}
+	if cfg.PowerUpChance != POWERUP_CHANCE || cfg.AreaWidth != AREA_WIDTH {
+		t.Errorf("Expected defaults for the missing fields, got %+v", cfg)
+	}
+	if err := cfg.WithDefaults().Validate(); err != nil {
+		t.Errorf("Expected the zero top offset to be valid, got %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cases := map[string]Config{
+		"negative width":     {AreaWidth: -1},
+		"too many bricks":    {AreaWidth: 10, BricksPerRow: 20, PaddleWidth: 8},
+		"wide paddle":        {PaddleWidth: AREA_WIDTH + 1},
+		"bricks below serve": {BrickRows: 20},
+	}
+	for name, cfg := range cases {
+		if err := cfg.WithDefaults().Validate(); err == nil {
+			t.Errorf("%s: expected config to be invalid", name)
+		}
+	}
+}
+
+func TestCustomConfigGame(t *testing.T) {
+	cfg := Config{AreaWidth: 100, AreaHeight: 300, BricksPerRow: 10, BrickRows: 4, PaddleWidth: 30}
+	game := NewBreakout(cfg, 1)
+	state := game.GetState()
+
+	if state.Width != 100 || state.Height != 300 {
+		t.Errorf("Expected state dimensions 100x300, got %dx%d", state.Width, state.Height)
+	}
+	if len(state.Bricks) != 40 {
+		t.Errorf("Expected 40 bricks, got %d", len(state.Bricks))
+	}
+	if state.PaddleWidth != 30 || state.PaddleX != 35 {
+		t.Errorf("Expected centered paddle of width 30, got x %d width %d", state.PaddleX, state.PaddleWidth)
+	}
+	// the bricks must cover the whole width
+	right := 0
+	for _, br := range state.Bricks {
+		right = max(right, br.X+br.Width)
+	}
+	if right != 100 {
+		t.Errorf("Expected bricks to end at the right edge, got %d", right)
+	}
+	for range 100 {
+		game.PaddleRight()
+	}
+	if game.GetState().PaddleX != 70 {
+		t.Errorf("Expected paddle to stop at the right edge, got %d", game.GetState().PaddleX)
+	}
+	for range 1000 {
+		game.MoveBall()
+		st := game.GetState()
+		if st.BallX < 0 || st.BallX > 100 || st.BallY < 0 || st.BallY > 300 {
+			t.Fatalf("Expected ball to stay in the configured area, got (%d, %d)", st.BallX, st.BallY)
+		}
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"AreaWidth": 120, "BricksPerRow": 12}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("Expected to read the config, got %v", err)
+	}
+	if cfg.AreaWidth != 120 || cfg.BricksPerRow != 12 || cfg.AreaHeight != AREA_HEIGHT {
+		t.Errorf("Expected the set fields and defaults, got %+v", cfg)
+	}
+	if err := os.WriteFile(path, []byte(`{"AreaWidth": -1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfig(path); err == nil {
+		t.Error("Expected an error for an invalid config")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	levelsPath := filepath.Join(dir, "levels.json")
+	if err := os.WriteFile(configPath, []byte(`{"BricksPerRow": 12}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(levelsPath, []byte(testLevels), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig("", "")
+	if err != nil || cfg != DefaultConfig() {
+		t.Errorf("Expected the default config without files, got %+v, %v", cfg, err)
+	}
+	cfg, err = LoadConfig("", levelsPath)
+	if err != nil {
+		t.Fatalf("Expected to load the levels, got %v", err)
+	}
+	if cfg.Levels == nil || len(cfg.Levels.Levels) != 2 {
+		t.Errorf("Expected the two test levels, got %+v", cfg.Levels)
+	}
+	if _, err := LoadConfig(configPath, levelsPath); err == nil {
+		t.Error("Expected an error for levels wider than the configured grid")
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json"), ""); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
@@ -0,0 +1,129 @@
+// Package breakout provides the events of the game, so callers learn what
+// happened in a frame without diffing game states.
+//
+// Every frame played by MoveBall (or Step) produces a list of typed events
+// in the order they happened. Step returns them, Events returns those of
+// the last frame and registered listeners receive them at the end of every
+// frame.
+//
+// Types:
+// - Event: The interface of all events.
+// - BrickHit: A ball, laser shot or explosion hit a brick.
+// - PaddleHit: A ball bounced off the paddle.
+// - WallBounce: A ball bounced off a wall.
+// - LifeLost, LifeGained: The number of lives changed.
+// - LevelCleared: All bricks of the level are cleared.
+// - GameOver: The game ended.
+// - Listener: Receives the events of every frame.
+//
+// Functions:
+// - (*Breakout) Step: Plays one frame and returns its events.
+// - (*Breakout) Events: Returns the events of the last frame.
+// - (*Breakout) AddListener: Registers a listener.
+package breakout
+
+// Sides of WallBounce
+const (
+	WALL_LEFT  = "left"
+	WALL_RIGHT = "right"
+	WALL_TOP   = "top"
+)
+
+type Event interface {
+	// Type returns the name of the event, e.g. "brick_hit"
+	Type() string
+}
+
+type BrickHit struct {
+	Row, Col int    // row and column of the brick in the brick grid
+	Color    string // color of the brick
+	Kind     string // kind of the brick, see BrickKind
+	Cleared  bool   // the hit cleared the brick
+	Points   int    // points scored, including the level multiplier, 0 if not cleared
+}
+
+type PaddleHit struct {
+	Offset float64 // where the ball hit, from -1 (left end) to 1 (right end)
+	Angle  float64 // direction of the bounced ball in degrees
+}
+
+type WallBounce struct {
+	Side string // WALL_LEFT, WALL_RIGHT or WALL_TOP
+}
+
+type LifeLost struct {
+	Lives int // lives left
+}
+
+type LifeGained struct {
+	Lives int // lives left
+}
+
+type LevelCleared struct {
+	Level int // the cleared level
+}
+
+type GameOver struct {
+	Won   bool // the last level was cleared
+	Score int  // final score
+}
+
+func (BrickHit) Type() string     { return "brick_hit" }
+func (PaddleHit) Type() string    { return "paddle_hit" }
+func (WallBounce) Type() string   { return "wall_bounce" }
+func (LifeLost) Type() string     { return "life_lost" }
+func (LifeGained) Type() string   { return "life_gained" }
+func (LevelCleared) Type() string { return "level_cleared" }
+func (GameOver) Type() string     { return "game_over" }
+
+// Listener receives the events of a frame
+type Listener func(events []Event)
+
+// Step plays one frame (see MoveBall) and returns its events
+func (b *Breakout) Step() []Event {
+	b.MoveBall()
+	return b.Events()
+}
+
+// Events returns the events of the last frame
+func (b *Breakout) Events() []Event {
+	return append([]Event(nil), b.events...)
+}
+
+// AddListener registers l to receive the events at the end of every frame
+// that has events, and returns a function that removes it again.
+// Listeners are not copied by Clone, and they are kept by Restore.
+func (b *Breakout) AddListener(l Listener) func() {
+	b.nextListener++
+	id := b.nextListener
+	b.listeners = append(b.listeners, listener{id: id, fn: l})
+	return func() {
+		for i, ln := range b.listeners {
+			if ln.id == id {
+				b.listeners = append(b.listeners[:i:i], b.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// listener is a registered Listener
+type listener struct {
+	id int
+	fn Listener
+}
+
+// emit adds an event to the current frame
+func (b *Breakout) emit(e Event) {
+	b.events = append(b.events, e)
+}
+
+// dispatch sends the events of the frame to the listeners
+func (b *Breakout) dispatch() {
+	if len(b.events) == 0 {
+		return
+	}
+	for _, ln := range b.listeners {
+		ln.fn(b.Events())
+	}
+}
@@ -0,0 +1,147 @@
+package breakout
+
+import "testing"
+
+// countEvents returns how many events of each type are in the list
+func countEvents(events []Event) map[string]int {
+	n := make(map[string]int)
+	for _, e := range events {
+		n[e.Type()]++
+	}
+	return n
+}
+
+// followStep moves the paddle towards the first ball and plays a frame
+func followStep(b *Breakout) []Event {
+	if b.balls[0].GetX() < b.paddle.GetX()+b.paddle.GetWidth()/2 {
+		b.PaddleLeft()
+	} else {
+		b.PaddleRight()
+	}
+	return b.Step()
+}
+
+func TestStepEvents(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 3)
+	total := make(map[string]int)
+	for range 3000 {
+		before := b.GetState()
+		events := followStep(b)
+		points := 0
+		for _, e := range events {
+			total[e.Type()]++
+			if hit, ok := e.(BrickHit); ok {
+				points += hit.Points
+			}
+		}
+		if points != b.GetState().Score-before.Score {
+			t.Fatalf("Expected the brick hits to score %d, got %d", b.GetState().Score-before.Score, points)
+		}
+		if b.GetState().Done {
+			break
+		}
+	}
+	for _, typ := range []string{"brick_hit", "paddle_hit", "wall_bounce"} {
+		if total[typ] == 0 {
+			t.Errorf("Expected %s events, got %v", typ, total)
+		}
+	}
+}
+
+func TestPaddleHitEvent(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	pa := b.paddle
+	// drop the ball onto the right half of the paddle
+	b.balls[0].x = float64(pa.GetX()) + float64(pa.GetWidth())*3/4
+	b.balls[0].y = float64(AREA_HEIGHT - pa.GetHeight() - b.balls[0].radius - 1)
+	b.balls[0].SetDir(90)
+	events := b.Step()
+	var hit PaddleHit
+	found := false
+	for _, e := range events {
+		if h, ok := e.(PaddleHit); ok {
+			hit, found = h, true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected a paddle hit, got %v", events)
+	}
+	if hit.Offset <= 0 || hit.Offset > 1 {
+		t.Errorf("Expected an offset right of the center, got %v", hit.Offset)
+	}
+	if hit.Angle != b.balls[0].GetDir() {
+		t.Errorf("Expected the angle of the ball %v, got %v", b.balls[0].GetDir(), hit.Angle)
+	}
+}
+
+func TestLifeLostAndGameOverEvents(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	for i := range MAX_LIVES {
+		b.balls[0].y = AREA_HEIGHT + 10
+		events := b.Step()
+		lost, ok := events[0].(LifeLost)
+		if !ok || lost.Lives != MAX_LIVES-1-i {
+			t.Fatalf("Expected LifeLost with %d lives, got %v", MAX_LIVES-1-i, events)
+		}
+	}
+	if n := countEvents(b.Events()); n["game_over"] != 1 {
+		t.Errorf("Expected the game over event, got %v", b.Events())
+	}
+	if events := b.Step(); len(events) != 0 {
+		t.Errorf("Expected no events after the game over, got %v", events)
+	}
+}
+
+func TestBrickHitNotCleared(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	br := b.bricks[0][0]
+	br.SetKind(BrickMultiHit, 2)
+	b.hitBrick(br)
+	hit, ok := b.events[0].(BrickHit)
+	if !ok || hit.Cleared || hit.Points != 0 || hit.Kind != "multi" {
+		t.Errorf("Expected an uncleared multi-hit brick without points, got %+v", b.events)
+	}
+}
+
+func TestListeners(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	var got []Event
+	frames := 0
+	remove := b.AddListener(func(events []Event) {
+		frames++
+		got = append(got, events...)
+	})
+	other := 0
+	b.AddListener(func(events []Event) { other++ })
+
+	var want []Event
+	for range 200 {
+		want = append(want, followStep(b)...)
+	}
+	if len(got) != len(want) || frames == 0 || other != frames {
+		t.Fatalf("Expected the listeners to receive the %d events, got %d", len(want), len(got))
+	}
+
+	// clones do not notify, restored games keep their listeners
+	c := b.Clone()
+	for range 200 {
+		followStep(c)
+	}
+	if len(got) != len(want) {
+		t.Error("Expected no events from a clone")
+	}
+	if err := b.Restore(c.Snapshot()); err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+	remove()
+	seen := other
+	for range 200 {
+		followStep(b)
+	}
+	if len(got) != len(want) {
+		t.Error("Expected no events after removing the listener")
+	}
+	if other == seen {
+		t.Error("Expected the other listener to be kept by Restore")
+	}
+}
@@ -0,0 +1,251 @@
+// Package breakout provides the level layout file format and its loader.
+//
+// A level file is a JSON document with a sequence of layouts. Every layout
+// draws its brick grid as text, one string per brick row from top to bottom
+// and one character per brick:
+//
+//	{
+//	  "loop": false,
+//	  "levels": [
+//	    {
+//	      "name": "Checkers",
+//	      "rows": [
+//	        "r.r.r.r.r.r.r.",
+//	        ".o.o.o.o.o.o.o",
+//	        "##....**....##"
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// The characters of the default legend are:
+// - '.' or ' ': No brick.
+// - 'y', 'g', 'o', 'r': Normal yellow, green, orange or red brick.
+// - 'Y', 'G', 'O', 'R': Multi-hit brick (2 hits) in yellow, green, orange or red.
+// - '-': Normal brick in the color of its row.
+// - '#': Indestructible brick.
+// - '*': Explosive brick in the color of its row.
+//
+// A layout can add or override characters with its own "legend", mapping a
+// character to {"kind", "color", "hits"}. Layouts may have fewer rows than
+// the configured brick rows (the missing rows at the bottom stay empty), but
+// every row must have exactly one character per brick.
+//
+// Level N of the game is built from layout N. After the last layout the game
+// is won, unless "loop" is set and the sequence starts over.
+//
+// Types:
+// - LevelSet: A sequence of layouts.
+// - Layout: The bricks of one level.
+// - BrickSpec: The kind, color and hits of the bricks drawn by one character.
+//
+// Functions:
+// - LoadLevels: Reads and checks a level file.
+// - ReadLevels: Reads and checks a level file from a path.
+// - (*LevelSet) Validate: Checks the layouts against a configuration.
+// - (Layout) Bricks: Builds the brick grid of a layout.
+package breakout
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+type LevelSet struct {
+	Levels []Layout `json:"levels"` // layouts of level 1, 2, ...
+	Loop   bool     `json:"loop"`   // start over after the last layout instead of ending the game with a win
+}
+
+type Layout struct {
+	Name   string               `json:"name,omitempty"`   // name of the level
+	Rows   []string             `json:"rows"`             // brick rows from top to bottom
+	Legend map[string]BrickSpec `json:"legend,omitempty"` // additional characters
+}
+
+type BrickSpec struct {
+	Kind  string `json:"kind,omitempty"`  // normal, multi, indestructible or explosive
+	Color string `json:"color,omitempty"` // yellow, green, orange or red, empty for the row color
+	Hits  int    `json:"hits,omitempty"`  // hits of a multi-hit brick
+}
+
+// DefaultLegend maps the layout characters to bricks, a nil spec is no brick
+var DefaultLegend = map[rune]*BrickSpec{
+	'.': nil,
+	' ': nil,
+	'y': {Kind: "normal", Color: "yellow"},
+	'g': {Kind: "normal", Color: "green"},
+	'o': {Kind: "normal", Color: "orange"},
+	'r': {Kind: "normal", Color: "red"},
+	'Y': {Kind: "multi", Color: "yellow", Hits: 2},
+	'G': {Kind: "multi", Color: "green", Hits: 2},
+	'O': {Kind: "multi", Color: "orange", Hits: 2},
+	'R': {Kind: "multi", Color: "red", Hits: 2},
+	'-': {Kind: "normal"},
+	'#': {Kind: "indestructible"},
+	'*': {Kind: "explosive"},
+}
+
+// brickKinds maps the kind names of a BrickSpec to brick kinds
+var brickKinds = map[string]BrickKind{
+	"":               BrickNormal,
+	"normal":         BrickNormal,
+	"multi":          BrickMultiHit,
+	"indestructible": BrickIndestructible,
+	"explosive":      BrickExplosive,
+}
+
+// brickColors lists the colors a BrickSpec can use
+var brickColors = map[string]bool{
+	"":       true,
+	"yellow": true,
+	"green":  true,
+	"orange": true,
+	"red":    true,
+}
+
+// LoadLevels reads a level file from r and checks its layouts. The sizes of
+// the layouts are checked against the game configuration by Config.Validate.
+func LoadLevels(r io.Reader) (*LevelSet, error) {
+	var set LevelSet
+	if err := json.NewDecoder(r).Decode(&set); err != nil {
+		return nil, fmt.Errorf("parse levels: %w", err)
+	}
+	if len(set.Levels) == 0 {
+		return nil, fmt.Errorf("no levels")
+	}
+	for i, l := range set.Levels {
+		if _, err := l.legend(); err != nil {
+			return nil, fmt.Errorf("level %d: %w", i+1, err)
+		}
+	}
+	return &set, nil
+}
+
+// ReadLevels reads a level file from path, see LoadLevels
+func ReadLevels(path string) (*LevelSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	set, err := LoadLevels(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Validate checks that every layout fits into the brick grid of cfg and
+// holds at least one brick that can be cleared.
+func (s *LevelSet) Validate(cfg Config) error {
+	if len(s.Levels) == 0 {
+		return fmt.Errorf("no levels")
+	}
+	for i, l := range s.Levels {
+		if err := l.validate(cfg); err != nil {
+			return fmt.Errorf("level %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Layout returns the layout of the given level (starting with 1), looping
+// over the sequence if Loop is set. It returns false if the sequence has no
+// layout for the level, i.e. the game is won.
+func (s *LevelSet) Layout(level int) (Layout, bool) {
+	i := level - 1
+	if i < 0 {
+		return Layout{}, false
+	}
+	if i >= len(s.Levels) {
+		if !s.Loop || len(s.Levels) == 0 {
+			return Layout{}, false
+		}
+		i %= len(s.Levels)
+	}
+	return s.Levels[i], true
+}
+
+// legend returns the default legend extended by the legend of the layout
+func (l Layout) legend() (map[rune]*BrickSpec, error) {
+	legend := make(map[rune]*BrickSpec, len(DefaultLegend)+len(l.Legend))
+	for c, spec := range DefaultLegend {
+		legend[c] = spec
+	}
+	for key, spec := range l.Legend {
+		chars := []rune(key)
+		if len(chars) != 1 {
+			return nil, fmt.Errorf("legend key %q is not a single character", key)
+		}
+		if _, ok := brickKinds[spec.Kind]; !ok {
+			return nil, fmt.Errorf("legend %q: unknown brick kind %q", key, spec.Kind)
+		}
+		if !brickColors[spec.Color] {
+			return nil, fmt.Errorf("legend %q: unknown brick color %q", key, spec.Color)
+		}
+		legend[chars[0]] = &spec
+	}
+	return legend, nil
+}
+
+// validate checks the layout against the brick grid of cfg
+func (l Layout) validate(cfg Config) error {
+	legend, err := l.legend()
+	if err != nil {
+		return err
+	}
+	if len(l.Rows) > cfg.BrickRows {
+		return fmt.Errorf("%d rows, the game has %d brick rows", len(l.Rows), cfg.BrickRows)
+	}
+	clearable := 0
+	for i, row := range l.Rows {
+		chars := []rune(row)
+		if len(chars) != cfg.BricksPerRow {
+			return fmt.Errorf("row %d has %d bricks, the game has %d bricks per row", i+1, len(chars), cfg.BricksPerRow)
+		}
+		for j, c := range chars {
+			spec, ok := legend[c]
+			if !ok {
+				return fmt.Errorf("row %d column %d: unknown character %q", i+1, j+1, c)
+			}
+			if spec != nil && brickKinds[spec.Kind] != BrickIndestructible {
+				clearable++
+			}
+		}
+	}
+	if clearable == 0 {
+		return fmt.Errorf("no bricks to clear")
+	}
+	return nil
+}
+
+// Bricks builds the brick grid of the layout for cfg. The first row of the
+// layout is the top row of the grid, cells without a brick are nil. The
+// layout must have been checked with Validate, unknown characters are left
+// empty.
+func (l Layout) Bricks(cfg *Config) [][]*Brick {
+	legend, _ := l.legend()
+	bricks := make([][]*Brick, cfg.BrickRows)
+	for i := range bricks {
+		bricks[i] = make([]*Brick, cfg.BricksPerRow)
+	}
+	for i, line := range l.Rows {
+		row := cfg.BrickRows - 1 - i // grid row 0 is the bottom row
+		if row < 0 {
+			break
+		}
+		for col, c := range []rune(line) {
+			spec := legend[c]
+			if spec == nil || col >= cfg.BricksPerRow {
+				continue
+			}
+			br := NewBrick(cfg, row, col)
+			br.SetKind(brickKinds[spec.Kind], spec.Hits)
+			br.SetColor(spec.Color)
+			bricks[row][col] = br
+		}
+	}
+	return bricks
+}
@@ -0,0 +1,152 @@
+package breakout
+
+import (
+	"strings"
+	"testing"
+)
+
+const testLevels = `{
+  "levels": [
+    {"name": "one", "rows": ["r.#*X.rrrrrrrr"], "legend": {"X": {"kind": "multi", "color": "green", "hits": 4}}},
+    {"name": "two", "rows": ["..............", "yyyyyyyyyyyyyy"]}
+  ]
+}`
+
+func loadTestLevels(t *testing.T) *LevelSet {
+	set, err := LoadLevels(strings.NewReader(testLevels))
+	if err != nil {
+		t.Fatalf("Expected levels to load, got %v", err)
+	}
+	return set
+}
+
+func TestLoadLevels(t *testing.T) {
+	set := loadTestLevels(t)
+	if len(set.Levels) != 2 || set.Levels[0].Name != "one" {
+		t.Fatalf("Unexpected level set %+v", set)
+	}
+	if err := set.Validate(DefaultConfig()); err != nil {
+		t.Errorf("Expected levels to fit the default config, got %v", err)
+	}
+}
+
+func TestLayoutBricks(t *testing.T) {
+	set := loadTestLevels(t)
+	cfg := DefaultConfig()
+	bricks := set.Levels[0].Bricks(&cfg)
+
+	// the first layout row is the top row of the grid
+	top := bricks[BRICK_ROWS-1]
+	if top[0] == nil || top[0].GetColor() != "red" || top[0].GetKind() != BrickNormal {
+		t.Error("Expected a normal red brick top left")
+	}
+	if top[1] != nil {
+		t.Error("Expected no brick for '.'")
+	}
+	if top[2].GetKind() != BrickIndestructible || top[3].GetKind() != BrickExplosive {
+		t.Error("Expected indestructible and explosive bricks")
+	}
+	if top[4].GetKind() != BrickMultiHit || top[4].GetHits() != 4 || top[4].GetColor() != "green" || top[4].GetPoints() != 3 {
+		t.Errorf("Expected the legend to define a green 4 hit brick, got %+v", top[4].GetState())
+	}
+	// the explosive brick takes the color of its row
+	if top[3].GetColor() != "red" {
+		t.Errorf("Expected the row color for the explosive brick, got %s", top[3].GetColor())
+	}
+	for i := 0; i < BRICK_ROWS-1; i++ {
+		for j := range bricks[i] {
+			if bricks[i][j] != nil {
+				t.Fatalf("Expected rows missing from the layout to be empty, found brick %d,%d", i, j)
+			}
+		}
+	}
+}
+
+func TestLoadLevelsErrors(t *testing.T) {
+	cases := map[string]string{
+		"bad json":       `{"levels": [`,
+		"no levels":      `{"levels": []}`,
+		"bad kind":       `{"levels": [{"rows": ["X"], "legend": {"X": {"kind": "rubber"}}}]}`,
+		"bad color":      `{"levels": [{"rows": ["X"], "legend": {"X": {"color": "pink"}}}]}`,
+		"bad legend key": `{"levels": [{"rows": ["X"], "legend": {"XY": {}}}]}`,
+	}
+	for name, data := range cases {
+		if _, err := LoadLevels(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLevelSetValidateErrors(t *testing.T) {
+	cases := map[string]Layout{
+		"short row":      {Rows: []string{"rrr"}},
+		"unknown char":   {Rows: []string{"rrrrrrrrrrrrr?"}},
+		"too many rows":  {Rows: strings.Split(strings.Repeat("rrrrrrrrrrrrrr,", BRICK_ROWS), ",")},
+		"nothing to hit": {Rows: []string{"##############"}},
+	}
+	for name, layout := range cases {
+		set := &LevelSet{Levels: []Layout{layout}}
+		if err := set.Validate(DefaultConfig()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadExampleLevels(t *testing.T) {
+	set, err := ReadLevels("../../levels/example.json")
+	if err != nil {
+		t.Fatalf("Expected the example levels to load, got %v", err)
+	}
+	if err := set.Validate(DefaultConfig()); err != nil {
+		t.Errorf("Expected the example levels to fit the default config, got %v", err)
+	}
+}
+
+// clearLevel clears all bricks of the current level
+func clearLevel(b *Breakout) {
+	for _, row := range b.bricks {
+		for _, brick := range row {
+			if brick != nil {
+				brick.SetCleared(true)
+			}
+		}
+	}
+}
+
+func TestLevelSequence(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Levels = loadTestLevels(t)
+	breakout := NewBreakout(cfg, 1)
+
+	if n := len(breakout.GetState().Bricks); n != 12 {
+		t.Fatalf("Expected 12 bricks on level 1, got %d", n)
+	}
+	clearLevel(breakout)
+	breakout.MoveBall()
+	state := breakout.GetState()
+	if state.Level != 2 || len(state.Bricks) != BRICKS_PER_ROW || state.Bricks[0].Color != "yellow" {
+		t.Fatalf("Expected level 2 with a row of yellow bricks, got level %d with %d bricks", state.Level, len(state.Bricks))
+	}
+	clearLevel(breakout)
+	breakout.MoveBall()
+	state = breakout.GetState()
+	if !state.Won || !state.Done || state.Level != 2 {
+		t.Errorf("Expected the game to be won after the last level, got %+v", state)
+	}
+}
+
+func TestLevelSequenceLoop(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Levels = loadTestLevels(t)
+	cfg.Levels.Loop = true
+	breakout := NewBreakout(cfg, 1)
+
+	clearLevel(breakout)
+	breakout.MoveBall()
+	clearLevel(breakout)
+	breakout.MoveBall()
+	state := breakout.GetState()
+	if state.Won || state.Level != 3 || len(state.Bricks) != 12 {
+		t.Errorf("Expected level 3 to loop back to the first layout, got level %d with %d bricks", state.Level, len(state.Bricks))
+	}
+}
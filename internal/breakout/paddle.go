@@ -6,45 +6,49 @@
 // creating a new paddle, retrieving its properties, and modifying its
 // position and dimensions.
 //
-// Constants:
-// - AREA_WIDTH: Represents the width of the game area. This constant is
+// Configuration:
+// - Config.AreaWidth: Represents the width of the game area. It is
 //   used to constrain the paddle's movement within the game boundaries.
+// - Config.PaddleWidth, Config.PaddleHeight, Config.PaddleStep: The paddle
+//   size and the distance it moves on every input.
 //
 // Types:
 // - Paddle: A struct that defines the paddle's position (x-coordinate),
 //   width, and height.
 //
 // Functions:
-// - NewPaddle: Creates and returns a new Paddle instance with default
-//   dimensions and position.
+// - NewPaddle: Creates and returns a new Paddle instance with the
+//   configured dimensions and position.
 //
 // Methods:
 // - GetX: Returns the current x-coordinate of the paddle.
 // - GetWidth: Returns the current width of the paddle.
 // - GetHeight: Returns the current height of the paddle.
-// - Shrink: Reduces the paddle's width to a smaller size (minimum 12).
-// - UnShrink: Ensures the paddle's width is at least the default minimum
-//   width of 24.
-// - MoveLeft: Moves the paddle to the left by 3 units, constrained by the
-//   left boundary of the game area.
-// - MoveRight: Moves the paddle to the right by 3 units, constrained by
-//   the right boundary of the game area.
+// - Shrink: Reduces the paddle's width to half of the configured width.
+// - UnShrink: Ensures the paddle's width is at least the configured width.
+// - SetWidth: Resizes the paddle around its center.
+// - MoveLeft: Moves the paddle to the left by the configured step,
+//   constrained by the left boundary of the game area.
+// - MoveRight: Moves the paddle to the right by the configured step,
+//   constrained by the right boundary of the game area.
 package breakout
 
 type Paddle struct {
 	x      int
 	width  int
 	height int
+	cfg    *Config // game configuration, holds the area width and paddle defaults
 }
 
-// NewPaddle creates and returns a new Paddle instance with default
-// dimensions and position. The paddle is initialized at the center
-// of the game area horizontally, with a predefined width and height.
-func NewPaddle() *Paddle {
+// NewPaddle creates and returns a new Paddle instance with the dimensions
+// from cfg. The paddle is initialized at the center of the game area
+// horizontally.
+func NewPaddle(cfg *Config) *Paddle {
 	return &Paddle{
-		x:      AREA_WIDTH/2 - 12,
-		width:  24,
-		height: 4,
+		x:      cfg.AreaWidth/2 - cfg.PaddleWidth/2,
+		width:  cfg.PaddleWidth,
+		height: cfg.PaddleHeight,
+		cfg:    cfg,
 	}
 }
 
@@ -67,41 +71,51 @@ func (p *Paddle) GetHeight() int {
 }
 
 // Shrink reduces the width of the paddle to a smaller size.
-// The width is set to 12 if it is currently greater than 12.
+// The width is set to half of the configured width if it is currently wider.
 func (p *Paddle) Shrink() {
-	if p.width > 12 {
-		p.width = 12
+	small := max(p.cfg.PaddleWidth/2, 1)
+	if p.width > small {
+		p.width = small
 	}
 }
 
-// UnShrink ensures that the paddle's width is at least the default minimum width of 24.
-// If the current width is less than 24, it resets the width to 24.
+// UnShrink ensures that the paddle's width is at least the configured width.
+// If the current width is smaller, it resets the width to the configured one.
 func (p *Paddle) UnShrink() {
-	if p.width < 24 {
-		p.width = 24
+	if p.width < p.cfg.PaddleWidth {
+		p.width = p.cfg.PaddleWidth
 	}
 }
 
-// MoveLeft moves the paddle to the left by 2 units.
+// SetWidth resizes the paddle keeping its center where it is.
+// The paddle's position is constrained to the game area.
+func (p *Paddle) SetWidth(width int) {
+	width = max(1, min(width, p.cfg.AreaWidth))
+	p.x += (p.width - width) / 2
+	p.width = width
+	p.x = max(0, min(p.x, p.cfg.AreaWidth-p.width))
+}
+
+// MoveLeft moves the paddle to the left by the configured step.
 // The paddle's position is constrained to ensure it does not move
 // beyond the left boundary of the game area.
 func (p *Paddle) MoveLeft() {
 	if p.x >= 0 {
-		p.x -= 3
+		p.x -= p.cfg.PaddleStep
 	}
 	if p.x < 0 {
 		p.x = 0
 	}
 }
 
-// MoveRight moves the paddle to the right by 2 units.
+// MoveRight moves the paddle to the right by the configured step.
 // The paddle's position is constrained to ensure it does not move
 // beyond the right boundary of the game area.
 func (p *Paddle) MoveRight() {
-	if p.x <= AREA_WIDTH-p.width {
-		p.x += 3
+	if p.x <= p.cfg.AreaWidth-p.width {
+		p.x += p.cfg.PaddleStep
 	}
-	if p.x > AREA_WIDTH-p.width {
-		p.x = AREA_WIDTH - p.width
+	if p.x > p.cfg.AreaWidth-p.width {
+		p.x = p.cfg.AreaWidth - p.width
 	}
 }
@@ -3,7 +3,7 @@ package breakout
 import "testing"
 
 func TestPaddleNew(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	if paddle.x != AREA_WIDTH/2-12 {
 		t.Errorf("Expected x to be %d, got %d", AREA_WIDTH/2-12, paddle.x)
 	}
@@ -16,28 +16,28 @@ func TestPaddleNew(t *testing.T) {
 }
 
 func TestPaddleGetX(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	if paddle.GetX() != paddle.x {
 		t.Errorf("Expected GetX to return %d, got %d", paddle.x, paddle.GetX())
 	}
 }
 
 func TestPaddleGetWidth(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	if paddle.GetWidth() != paddle.width {
 		t.Errorf("Expected GetWidth to return %d, got %d", paddle.width, paddle.GetWidth())
 	}
 }
 
 func TestPaddleGetHeight(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	if paddle.GetHeight() != paddle.height {
 		t.Errorf("Expected GetHeight to return %d, got %d", paddle.height, paddle.GetHeight())
 	}
 }
 
 func TestPaddleShrink(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	paddle.Shrink()
 	if paddle.width != 12 {
 		t.Errorf("Expected width to be 12 after Shrink, got %d", paddle.width)
@@ -45,7 +45,7 @@ func TestPaddleShrink(t *testing.T) {
 }
 
 func TestPaddleUnShrink(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	paddle.Shrink()
 	if paddle.width != 12 {
 		t.Errorf("Expected width to be 12 after Shrink, got %d", paddle.width)
@@ -57,7 +57,7 @@ func TestPaddleUnShrink(t *testing.T) {
 }
 
 func TestPaddleMoveLeft(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	initialX := paddle.x
 	paddle.MoveLeft()
 	if paddle.x != initialX-3 && paddle.x != 0 {
@@ -73,7 +73,7 @@ func TestPaddleMoveLeft(t *testing.T) {
 }
 
 func TestPaddleMoveRight(t *testing.T) {
-	paddle := NewPaddle()
+	paddle := NewPaddle(&testConfig)
 	initialX := paddle.x
 	paddle.MoveRight()
 	if paddle.x != initialX+3 && paddle.x != AREA_WIDTH-paddle.width {
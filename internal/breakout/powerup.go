@@ -0,0 +1,346 @@
+// Package breakout provides the optional power-up subsystem (Config.PowerUps).
+//
+// When a brick is cleared it drops a capsule with the probability
+// Config.PowerUpChance. Capsules fall down and are caught with the paddle,
+// catching one activates its power-up:
+//   - wide: The paddle is one and a half times as wide (timed).
+//   - slow: The ball slows down (timed).
+//   - multi: Two more balls split off every ball in play (up to MAX_BALLS).
+//   - sticky: The ball sticks to the paddle until it is released with
+//     ReleaseBall or after STICKY_HOLD frames (timed).
+//   - laser: The paddle fires two laser shots every LASER_INTERVAL frames,
+//     a shot hits the first brick above it (timed).
+//   - life: An extra life, up to the maximum of 5 lives.
+//
+// Timed power-ups last POWERUP_DURATION frames, catching the same kind again
+// restarts the time. Losing a life or advancing to the next level ends all
+// power-ups and removes the falling capsules and laser shots.
+//
+// Types:
+//   - PowerUpKind: The kind of a power-up.
+//   - Capsule: A falling capsule.
+//   - Laser: A laser shot.
+//   - CapsuleState, EffectState, LaserState: The state of capsules, active
+//     power-ups and laser shots used in BreakoutState.
+//
+// Functions:
+//   - (*Breakout) dropCapsule: Drops a capsule from a cleared brick.
+//   - (*Breakout) updatePowerUps: Moves capsules and shots, counts down the
+//     timed power-ups.
+//   - (*Breakout) ReleaseBall: Releases balls held by the sticky paddle.
+package breakout
+
+const (
+	POWERUP_CHANCE   = 0.15 // default chance that a cleared brick drops a capsule
+	POWERUP_DURATION = 600  // frames a timed power-up lasts
+	CAPSULE_WIDTH    = 10
+	CAPSULE_HEIGHT   = 4
+	CAPSULE_SPEED    = 1.0 // distance a capsule falls on every frame
+	SLOW_FACTOR      = 0.6 // factor applied to the ball speed by the slow power-up
+	WIDE_FACTOR      = 1.5 // factor applied to the paddle width by the wide power-up
+	STICKY_HOLD      = 90  // frames the sticky paddle holds the ball at most
+	LASER_INTERVAL   = 20  // frames between two laser shots
+	LASER_SPEED      = 6.0 // distance a laser shot moves on every frame
+	LASER_LENGTH     = 4   // length of a laser shot
+	MAX_LIVES        = 5   // number of lives of a game
+	MAX_BALLS        = 9   // number of balls the multi-ball power-up puts into play at most
+	SPLIT_ANGLE      = 30  // angle between a ball and the balls split off it
+)
+
+// PowerUpKind is the kind of a power-up
+type PowerUpKind int
+
+const (
+	PowerWide PowerUpKind = iota
+	PowerSlow
+	PowerMultiBall
+	PowerSticky
+	PowerLaser
+	PowerExtraLife
+	numPowerUps
+)
+
+// String returns the name of the power-up as used in the game state
+func (k PowerUpKind) String() string {
+	switch k {
+	case PowerWide:
+		return "wide"
+	case PowerSlow:
+		return "slow"
+	case PowerMultiBall:
+		return "multi"
+	case PowerSticky:
+		return "sticky"
+	case PowerLaser:
+		return "laser"
+	case PowerExtraLife:
+		return "life"
+	default:
+		return "unknown"
+	}
+}
+
+// timed returns true if the power-up lasts POWERUP_DURATION frames
+func (k PowerUpKind) timed() bool {
+	return k == PowerWide || k == PowerSlow || k == PowerSticky || k == PowerLaser
+}
+
+// dropTable lists the power-ups a capsule can hold, drawn with equal chance
+var dropTable = []PowerUpKind{PowerWide, PowerSlow, PowerMultiBall, PowerSticky, PowerLaser, PowerExtraLife}
+
+type Capsule struct {
+	x, y float64     // top left corner of the capsule
+	kind PowerUpKind // power-up the capsule holds
+}
+
+type Laser struct {
+	x, y float64 // top end of the laser shot
+}
+
+type CapsuleState struct {
+	X, Y          int    // coordinates of the capsule
+	Width, Height int    // dimensions of the capsule
+	Kind          string // power-up the capsule holds
+}
+
+type EffectState struct {
+	Kind      string // active power-up
+	Remaining int    // frames left
+}
+
+type LaserState struct {
+	X, Y   int // top end of the laser shot
+	Length int // length of the laser shot
+}
+
+// dropCapsule lets a cleared brick drop a capsule with the configured chance
+func (b *Breakout) dropCapsule(br *Brick) {
+	if !b.cfg.PowerUps || b.rng.Float64() >= b.cfg.PowerUpChance {
+		return
+	}
+	b.capsules = append(b.capsules, &Capsule{
+		x:    float64(br.GetX() + br.GetWidth()/2 - CAPSULE_WIDTH/2),
+		y:    float64(br.GetY()),
+		kind: dropTable[b.rng.IntN(len(dropTable))],
+	})
+}
+
+// updatePowerUps moves the capsules and laser shots by one frame, activates
+// caught capsules and counts down the timed power-ups.
+func (b *Breakout) updatePowerUps() {
+	// capsules fall down and are caught with the paddle
+	paX, paW := float64(b.paddle.GetX()), float64(b.paddle.GetWidth())
+	paY := float64(b.cfg.AreaHeight - b.paddle.GetHeight())
+	capsules := b.capsules[:0]
+	for _, c := range b.capsules {
+		c.y += CAPSULE_SPEED
+		if c.y+CAPSULE_HEIGHT >= paY && c.y <= paY+float64(b.paddle.GetHeight()) &&
+			c.x+CAPSULE_WIDTH >= paX && c.x <= paX+paW {
+			b.activate(c.kind)
+			continue
+		}
+		if c.y < float64(b.cfg.AreaHeight) {
+			capsules = append(capsules, c)
+		}
+	}
+	b.capsules = capsules
+
+	// laser shots move up and hit the first brick in their way
+	lasers := b.lasers[:0]
+	for _, l := range b.lasers {
+		if br := b.laserTarget(l.x, l.y-LASER_SPEED, l.y+LASER_LENGTH); br != nil {
+			b.hitBrick(br)
+			continue
+		}
+		l.y -= LASER_SPEED
+		if l.y+LASER_LENGTH > 0 {
+			lasers = append(lasers, l)
+		}
+	}
+	b.lasers = lasers
+
+	// timed power-ups
+	for k := range numPowerUps {
+		if b.effects[k] == 0 {
+			continue
+		}
+		b.effects[k]--
+		if k == PowerLaser && b.effects[k]%LASER_INTERVAL == 0 {
+			b.fireLaser()
+		}
+		if b.effects[k] == 0 {
+			b.deactivate(k)
+		}
+	}
+}
+
+// activate starts the effect of a caught capsule
+func (b *Breakout) activate(kind PowerUpKind) {
+	switch kind {
+	case PowerMultiBall:
+		balls := b.balls
+		for _, bl := range balls {
+			for _, angle := range []float64{-SPLIT_ANGLE, SPLIT_ANGLE} {
+				if len(b.balls) < MAX_BALLS {
+					b.balls = append(b.balls, bl.Split(angle))
+				}
+			}
+		}
+	case PowerExtraLife:
+		if b.live > 1 {
+			b.live--
+			b.emit(LifeGained{Lives: b.Lives()})
+		}
+	}
+	if kind.timed() {
+		b.effects[kind] = POWERUP_DURATION
+	}
+	switch kind {
+	case PowerWide:
+		b.paddle.SetWidth(b.paddleWidth())
+	case PowerSlow:
+		b.setBallSpeed()
+	}
+}
+
+// deactivate ends the effect of a timed power-up
+func (b *Breakout) deactivate(kind PowerUpKind) {
+	switch kind {
+	case PowerWide:
+		b.paddle.SetWidth(b.paddleWidth())
+	case PowerSlow:
+		b.setBallSpeed()
+	case PowerSticky:
+		b.ReleaseBall()
+	}
+}
+
+// clearPowerUps ends all power-ups and removes capsules and laser shots
+func (b *Breakout) clearPowerUps() {
+	b.capsules = nil
+	b.lasers = nil
+	b.effects = [numPowerUps]int{}
+}
+
+// paddleWidth returns the paddle width for the active rules and power-ups,
+// the arcade ceiling rule halves the paddle and the wide power-up widens it
+func (b *Breakout) paddleWidth() int {
+	w := b.cfg.PaddleWidth
+	if b.arcade.ceiling {
+		w = max(w/2, 1)
+	}
+	if b.effects[PowerWide] > 0 {
+		w = int(float64(w) * WIDE_FACTOR)
+	}
+	return w
+}
+
+// ballSpeed returns the ball speed for the active rules and power-ups, the
+// configured speed plus the arcade speed-ups, slowed by the slow power-up.
+// Slowing and speeding up are not undone step by step, so they can happen
+// in any order.
+func (b *Breakout) ballSpeed() float64 {
+	speed := b.cfg.BallSpeed * (1 + float64(b.arcade.speedups())*ARCADE_SPEEDUP)
+	if b.effects[PowerSlow] > 0 {
+		speed *= SLOW_FACTOR
+	}
+	return speed
+}
+
+// setBallSpeed sets every ball in play to the current ball speed
+func (b *Breakout) setBallSpeed() {
+	for _, bl := range b.balls {
+		bl.SetSpeed(b.ballSpeed())
+	}
+}
+
+// fireLaser fires two laser shots from the edges of the paddle
+func (b *Breakout) fireLaser() {
+	y := float64(b.cfg.AreaHeight - b.paddle.GetHeight() - LASER_LENGTH)
+	b.lasers = append(b.lasers,
+		&Laser{x: float64(b.paddle.GetX()) + 1, y: y},
+		&Laser{x: float64(b.paddle.GetX()+b.paddle.GetWidth()) - 1, y: y},
+	)
+}
+
+// laserTarget returns the lowest brick at x that overlaps the vertical
+// range top-bottom, or nil
+func (b *Breakout) laserTarget(x, top, bottom float64) *Brick {
+	var target *Brick
+	for i := range b.bricks {
+		for j := range b.bricks[i] {
+			br := b.bricks[i][j]
+			if br == nil || br.IsCleared() {
+				continue
+			}
+			if x < float64(br.GetX()) || x >= float64(br.GetX()+br.GetWidth()) {
+				continue
+			}
+			if float64(br.GetY()+br.GetHeight()) < top || float64(br.GetY()) > bottom {
+				continue
+			}
+			if target == nil || br.GetY() > target.GetY() {
+				target = br
+			}
+		}
+	}
+	return target
+}
+
+// holdBall lets the sticky paddle catch the ball after it bounced off the
+// paddle, it returns false if the sticky power-up is not active
+func (b *Breakout) holdBall(bl *Ball) bool {
+	if b.effects[PowerSticky] == 0 {
+		return false
+	}
+	bl.held = STICKY_HOLD
+	bl.heldOffset = bl.x - float64(b.paddle.GetX())
+	bl.y = float64(b.cfg.AreaHeight - b.paddle.GetHeight() - bl.radius)
+	return true
+}
+
+// moveHeldBall keeps a held ball on the paddle, it returns false if the
+// ball is not held
+func (b *Breakout) moveHeldBall(bl *Ball) bool {
+	if bl.held == 0 {
+		return false
+	}
+	bl.held--
+	bl.x = float64(b.paddle.GetX()) + bl.heldOffset
+	bl.y = float64(b.cfg.AreaHeight - b.paddle.GetHeight() - bl.radius)
+	return true
+}
+
+// ReleaseBall releases the balls held by the sticky paddle, they keep the
+// direction they got from the paddle bounce
+func (b *Breakout) ReleaseBall() {
+	for _, bl := range b.balls {
+		bl.held = 0
+	}
+}
+
+// powerUpState returns the state of the capsules, the active power-ups and
+// the laser shots
+func (b *Breakout) powerUpState() ([]CapsuleState, []EffectState, []LaserState) {
+	var capsules []CapsuleState
+	for _, c := range b.capsules {
+		capsules = append(capsules, CapsuleState{
+			X:      int(c.x),
+			Y:      int(c.y),
+			Width:  CAPSULE_WIDTH,
+			Height: CAPSULE_HEIGHT,
+			Kind:   c.kind.String(),
+		})
+	}
+	var effects []EffectState
+	for k := range numPowerUps {
+		if b.effects[k] > 0 {
+			effects = append(effects, EffectState{Kind: k.String(), Remaining: b.effects[k]})
+		}
+	}
+	var lasers []LaserState
+	for _, l := range b.lasers {
+		lasers = append(lasers, LaserState{X: int(l.x), Y: int(l.y), Length: LASER_LENGTH})
+	}
+	return capsules, effects, lasers
+}
@@ -0,0 +1,203 @@
+package breakout
+
+import (
+	"math"
+	"testing"
+)
+
+func newPowerUpGame() *Breakout {
+	cfg := DefaultConfig()
+	cfg.PowerUps = true
+	cfg.PowerUpChance = 1
+	return NewBreakout(cfg, 1)
+}
+
+// parkBall keeps the ball away from bricks and paddle for a while
+func parkBall(b *Breakout) {
+	b.balls[0].x = AREA_WIDTH / 2
+	b.balls[0].y = AREA_HEIGHT / 2
+	b.balls[0].SetDir(0)
+}
+
+func TestNoCapsulesWithoutPowerUps(t *testing.T) {
+	breakout := NewBreakout(DefaultConfig(), 1)
+	breakout.hitBrick(breakout.bricks[0][0])
+	if len(breakout.capsules) != 0 {
+		t.Error("Expected no capsules without Config.PowerUps")
+	}
+}
+
+func TestCapsuleDropAndCatch(t *testing.T) {
+	breakout := newPowerUpGame()
+	brick := breakout.bricks[0][5]
+	breakout.hitBrick(brick)
+	if len(breakout.capsules) != 1 {
+		t.Fatalf("Expected one capsule, got %d", len(breakout.capsules))
+	}
+	capsule := breakout.capsules[0]
+	capsule.kind = PowerSticky
+	// put the paddle under the capsule
+	breakout.paddle.x = int(capsule.x) - 5
+
+	caught := false
+	for i := 0; i < AREA_HEIGHT; i++ {
+		parkBall(breakout)
+		breakout.MoveBall()
+		if breakout.effects[PowerSticky] > 0 {
+			caught = true
+			break
+		}
+	}
+	if !caught {
+		t.Fatal("Expected the capsule to be caught with the paddle")
+	}
+	if len(breakout.capsules) != 0 {
+		t.Error("Expected the caught capsule to be removed")
+	}
+	state := breakout.GetState()
+	if len(state.Effects) != 1 || state.Effects[0].Kind != "sticky" {
+		t.Errorf("Expected the sticky effect in the state, got %+v", state.Effects)
+	}
+}
+
+func TestMissedCapsuleDisappears(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.hitBrick(breakout.bricks[0][0])
+	breakout.paddle.x = AREA_WIDTH - breakout.paddle.width
+	for i := 0; i < AREA_HEIGHT; i++ {
+		parkBall(breakout)
+		breakout.MoveBall()
+	}
+	if len(breakout.capsules) != 0 {
+		t.Error("Expected the missed capsule to fall out of the game")
+	}
+}
+
+func TestWidePowerUp(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.activate(PowerWide)
+	if breakout.paddle.GetWidth() != PADDLE_WIDTH*3/2 {
+		t.Errorf("Expected wide paddle %d, got %d", PADDLE_WIDTH*3/2, breakout.paddle.GetWidth())
+	}
+	for i := 0; i < POWERUP_DURATION; i++ {
+		breakout.updatePowerUps()
+	}
+	if breakout.paddle.GetWidth() != PADDLE_WIDTH {
+		t.Errorf("Expected paddle width %d after the power-up, got %d", PADDLE_WIDTH, breakout.paddle.GetWidth())
+	}
+}
+
+func TestSlowPowerUp(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.activate(PowerSlow)
+	breakout.activate(PowerSlow) // catching it again only restarts the time
+	if math.Abs(breakout.balls[0].GetSpeed()-BALL_SPEED*SLOW_FACTOR) > 1e-9 {
+		t.Errorf("Expected slow ball, got speed %f", breakout.balls[0].GetSpeed())
+	}
+	for i := 0; i < POWERUP_DURATION; i++ {
+		breakout.updatePowerUps()
+	}
+	if math.Abs(breakout.balls[0].GetSpeed()-BALL_SPEED) > 1e-9 {
+		t.Errorf("Expected speed %d after the power-up, got %f", BALL_SPEED, breakout.balls[0].GetSpeed())
+	}
+}
+
+func TestStickyPowerUp(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.activate(PowerSticky)
+	ball := breakout.balls[0]
+	paddle := breakout.paddle
+	ball.SetDir(90)
+	ball.x = float64(paddle.x + paddle.width/2)
+	ball.y = float64(AREA_HEIGHT-paddle.height-ball.radius) - 1
+
+	breakout.MoveBall()
+	if ball.held == 0 {
+		t.Fatal("Expected the sticky paddle to hold the ball")
+	}
+	breakout.PaddleLeft()
+	breakout.MoveBall()
+	if ball.GetX() != paddle.x+paddle.width/2 {
+		t.Errorf("Expected the held ball to move with the paddle, got x %d", ball.GetX())
+	}
+
+	breakout.ReleaseBall()
+	y := ball.y
+	breakout.MoveBall()
+	if ball.y >= y {
+		t.Error("Expected the released ball to move up")
+	}
+}
+
+func TestLaserPowerUp(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.cfg.PowerUpChance = 0 // keep capsules out of the way
+	breakout.activate(PowerLaser)
+	brick := breakout.bricks[0][0]
+	// paddle at the left edge, its left laser hits column 0
+	breakout.paddle.x = 0
+
+	for i := 0; i < LASER_INTERVAL; i++ {
+		breakout.updatePowerUps()
+	}
+	if len(breakout.GetState().Lasers) != 2 {
+		t.Fatalf("Expected two laser shots, got %d", len(breakout.lasers))
+	}
+	for i := 0; i < AREA_HEIGHT/int(LASER_SPEED); i++ {
+		breakout.updatePowerUps()
+	}
+	if !brick.IsCleared() {
+		t.Error("Expected the laser to clear the lowest brick above it")
+	}
+	if breakout.bricks[1][0].IsCleared() {
+		t.Error("Expected the laser shot to stop at the first brick")
+	}
+}
+
+func TestExtraLifePowerUp(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.live = 3
+	breakout.activate(PowerExtraLife)
+	if breakout.live != 2 {
+		t.Errorf("Expected one more life, got live %d", breakout.live)
+	}
+	breakout.activate(PowerExtraLife)
+	breakout.activate(PowerExtraLife)
+	if breakout.live != 1 {
+		t.Errorf("Expected at most %d lives, got live %d", MAX_LIVES, breakout.live)
+	}
+}
+
+func TestLifeLostEndsPowerUps(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.activate(PowerWide)
+	breakout.activate(PowerLaser)
+	breakout.hitBrick(breakout.bricks[0][0])
+	breakout.balls[0].y = AREA_HEIGHT + 1 // Simulate ball falling out of bounds
+
+	breakout.MoveBall()
+
+	state := breakout.GetState()
+	if len(state.Effects) != 0 || len(state.Capsules) != 0 || state.PaddleWidth != PADDLE_WIDTH {
+		t.Errorf("Expected all power-ups to end with a lost life, got %+v %+v", state.Effects, state.Capsules)
+	}
+}
+
+func TestMultiBallPowerUp(t *testing.T) {
+	breakout := newPowerUpGame()
+	breakout.activate(PowerMultiBall)
+	if len(breakout.balls) != 3 {
+		t.Fatalf("Expected 3 balls, got %d", len(breakout.balls))
+	}
+	breakout.activate(PowerMultiBall)
+	breakout.activate(PowerMultiBall)
+	if len(breakout.balls) != MAX_BALLS {
+		t.Errorf("Expected at most %d balls, got %d", MAX_BALLS, len(breakout.balls))
+	}
+	breakout.activate(PowerSlow)
+	for _, ball := range breakout.balls {
+		if math.Abs(ball.GetSpeed()-BALL_SPEED*SLOW_FACTOR) > 1e-9 {
+			t.Fatal("Expected the slow power-up to slow down every ball")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package breakout provides the record of what happened in a frame, a
+// summary of the events of the frame (see events.go) for callers like
+// reward functions.
+//
+// Types:
+// - FrameRecord: The summary of one frame.
+// - ClearedBrick: A brick cleared in the frame and the points it scored.
+//
+// Functions:
+// - (*Breakout) LastFrame: Returns the record of the last frame.
+// - (*FrameRecord) Points: Returns the points scored in the frame.
+package breakout
+
+type ClearedBrick struct {
+	Row, Col int    // row and column of the brick in the brick grid
+	Color    string // color of the brick
+	Kind     string // kind of the brick, see BrickKind
+	Points   int    // points scored for the brick, including the level multiplier
+}
+
+type FrameRecord struct {
+	Cleared      []ClearedBrick // bricks cleared in the frame
+	PaddleHits   int            // bounces off the paddle
+	LifeLost     bool           // the last ball drained
+	LifeGained   bool           // an extra life capsule was caught
+	LevelCleared bool           // the level was cleared, also the last one
+	GameOver     bool           // the game ended in the frame
+	Won          bool           // the game ended by clearing the last level
+}
+
+// Points returns the points scored in the frame
+func (r *FrameRecord) Points() int {
+	points := 0
+	for _, c := range r.Cleared {
+		points += c.Points
+	}
+	return points
+}
+
+// LastFrame returns the record of the last frame played by MoveBall
+func (b *Breakout) LastFrame() FrameRecord {
+	var rec FrameRecord
+	for _, e := range b.events {
+		switch e := e.(type) {
+		case BrickHit:
+			if e.Cleared {
+				rec.Cleared = append(rec.Cleared, ClearedBrick{Row: e.Row, Col: e.Col, Color: e.Color, Kind: e.Kind, Points: e.Points})
+			}
+		case PaddleHit:
+			rec.PaddleHits++
+		case LifeLost:
+			rec.LifeLost = true
+		case LifeGained:
+			rec.LifeGained = true
+		case LevelCleared:
+			rec.LevelCleared = true
+		case GameOver:
+			rec.GameOver = true
+			rec.Won = e.Won
+		}
+	}
+	return rec
+}
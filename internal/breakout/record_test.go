@@ -0,0 +1,83 @@
+package breakout
+
+import "testing"
+
+func TestLastFrameMatchesScoreAndLives(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 3)
+	cleared, paddleHits := 0, 0
+	for range 3000 {
+		before := b.GetState()
+		playFrames(b, 1)
+		rec := b.LastFrame()
+		after := b.GetState()
+		if rec.Points() != after.Score-before.Score {
+			t.Fatalf("Expected %d points in the record, got %d", after.Score-before.Score, rec.Points())
+		}
+		if rec.LifeLost != (after.Live > before.Live) {
+			t.Fatalf("Expected LifeLost %v, got %v", after.Live > before.Live, rec.LifeLost)
+		}
+		if (rec.PaddleHits > 0) != (after.FrameReward == 10) {
+			t.Fatalf("Expected paddle hits with the paddle reward, got %d", rec.PaddleHits)
+		}
+		cleared += len(rec.Cleared)
+		paddleHits += rec.PaddleHits
+		if after.Done {
+			break
+		}
+	}
+	if cleared == 0 || paddleHits == 0 {
+		t.Errorf("Expected cleared bricks and paddle hits, got %d and %d", cleared, paddleHits)
+	}
+}
+
+func TestLastFrameLifeLost(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	for range MAX_LIVES {
+		// drop the ball below the paddle
+		b.balls[0].y = AREA_HEIGHT + 10
+		b.MoveBall()
+		if rec := b.LastFrame(); !rec.LifeLost {
+			t.Fatal("Expected a lost life in the record")
+		}
+	}
+	if rec := b.LastFrame(); !rec.GameOver || rec.Won {
+		t.Errorf("Expected the game over in the record, got %+v", rec)
+	}
+	// frames after the game over are empty
+	b.MoveBall()
+	if rec := b.LastFrame(); rec.LifeLost || rec.GameOver {
+		t.Errorf("Expected an empty record after the game over, got %+v", rec)
+	}
+}
+
+func TestLastFrameLevelCleared(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	for i := range b.bricks {
+		for j := range b.bricks[i] {
+			b.bricks[i][j].SetCleared(true)
+		}
+	}
+	b.MoveBall()
+	if rec := b.LastFrame(); !rec.LevelCleared || rec.GameOver {
+		t.Errorf("Expected a cleared level without game over, got %+v", rec)
+	}
+}
+
+func TestLastFrameSummarizesEvents(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	b.events = []Event{
+		BrickHit{Row: 1, Col: 2, Cleared: true, Points: 3},
+		BrickHit{Row: 1, Col: 3},
+		PaddleHit{},
+		WallBounce{Side: WALL_TOP},
+		LifeGained{Lives: 5},
+		GameOver{Won: true},
+	}
+	rec := b.LastFrame()
+	if len(rec.Cleared) != 1 || rec.Points() != 3 || rec.Cleared[0].Col != 2 {
+		t.Errorf("Expected one cleared brick for 3 points, got %+v", rec.Cleared)
+	}
+	if rec.PaddleHits != 1 || !rec.LifeGained || !rec.GameOver || !rec.Won || rec.LifeLost {
+		t.Errorf("Expected the summary of the events, got %+v", rec)
+	}
+}
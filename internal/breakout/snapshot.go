@@ -0,0 +1,286 @@
+// Package breakout provides snapshots and clones of the full game state.
+//
+// GetState is meant for rendering and drops what is not visible, e.g. the
+// ball velocity, cleared bricks or the random source. A Snapshot holds all
+// of it in exported fields, so it can be serialized (e.g. to JSON) to save
+// and resume a game. Clone makes a cheap deep copy of a game, so search
+// based agents can simulate ahead without touching the game in play.
+//
+// Types:
+// - Snapshot: The full, serializable game state.
+// - BallSnapshot, PaddleSnapshot, BrickSnapshot, CapsuleSnapshot,
+//   LaserSnapshot, ArcadeSnapshot: The state of the game elements.
+//
+// Functions:
+// - (*Breakout) Snapshot: Returns the full game state.
+// - (*Breakout) Restore: Replaces the game state with a snapshot.
+// - (*Breakout) Clone: Returns an independent deep copy of the game.
+package breakout
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// SNAPSHOT_VERSION is the version of the Snapshot layout
+const SNAPSHOT_VERSION = 1
+
+type Snapshot struct {
+	Version     int                // layout version, SNAPSHOT_VERSION
+	Config      Config             // game configuration
+	Seed        int64              // seed the game was created with
+	RNG         []byte             // state of the random source
+	Balls       []BallSnapshot     // balls in play
+	Paddle      PaddleSnapshot     // paddle
+	Bricks      [][]*BrickSnapshot // brick grid, nil for cells without a brick
+	Score       int                // current score
+	Level       int                // current level
+	Live        int                // current live
+	FrameReward int                // reward for the current frame
+	GameOver    bool               // game over
+	Won         bool               // all levels of the level sequence are cleared
+	Arcade      ArcadeSnapshot     // counters of the arcade ruleset
+	Capsules    []CapsuleSnapshot  // falling power-up capsules
+	Lasers      []LaserSnapshot    // laser shots
+	Effects     []int              // frames left of the timed power-ups, indexed by PowerUpKind
+}
+
+type BallSnapshot struct {
+	X, Y       float64 // coordinates of the ball
+	Radius     int     // radius of the ball
+	Dir        float64 // direction of the ball
+	Speed      float64 // speed of the ball
+	VX, VY     float64 // x and y speed of the ball
+	Held       int     // frames the sticky paddle still holds the ball
+	HeldOffset float64 // x offset of a held ball from the paddle
+}
+
+type PaddleSnapshot struct {
+	X, Width, Height int // position and size of the paddle
+}
+
+type BrickSnapshot struct {
+	Row, Col int    // position of the brick in the grid
+	Cleared  bool   // the brick has been cleared
+	Kind     int    // kind of the brick, see BrickKind
+	Hits     int    // hits left before the brick is cleared
+	Color    string // color of the brick, empty for the color of its row
+}
+
+type CapsuleSnapshot struct {
+	X, Y float64 // top left corner of the capsule
+	Kind int     // power-up the capsule holds, see PowerUpKind
+}
+
+type LaserSnapshot struct {
+	X, Y float64 // top end of the laser shot
+}
+
+type ArcadeSnapshot struct {
+	Hits    int  // number of paddle hits
+	Orange  bool // the ball has hit an orange brick
+	Red     bool // the ball has hit a red brick
+	Ceiling bool // the ball has hit the top wall
+}
+
+// Snapshot returns the full state of the game. Restoring it gives a game
+// that plays on exactly like this one.
+func (b *Breakout) Snapshot() Snapshot {
+	rng, _ := b.src.MarshalBinary() // never fails
+	s := Snapshot{
+		Version:     SNAPSHOT_VERSION,
+		Config:      *b.cfg,
+		Seed:        b.seed,
+		RNG:         rng,
+		Paddle:      PaddleSnapshot{X: b.paddle.x, Width: b.paddle.width, Height: b.paddle.height},
+		Score:       b.score,
+		Level:       b.level,
+		Live:        b.live,
+		FrameReward: b.frameReward,
+		GameOver:    b.gameOver,
+		Won:         b.won,
+		Arcade: ArcadeSnapshot{
+			Hits:    b.arcade.hits,
+			Orange:  b.arcade.orange,
+			Red:     b.arcade.red,
+			Ceiling: b.arcade.ceiling,
+		},
+		Effects: append([]int(nil), b.effects[:]...),
+	}
+	for _, bl := range b.balls {
+		s.Balls = append(s.Balls, BallSnapshot{
+			X: bl.x, Y: bl.y, Radius: bl.radius, Dir: bl.dir, Speed: bl.speed,
+			VX: bl.v_x, VY: bl.v_y, Held: bl.held, HeldOffset: bl.heldOffset,
+		})
+	}
+	s.Bricks = make([][]*BrickSnapshot, len(b.bricks))
+	for i := range b.bricks {
+		s.Bricks[i] = make([]*BrickSnapshot, len(b.bricks[i]))
+		for j, br := range b.bricks[i] {
+			if br == nil {
+				continue
+			}
+			s.Bricks[i][j] = &BrickSnapshot{
+				Row: br.row, Col: br.col, Cleared: br.cleared,
+				Kind: int(br.kind), Hits: br.hits, Color: br.color,
+			}
+		}
+	}
+	for _, c := range b.capsules {
+		s.Capsules = append(s.Capsules, CapsuleSnapshot{X: c.x, Y: c.y, Kind: int(c.kind)})
+	}
+	for _, l := range b.lasers {
+		s.Lasers = append(s.Lasers, LaserSnapshot{X: l.x, Y: l.y})
+	}
+	return s
+}
+
+// Restore replaces the state of the game with the snapshot. A zero Breakout
+// can be restored, so new(Breakout).Restore(s) creates a game from a
+// snapshot.
+func (b *Breakout) Restore(s Snapshot) error {
+	if s.Version != SNAPSHOT_VERSION {
+		return fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+	cfg := s.Config.WithDefaults()
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid snapshot config: %w", err)
+	}
+	if len(s.Balls) == 0 {
+		return fmt.Errorf("snapshot without balls")
+	}
+	if len(s.Bricks) != cfg.BrickRows {
+		return fmt.Errorf("snapshot with %d brick rows, config has %d", len(s.Bricks), cfg.BrickRows)
+	}
+	if len(s.Effects) > int(numPowerUps) {
+		return fmt.Errorf("snapshot with %d power-up effects", len(s.Effects))
+	}
+	// the lives left, MAX_LIVES+1-live, run from MAX_LIVES down to 0
+	if s.Live < 1 || s.Live > MAX_LIVES+1 {
+		return fmt.Errorf("snapshot with live %d, expected 1 to %d", s.Live, MAX_LIVES+1)
+	}
+	if s.Level < 1 {
+		return fmt.Errorf("snapshot with level %d", s.Level)
+	}
+	if s.Paddle.Width <= 0 || s.Paddle.Height <= 0 {
+		return fmt.Errorf("snapshot with paddle size %dx%d", s.Paddle.Width, s.Paddle.Height)
+	}
+	src := &rand.PCG{}
+	if err := src.UnmarshalBinary(s.RNG); err != nil {
+		return fmt.Errorf("invalid snapshot random source: %w", err)
+	}
+
+	bricks := make([][]*Brick, len(s.Bricks))
+	for i := range s.Bricks {
+		if len(s.Bricks[i]) != cfg.BricksPerRow {
+			return fmt.Errorf("snapshot with %d bricks in row %d, config has %d", len(s.Bricks[i]), i, cfg.BricksPerRow)
+		}
+		bricks[i] = make([]*Brick, len(s.Bricks[i]))
+		for j, bs := range s.Bricks[i] {
+			if bs == nil {
+				continue
+			}
+			if bs.Row != i || bs.Col != j {
+				return fmt.Errorf("snapshot with brick %d,%d in slot %d,%d", bs.Row, bs.Col, i, j)
+			}
+			if bs.Kind < 0 || bs.Kind >= int(numBrickKinds) || bs.Hits < 0 {
+				return fmt.Errorf("snapshot with brick kind %d and %d hits in row %d", bs.Kind, bs.Hits, i)
+			}
+			br := NewBrick(&cfg, bs.Row, bs.Col)
+			br.cleared = bs.Cleared
+			br.kind = BrickKind(bs.Kind)
+			br.hits = bs.Hits
+			br.color = bs.Color
+			bricks[i][j] = br
+		}
+	}
+	balls := make([]*Ball, 0, len(s.Balls))
+	for _, bs := range s.Balls {
+		if bs.Radius <= 0 || !(bs.Speed > 0) {
+			return fmt.Errorf("snapshot with ball radius %d and speed %g", bs.Radius, bs.Speed)
+		}
+		balls = append(balls, &Ball{
+			x: bs.X, y: bs.Y, radius: bs.Radius, dir: bs.Dir, speed: bs.Speed,
+			v_x: bs.VX, v_y: bs.VY, held: bs.Held, heldOffset: bs.HeldOffset, cfg: &cfg,
+		})
+	}
+	var capsules []*Capsule
+	for _, cs := range s.Capsules {
+		if cs.Kind < 0 || cs.Kind >= int(numPowerUps) {
+			return fmt.Errorf("snapshot with capsule kind %d", cs.Kind)
+		}
+		capsules = append(capsules, &Capsule{x: cs.X, y: cs.Y, kind: PowerUpKind(cs.Kind)})
+	}
+	var lasers []*Laser
+	for _, ls := range s.Lasers {
+		lasers = append(lasers, &Laser{x: ls.X, y: ls.Y})
+	}
+
+	*b = Breakout{
+		balls:       balls,
+		bricks:      bricks,
+		score:       s.Score,
+		level:       s.Level,
+		live:        s.Live,
+		paddle:      &Paddle{x: s.Paddle.X, width: s.Paddle.Width, height: s.Paddle.Height, cfg: &cfg},
+		frameReward: s.FrameReward,
+		cfg:         &cfg,
+		arcade: arcadeState{
+			hits:    s.Arcade.Hits,
+			orange:  s.Arcade.Orange,
+			red:     s.Arcade.Red,
+			ceiling: s.Arcade.Ceiling,
+		},
+		capsules: capsules,
+		lasers:   lasers,
+		seed:     s.Seed,
+		src:      src,
+		rng:      rand.New(src),
+		gameOver: s.GameOver,
+		won:      s.Won,
+
+		listeners:    b.listeners,
+		nextListener: b.nextListener,
+	}
+	copy(b.effects[:], s.Effects)
+	return nil
+}
+
+// Clone returns a deep copy of the game that plays on independently of it.
+// The copy shares the (read only) configuration with the original.
+func (b *Breakout) Clone() *Breakout {
+	c := *b
+	src := *b.src
+	c.src = &src
+	c.rng = rand.New(c.src)
+	c.balls = make([]*Ball, len(b.balls))
+	for i, bl := range b.balls {
+		nb := *bl
+		c.balls[i] = &nb
+	}
+	paddle := *b.paddle
+	c.paddle = &paddle
+	c.bricks = make([][]*Brick, len(b.bricks))
+	for i := range b.bricks {
+		c.bricks[i] = make([]*Brick, len(b.bricks[i]))
+		for j, br := range b.bricks[i] {
+			if br != nil {
+				nb := *br
+				c.bricks[i][j] = &nb
+			}
+		}
+	}
+	c.capsules = make([]*Capsule, len(b.capsules))
+	for i, cp := range b.capsules {
+		nc := *cp
+		c.capsules[i] = &nc
+	}
+	c.lasers = make([]*Laser, len(b.lasers))
+	for i, l := range b.lasers {
+		nl := *l
+		c.lasers[i] = &nl
+	}
+	c.events = b.Events()
+	c.listeners = nil
+	return &c
+}
@@ -0,0 +1,148 @@
+package breakout
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// playFrames plays n frames with the paddle following the first ball
+func playFrames(b *Breakout, n int) {
+	for range n {
+		if b.balls[0].GetX() < b.paddle.GetX()+b.paddle.GetWidth()/2 {
+			b.PaddleLeft()
+		} else {
+			b.PaddleRight()
+		}
+		b.MoveBall()
+	}
+}
+
+func newSnapshotGame() *Breakout {
+	cfg := DefaultConfig()
+	cfg.ArcadeRules = true
+	cfg.PowerUps = true
+	cfg.PowerUpChance = 0.5
+	b := NewBreakout(cfg, 7)
+	playFrames(b, 500)
+	return b
+}
+
+func TestSnapshotRestorePlaysOnIdentically(t *testing.T) {
+	b := newSnapshotGame()
+
+	data, err := json.Marshal(b.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected snapshot to marshal, got %v", err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("Expected snapshot to unmarshal, got %v", err)
+	}
+	restored := new(Breakout)
+	if err := restored.Restore(s); err != nil {
+		t.Fatalf("Expected snapshot to restore, got %v", err)
+	}
+
+	if !reflect.DeepEqual(b.GetState(), restored.GetState()) {
+		t.Fatal("Expected restored game to have the same state")
+	}
+	for frame := range 1000 {
+		playFrames(b, 1)
+		playFrames(restored, 1)
+		if !reflect.DeepEqual(b.GetState(), restored.GetState()) {
+			t.Fatalf("Expected restored game to play on identically, differs at frame %d", frame)
+		}
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	b := newSnapshotGame()
+	s := b.Snapshot()
+
+	restored := new(Breakout)
+	if err := restored.Restore(s); err != nil {
+		t.Fatalf("Expected snapshot to restore, got %v", err)
+	}
+	if !reflect.DeepEqual(s, restored.Snapshot()) {
+		t.Error("Expected snapshot of the restored game to equal the snapshot")
+	}
+}
+
+func TestSnapshotKeepsClearedBricks(t *testing.T) {
+	b := NewBreakout(DefaultConfig(), 1)
+	b.bricks[0][3].SetCleared(true)
+
+	s := b.Snapshot()
+	b.bricks[0][3].SetCleared(false)
+
+	restored := new(Breakout)
+	if err := restored.Restore(s); err != nil {
+		t.Fatalf("Expected snapshot to restore, got %v", err)
+	}
+	if !restored.bricks[0][3].IsCleared() {
+		t.Error("Expected cleared brick to stay cleared")
+	}
+}
+
+func TestRestoreRejectsInvalidSnapshot(t *testing.T) {
+	valid := NewBreakout(DefaultConfig(), 1).Snapshot()
+
+	tests := map[string]func(s *Snapshot){
+		"version":    func(s *Snapshot) { s.Version = SNAPSHOT_VERSION + 1 },
+		"no balls":   func(s *Snapshot) { s.Balls = nil },
+		"brick rows": func(s *Snapshot) { s.Bricks = s.Bricks[1:] },
+		"rng":        func(s *Snapshot) { s.RNG = []byte("broken") },
+		"config":     func(s *Snapshot) { s.Config.PaddleWidth = -1 },
+		"live":       func(s *Snapshot) { s.Live = MAX_LIVES + 2 },
+		"no live":    func(s *Snapshot) { s.Live = 0 },
+		"level":      func(s *Snapshot) { s.Level = 0 },
+		"paddle":     func(s *Snapshot) { s.Paddle.Width = 0 },
+		"ball":       func(s *Snapshot) { s.Balls[0].Radius = 0 },
+		"ball speed": func(s *Snapshot) { s.Balls[0].Speed = math.NaN() },
+		"brick kind": func(s *Snapshot) { s.Bricks[0][0].Kind = int(numBrickKinds) },
+		"brick slot": func(s *Snapshot) { s.Bricks[0][0].Col = 1 },
+		"brick grid": func(s *Snapshot) { s.Bricks[0][0].Row = BRICK_ROWS },
+		"capsule":    func(s *Snapshot) { s.Capsules = []CapsuleSnapshot{{Kind: int(numPowerUps)}} },
+	}
+	for name, mutate := range tests {
+		s := valid
+		s.Bricks = append([][]*BrickSnapshot(nil), valid.Bricks...)
+		s.Bricks[0] = append([]*BrickSnapshot(nil), valid.Bricks[0]...)
+		brick := *valid.Bricks[0][0]
+		s.Bricks[0][0] = &brick
+		s.Balls = append([]BallSnapshot(nil), valid.Balls...)
+		mutate(&s)
+		if err := new(Breakout).Restore(s); err == nil {
+			t.Errorf("Expected error for invalid %s", name)
+		}
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	b := newSnapshotGame()
+	before := b.GetState()
+
+	c := b.Clone()
+	playFrames(c, 300)
+	c.PaddleLeft()
+	c.bricks[1][1].SetCleared(true)
+
+	if !reflect.DeepEqual(before, b.GetState()) {
+		t.Error("Expected original game to be unchanged by playing the clone")
+	}
+}
+
+func TestClonePlaysOnIdentically(t *testing.T) {
+	b := newSnapshotGame()
+	c := b.Clone()
+
+	for frame := range 1000 {
+		playFrames(b, 1)
+		playFrames(c, 1)
+		if !reflect.DeepEqual(b.GetState(), c.GetState()) {
+			t.Fatalf("Expected clone to play on identically, differs at frame %d", frame)
+		}
+	}
+}
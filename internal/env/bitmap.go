@@ -0,0 +1,155 @@
+// Package env provides the bitmap observation of the game state.
+//
+// Functions:
+// - BreakoutState2Bitmap: Draws the game state into a scaled down bitmap.
+// - DrawBitmap: Draws the game state into a bitmap of any resolution.
+// - BitmapObservation: Flattens a bitmap into an Observation.
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"math"
+)
+
+// BITMAP_FACTOR is the scale down of the bitmap of BreakoutState2Bitmap
+const BITMAP_FACTOR = 3
+
+// Values of the bitmap cells
+const (
+	CellEmpty = iota
+	CellYellow
+	CellGreen
+	CellOrange
+	CellRed
+	CellPaddle
+	CellBall
+	CellMultiHit
+	CellIndestructible
+	CellExplosive
+	CellCapsule
+	CellLaser
+	numCells
+)
+
+// BreakoutState2Bitmap converts the state of a Breakout game into a 2D bitmap representation.
+// Each element in the bitmap corresponds to a specific part of the game:
+// - 0: Empty space
+// - 1: Yellow brick
+// - 2: Green brick
+// - 3: Orange brick
+// - 4: Red brick
+// - 5: Paddle
+// - 6: Ball
+// - 7: Multi-hit brick
+// - 8: Indestructible brick
+// - 9: Explosive brick
+// - 10: Power-up capsule
+// - 11: Laser shot
+//
+// The function scales down the game state by BITMAP_FACTOR, so the bitmap
+// size follows the configured game area, see DrawBitmap.
+//
+// Parameters:
+// - state: A pointer to a BreakoutState struct containing the current game state.
+//
+// Returns:
+//   - A 2D slice of integers representing the bitmap of the game state.
+//     size of the slice is (Height/3)x(Width/3), 80x60 (height x width) for the
+//     default game area - compression factor 3
+func BreakoutState2Bitmap(state *breakout.BreakoutState) [][]int {
+	return DrawBitmap(state, state.Height/BITMAP_FACTOR, state.Width/BITMAP_FACTOR)
+}
+
+// DrawBitmap draws the game state into a bitmap of height x width cells with
+// the values of BreakoutState2Bitmap. An object covers the cells between its
+// rounded edges, but at least the cell of its center, so small objects like
+// the ball or a laser shot never vanish at low resolutions.
+func DrawBitmap(state *breakout.BreakoutState, height, width int) [][]int {
+	bitmap := make([][]int, height)
+	for i := range bitmap {
+		bitmap[i] = make([]int, width)
+	}
+	drawCells(state, height, width, func(y, x, cell int) {
+		bitmap[y][x] = cell
+	})
+	return bitmap
+}
+
+// drawCells draws the objects of the game state onto a grid of height x
+// width cells by calling set for every covered cell. Later objects are
+// drawn over earlier ones, the balls last.
+func drawCells(state *breakout.BreakoutState, height, width int, set func(y, x, cell int)) {
+	colormap := map[string]int{
+		"red":    CellRed,
+		"orange": CellOrange,
+		"green":  CellGreen,
+		"yellow": CellYellow,
+	}
+	// special brick kinds are shown by kind instead of color
+	kindmap := map[string]int{
+		"multi":          CellMultiHit,
+		"indestructible": CellIndestructible,
+		"explosive":      CellExplosive,
+	}
+	scaleX := float64(width) / float64(state.Width)
+	scaleY := float64(height) / float64(state.Height)
+	fill := func(x, y, w, h, cell int) {
+		x0, x1 := span(x, x+w, scaleX, width)
+		y0, y1 := span(y, y+h, scaleY, height)
+		for j := y0; j < y1; j++ {
+			for i := x0; i < x1; i++ {
+				set(j, i, cell)
+			}
+		}
+	}
+
+	// Draw the bricks
+	for _, brick := range state.Bricks {
+		cell := colormap[brick.Color]
+		if val, ok := kindmap[brick.Kind]; ok {
+			cell = val
+		}
+		fill(brick.X, brick.Y, brick.Width, brick.Height, cell)
+	}
+	// Draw the paddle
+	fill(state.PaddleX, state.Height-state.PaddleHeight, state.PaddleWidth, state.PaddleHeight, CellPaddle)
+	// Draw the power-up capsules and laser shots
+	for _, capsule := range state.Capsules {
+		fill(capsule.X, capsule.Y, capsule.Width, capsule.Height, CellCapsule)
+	}
+	for _, laser := range state.Lasers {
+		fill(laser.X, laser.Y, 1, laser.Length, CellLaser)
+	}
+	// Draw the balls
+	for _, ball := range state.Balls {
+		fill(ball.X-ball.Radius, ball.Y-ball.Radius, 2*ball.Radius, 2*ball.Radius, CellBall)
+	}
+}
+
+// span returns the cells [lo, hi) of a grid of n cells covered by the
+// pixels [from, to) at the given scale, see DrawBitmap
+func span(from, to int, scale float64, n int) (int, int) {
+	lo := int(math.Round(float64(from) * scale))
+	hi := int(math.Round(float64(to) * scale))
+	if hi <= lo {
+		lo = int(math.Floor(float64(from+to) / 2 * scale))
+		hi = lo + 1
+	}
+	return max(lo, 0), min(hi, n)
+}
+
+// BitmapObservation flattens a bitmap into an observation of shape
+// [height, width]
+func BitmapObservation(bitmap [][]int) Observation {
+	h, w := len(bitmap), 0
+	if h > 0 {
+		w = len(bitmap[0])
+	}
+	data := make([]float32, 0, h*w)
+	for _, row := range bitmap {
+		for _, v := range row {
+			data = append(data, float32(v))
+		}
+	}
+	return Observation{Shape: []int{h, w}, Data: data}
+}
@@ -0,0 +1,54 @@
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"testing"
+)
+
+// countCells returns how many cells of the bitmap hold the value
+func countCells(bitmap [][]int, cell int) int {
+	n := 0
+	for _, row := range bitmap {
+		for _, v := range row {
+			if v == cell {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestBitmapDefaultSize(t *testing.T) {
+	state := breakout.NewBreakout(breakout.DefaultConfig(), 1).GetState()
+	bitmap := BreakoutState2Bitmap(&state)
+	if len(bitmap) != breakout.AREA_HEIGHT/BITMAP_FACTOR || len(bitmap[0]) != breakout.AREA_WIDTH/BITMAP_FACTOR {
+		t.Errorf("Expected a %dx%d bitmap, got %dx%d", breakout.AREA_HEIGHT/BITMAP_FACTOR, breakout.AREA_WIDTH/BITMAP_FACTOR, len(bitmap), len(bitmap[0]))
+	}
+}
+
+func TestBitmapSmallObjectsDoNotVanish(t *testing.T) {
+	state := breakout.NewBreakout(breakout.DefaultConfig(), 1).GetState()
+	state.Lasers = []breakout.LaserState{{X: 40, Y: 100, Length: 2}}
+	for _, size := range [][2]int{{80, 60}, {40, 30}, {21, 16}, {8, 8}} {
+		bitmap := DrawBitmap(&state, size[0], size[1])
+		for _, cell := range []int{CellBall, CellPaddle, CellLaser} {
+			if countCells(bitmap, cell) == 0 {
+				t.Errorf("Expected cell %d in the %dx%d bitmap", cell, size[0], size[1])
+			}
+		}
+	}
+}
+
+func TestBitmapPaddleRows(t *testing.T) {
+	state := breakout.NewBreakout(breakout.DefaultConfig(), 1).GetState()
+	bitmap := BreakoutState2Bitmap(&state)
+	// the paddle height 4 covers 4/3 rows, rounded to one row at the bottom
+	last := bitmap[len(bitmap)-1]
+	wantWidth := state.PaddleWidth / BITMAP_FACTOR
+	if n := countCells(bitmap, CellPaddle); n != wantWidth {
+		t.Errorf("Expected %d paddle cells, got %d", wantWidth, n)
+	}
+	if countCells([][]int{last}, CellPaddle) != wantWidth {
+		t.Error("Expected the paddle in the bottom row")
+	}
+}
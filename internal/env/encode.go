@@ -0,0 +1,178 @@
+// Package env provides compact binary encodings of observations, so
+// trainers can read them without JSON parsing.
+//
+// Two encodings are supported, both in row-major order and for any shape,
+// e.g. a single bitmap [H, W], stacked frames [K, H, W] or a batch of a
+// vectorized environment [N, H, W]:
+// - raw: One byte (uint8) per value, the shape is sent separately.
+// - npy: The NumPy .npy format (version 1.0) that carries its shape.
+//
+// The npy values are uint8 or little-endian float32, as given by the DType
+// of the Space of the observations.
+//
+// Functions:
+// - EncodeRaw, DecodeRaw: Encode and decode the raw format.
+// - EncodeNPY, DecodeNPY: Encode and decode the npy format.
+package env
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// npyMagic starts every .npy file
+const npyMagic = "\x93NUMPY"
+
+// EncodeRaw returns one byte per value of an observation of the given
+// dtype. It fails for dtypes other than DTYPE_UINT8.
+func EncodeRaw(obs Observation, dtype string) ([]byte, error) {
+	if dtype != DTYPE_UINT8 {
+		return nil, fmt.Errorf("observations of dtype %s do not fit into uint8", dtype)
+	}
+	data := make([]byte, len(obs.Data))
+	for i, v := range obs.Data {
+		data[i] = byte(v)
+	}
+	return data, nil
+}
+
+// DecodeRaw returns the observation of the given shape encoded by EncodeRaw
+func DecodeRaw(data []byte, shape []int) (Observation, error) {
+	if n := shapeSize(shape); n != len(data) {
+		return Observation{}, fmt.Errorf("%d bytes for shape %v of %d values", len(data), shape, n)
+	}
+	obs := Observation{Shape: append([]int(nil), shape...), Data: make([]float32, len(data))}
+	for i, v := range data {
+		obs.Data[i] = float32(v)
+	}
+	return obs, nil
+}
+
+// EncodeNPY returns the observation of the given dtype as a .npy file. It
+// fails for dtypes other than DTYPE_UINT8 and DTYPE_FLOAT32.
+func EncodeNPY(obs Observation, dtype string) ([]byte, error) {
+	var descr string
+	switch dtype {
+	case DTYPE_UINT8:
+		descr = "|u1"
+	case DTYPE_FLOAT32:
+		descr = "<f4"
+	default:
+		return nil, fmt.Errorf("unsupported dtype %q", dtype)
+	}
+	dims := make([]string, len(obs.Shape))
+	for i, d := range obs.Shape {
+		dims[i] = strconv.Itoa(d)
+	}
+	shape := strings.Join(dims, ", ")
+	if len(dims) == 1 {
+		shape += ","
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, shape)
+	// magic, version and header length take 10 bytes, the data starts at a
+	// multiple of 64 after the header padded with spaces and a newline
+	pad := 64 - (10+len(header)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	header += strings.Repeat(" ", pad) + "\n"
+
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{1, 0})
+	binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	if descr == "|u1" {
+		for _, v := range obs.Data {
+			buf.WriteByte(byte(v))
+		}
+	} else {
+		for _, v := range obs.Data {
+			binary.Write(&buf, binary.LittleEndian, math.Float32bits(v))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeNPY returns the observation of a .npy file with uint8 or float32
+// values in C order, e.g. written by EncodeNPY or numpy.save
+func DecodeNPY(data []byte) (Observation, error) {
+	if len(data) < 10 || string(data[:6]) != npyMagic {
+		return Observation{}, fmt.Errorf("not a .npy file")
+	}
+	if data[6] != 1 {
+		return Observation{}, fmt.Errorf("unsupported .npy version %d.%d", data[6], data[7])
+	}
+	n := int(binary.LittleEndian.Uint16(data[8:10]))
+	if len(data) < 10+n {
+		return Observation{}, fmt.Errorf("truncated .npy header")
+	}
+	header, body := string(data[10:10+n]), data[10+n:]
+	if !strings.Contains(header, "'fortran_order': False") {
+		return Observation{}, fmt.Errorf("unsupported .npy order")
+	}
+	shape, err := npyShape(header)
+	if err != nil {
+		return Observation{}, err
+	}
+	size := shapeSize(shape)
+	obs := Observation{Shape: shape, Data: make([]float32, size)}
+	switch {
+	case strings.Contains(header, "'descr': '|u1'"):
+		if len(body) != size {
+			return Observation{}, fmt.Errorf("%d bytes of data for %d values", len(body), size)
+		}
+		for i, v := range body {
+			obs.Data[i] = float32(v)
+		}
+	case strings.Contains(header, "'descr': '<f4'"):
+		if len(body) != 4*size {
+			return Observation{}, fmt.Errorf("%d bytes of data for %d values", len(body), size)
+		}
+		for i := range obs.Data {
+			obs.Data[i] = math.Float32frombits(binary.LittleEndian.Uint32(body[4*i:]))
+		}
+	default:
+		return Observation{}, fmt.Errorf("unsupported .npy type in %q", header)
+	}
+	return obs, nil
+}
+
+// npyShape parses the shape tuple of a .npy header
+func npyShape(header string) ([]int, error) {
+	i := strings.Index(header, "'shape': (")
+	if i < 0 {
+		return nil, fmt.Errorf("missing .npy shape")
+	}
+	rest := header[i+len("'shape': ("):]
+	j := strings.Index(rest, ")")
+	if j < 0 {
+		return nil, fmt.Errorf("invalid .npy shape")
+	}
+	shape := []int{}
+	for _, dim := range strings.Split(rest[:j], ",") {
+		dim = strings.TrimSpace(dim)
+		if dim == "" {
+			continue
+		}
+		d, err := strconv.Atoi(dim)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid .npy dimension %q", dim)
+		}
+		shape = append(shape, d)
+	}
+	return shape, nil
+}
+
+// shapeSize returns the number of values of an array of the given shape
+func shapeSize(shape []int) int {
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	return n
+}
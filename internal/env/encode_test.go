@@ -0,0 +1,88 @@
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeRawRoundTrip(t *testing.T) {
+	e := NewBreakoutEnv(breakout.DefaultConfig(), Options{})
+	obs := e.Reset(1)
+	data, err := EncodeRaw(obs, e.Space().DType)
+	if err != nil {
+		t.Fatalf("Expected bitmap to encode, got %v", err)
+	}
+	if len(data) != len(obs.Data) {
+		t.Errorf("Expected one byte per value, got %d bytes for %d values", len(data), len(obs.Data))
+	}
+	decoded, err := DecodeRaw(data, obs.Shape)
+	if err != nil || !reflect.DeepEqual(decoded, obs) {
+		t.Errorf("Expected raw round trip to keep the observation, got %v", err)
+	}
+	if _, err := DecodeRaw(data, []int{2, 2}); err == nil {
+		t.Error("Expected error for a wrong shape")
+	}
+}
+
+func TestEncodeRawRejectsFloats(t *testing.T) {
+	// integer values of a float32 space are still float32
+	if _, err := EncodeRaw(Observation{Shape: []int{2}, Data: []float32{0, 1}}, DTYPE_FLOAT32); err == nil {
+		t.Error("Expected error for a float32 observation")
+	}
+}
+
+func TestEncodeNPYRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		obs   Observation
+		dtype string
+		descr string
+	}{
+		"uint8 batch":     {Observation{Shape: []int{2, 2, 3}, Data: []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 255}}, DTYPE_UINT8, "|u1"},
+		"float32":         {Observation{Shape: []int{3}, Data: []float32{-1.5, 0.25, 3}}, DTYPE_FLOAT32, "<f4"},
+		"1d uint8":        {Observation{Shape: []int{1}, Data: []float32{7}}, DTYPE_UINT8, "|u1"},
+		"integer float32": {Observation{Shape: []int{2}, Data: []float32{0, 1}}, DTYPE_FLOAT32, "<f4"},
+	}
+	for name, tt := range tests {
+		obs := tt.obs
+		data, err := EncodeNPY(obs, tt.dtype)
+		if err != nil {
+			t.Errorf("%s: Expected encode to succeed, got %v", name, err)
+			continue
+		}
+		if !strings.Contains(string(data), "'descr': '"+tt.descr+"'") {
+			t.Errorf("%s: Expected type %s in the header", name, tt.descr)
+		}
+		n := int(data[8]) | int(data[9])<<8
+		if (10+n)%64 != 0 {
+			t.Errorf("%s: Expected the data to start at a multiple of 64, got %d", name, 10+n)
+		}
+		decoded, err := DecodeNPY(data)
+		if err != nil {
+			t.Errorf("%s: Expected decode to succeed, got %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, obs) {
+			t.Errorf("%s: Expected %v, got %v", name, obs, decoded)
+		}
+	}
+}
+
+func TestEncodeNPYHeader(t *testing.T) {
+	data, err := EncodeNPY(Observation{Shape: []int{2, 3}, Data: make([]float32, 6)}, DTYPE_UINT8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := string(data[10 : len(data)-6])
+	want := "{'descr': '|u1', 'fortran_order': False, 'shape': (2, 3), }"
+	if header[:len(want)] != want {
+		t.Errorf("Expected header %q, got %q", want, header)
+	}
+	if _, err := EncodeNPY(Observation{Shape: []int{1}, Data: []float32{1}}, "int64"); err == nil {
+		t.Error("Expected error for an unsupported dtype")
+	}
+	if _, err := DecodeNPY([]byte("not numpy")); err == nil {
+		t.Error("Expected error for data without the .npy magic")
+	}
+}
@@ -0,0 +1,163 @@
+// Package env provides a Gym-style reinforcement learning environment on top
+// of the breakout game.
+//
+// An episode starts with Reset and advances one frame per Step. Step reports
+// the reward of the frame and separates the two ways an episode ends: it is
+// terminated when the game is over (all lives lost or all levels won) and
+// truncated when it reaches the step limit of the environment.
+//
+// Types:
+// - Env: The environment interface.
+// - Action: The input of one step.
+// - Observation: What the agent sees, a flat array with its shape.
+// - Info: Lives, level, score and frame count of the episode.
+// - Options: The step limit, observation mode and reward of a BreakoutEnv.
+// - BreakoutEnv: The Env playing a breakout.Breakout game.
+//
+// Functions:
+// - NewBreakoutEnv: Creates an environment for a game configuration.
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"fmt"
+	"time"
+)
+
+type Env interface {
+	// Reset starts a new episode from the seed, 0 for a random seed, and
+	// returns its first observation
+	Reset(seed int64) Observation
+	// Step applies the action, advances the game by one frame and returns
+	// the observation, the reward, whether the episode is terminated or
+	// truncated, and the episode info
+	Step(action Action) (Observation, float64, bool, bool, Info)
+	// Space describes the observations
+	Space() Space
+}
+
+// Action is the input of one step
+type Action int
+
+const (
+	ActionNoop Action = iota
+	ActionLeft
+	ActionRight
+	numActions
+)
+
+// NUM_ACTIONS is the number of actions, valid actions are 0 to NUM_ACTIONS-1
+const NUM_ACTIONS = int(numActions)
+
+// Valid returns true for ActionNoop, ActionLeft and ActionRight
+func (a Action) Valid() bool {
+	return a >= 0 && a < numActions
+}
+
+// String returns the name of the action
+func (a Action) String() string {
+	switch a {
+	case ActionNoop:
+		return "noop"
+	case ActionLeft:
+		return "left"
+	case ActionRight:
+		return "right"
+	default:
+		return fmt.Sprintf("action(%d)", int(a))
+	}
+}
+
+type Observation struct {
+	Shape []int     `json:"shape"` // dimensions, e.g. [height, width] of a bitmap
+	Data  []float32 `json:"data"`  // values in row-major order
+}
+
+type Info struct {
+	Lives int `json:"lives"` // lives left
+	Level int `json:"level"` // current level
+	Score int `json:"score"` // current score
+	Frame int `json:"frame"` // frames played in the episode
+}
+
+type Options struct {
+	MaxSteps    int        // steps after which an episode is truncated, 0 for no limit
+	Observation ObsOptions // observation mode, see NewObserver
+	Reward      RewardFunc // reward of a frame, nil for ScoreReward
+}
+
+type BreakoutEnv struct {
+	cfg      breakout.Config
+	opts     Options
+	observer Observer
+	reward   RewardFunc
+	game     *breakout.Breakout
+	frame    int // frames played in the episode
+}
+
+// NewBreakoutEnv creates an environment playing games with cfg. The first
+// episode starts with seed 1, call Reset to pick another one. Invalid
+// observation options fall back to their defaults, see NewObserver.
+func NewBreakoutEnv(cfg breakout.Config, opts Options) *BreakoutEnv {
+	e := &BreakoutEnv{cfg: cfg, opts: opts, observer: NewObserver(cfg, opts.Observation), reward: opts.Reward}
+	if e.reward == nil {
+		e.reward = ScoreReward
+	}
+	e.Reset(1)
+	return e
+}
+
+// Reset starts a new episode, see Env
+func (e *BreakoutEnv) Reset(seed int64) Observation {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	e.game = breakout.NewBreakout(e.cfg, seed)
+	e.frame = 0
+	return e.observe()
+}
+
+// Step advances the episode by one frame, see Env. An invalid action is
+// played as ActionNoop. The reward function of the options rates the
+// record of the frame, by default the reward is the score gained.
+func (e *BreakoutEnv) Step(action Action) (Observation, float64, bool, bool, Info) {
+	switch action {
+	case ActionLeft:
+		e.game.PaddleLeft()
+	case ActionRight:
+		e.game.PaddleRight()
+	}
+	e.game.MoveBall()
+	e.frame++
+	rec := e.game.LastFrame()
+	terminated := e.game.GetState().Done
+	truncated := !terminated && e.opts.MaxSteps > 0 && e.frame >= e.opts.MaxSteps
+	return e.observe(), e.reward(&rec), terminated, truncated, e.Info()
+}
+
+// Space describes the observations, see Env
+func (e *BreakoutEnv) Space() Space {
+	return e.observer.Space()
+}
+
+// Info returns the info of the current episode
+func (e *BreakoutEnv) Info() Info {
+	state := e.game.GetState()
+	return Info{Lives: e.game.Lives(), Level: state.Level, Score: state.Score, Frame: e.frame}
+}
+
+// State returns the state of the game
+func (e *BreakoutEnv) State() breakout.BreakoutState {
+	return e.game.GetState()
+}
+
+// Game returns the game of the current episode
+func (e *BreakoutEnv) Game() *breakout.Breakout {
+	return e.game
+}
+
+// observe returns the observation of the current frame
+func (e *BreakoutEnv) observe() Observation {
+	state := e.game.GetState()
+	return e.observer.Observe(&state)
+}
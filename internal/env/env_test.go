@@ -0,0 +1,84 @@
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"reflect"
+	"testing"
+)
+
+func TestResetSameSeedSameObservation(t *testing.T) {
+	e := NewBreakoutEnv(breakout.DefaultConfig(), Options{})
+	first := e.Reset(42)
+	for range 30 {
+		e.Step(ActionLeft)
+	}
+	if again := e.Reset(42); !reflect.DeepEqual(first, again) {
+		t.Error("Expected the same first observation for the same seed")
+	}
+	if info := e.Info(); info.Frame != 0 || info.Lives != breakout.MAX_LIVES || info.Level != 1 {
+		t.Errorf("Expected a fresh episode after reset, got %+v", info)
+	}
+}
+
+func TestObservationShape(t *testing.T) {
+	e := NewBreakoutEnv(breakout.DefaultConfig(), Options{})
+	obs := e.Reset(1)
+	want := []int{breakout.AREA_HEIGHT / 3, breakout.AREA_WIDTH / 3}
+	if !reflect.DeepEqual(obs.Shape, want) {
+		t.Errorf("Expected shape %v, got %v", want, obs.Shape)
+	}
+	if len(obs.Data) != want[0]*want[1] {
+		t.Errorf("Expected %d values, got %d", want[0]*want[1], len(obs.Data))
+	}
+}
+
+func TestStepInfoAndReward(t *testing.T) {
+	e := NewBreakoutEnv(breakout.DefaultConfig(), Options{})
+	e.Reset(1)
+	total := 0.0
+	for i := range 2000 {
+		_, reward, terminated, truncated, info := e.Step(ActionNoop)
+		total += reward
+		if info.Frame != i+1 {
+			t.Fatalf("Expected frame %d, got %d", i+1, info.Frame)
+		}
+		if truncated {
+			t.Fatal("Expected no truncation without a step limit")
+		}
+		if int(total) != info.Score {
+			t.Fatalf("Expected the rewards to add up to the score %d, got %f", info.Score, total)
+		}
+		if terminated {
+			if info.Lives != 0 {
+				t.Errorf("Expected no lives left at the end, got %d", info.Lives)
+			}
+			return
+		}
+	}
+	t.Error("Expected the episode to terminate without moving the paddle")
+}
+
+func TestStepTruncatesAtMaxSteps(t *testing.T) {
+	e := NewBreakoutEnv(breakout.DefaultConfig(), Options{MaxSteps: 5})
+	e.Reset(1)
+	for i := range 5 {
+		_, _, terminated, truncated, _ := e.Step(ActionRight)
+		if terminated {
+			t.Fatal("Expected no termination within 5 steps")
+		}
+		if truncated != (i == 4) {
+			t.Errorf("Expected truncation only at step 5, got %v at step %d", truncated, i+1)
+		}
+	}
+}
+
+func TestActionValid(t *testing.T) {
+	for a := range Action(NUM_ACTIONS) {
+		if !a.Valid() {
+			t.Errorf("Expected %s to be valid", a)
+		}
+	}
+	if Action(-1).Valid() || Action(NUM_ACTIONS).Valid() {
+		t.Error("Expected actions outside 0 to NUM_ACTIONS-1 to be invalid")
+	}
+}
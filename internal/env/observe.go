@@ -0,0 +1,203 @@
+// Package env provides the observation modes of the breakout environment.
+//
+// Every mode turns a game state into an Observation and describes the
+// observations it returns by a Space:
+// - bitmap: The cell values of DrawBitmap at a configurable resolution.
+// - rgb: A full resolution RGB frame drawn with the bitmap palette.
+// - features: A compact feature vector of the ball, the paddle and the bricks.
+//
+// Types:
+// - ObsMode: The name of an observation mode.
+// - ObsOptions: The mode and bitmap resolution of an environment.
+// - Space: The shape and value range of observations.
+// - Observer: Turns game states into observations of one mode.
+//
+// Functions:
+// - NewObserver: Creates the observer for a game configuration and options.
+// - CellColor: Returns the RGB color of a bitmap cell value.
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"fmt"
+)
+
+// ObsMode is the name of an observation mode
+type ObsMode string
+
+const (
+	ObsBitmap   ObsMode = "bitmap"   // [height, width] cell values, see BreakoutState2Bitmap
+	ObsRGB      ObsMode = "rgb"      // [AreaHeight, AreaWidth, 3] colors
+	ObsFeatures ObsMode = "features" // [6 + BrickRows*BricksPerRow] features
+)
+
+// NUM_BALL_PADDLE_FEATURES is the number of features before the brick mask:
+// ball x, y, vx, vy, paddle x and width
+const NUM_BALL_PADDLE_FEATURES = 6
+
+type ObsOptions struct {
+	Mode   ObsMode `json:"mode"`   // observation mode, "" for ObsBitmap
+	Height int     `json:"height"` // bitmap rows, 0 for AreaHeight/BITMAP_FACTOR
+	Width  int     `json:"width"`  // bitmap columns, 0 for AreaWidth/BITMAP_FACTOR
+}
+
+// Validate returns an error for an unknown mode or a bitmap resolution that
+// is negative or larger than the game area of cfg
+func (o ObsOptions) Validate(cfg breakout.Config) error {
+	switch o.Mode {
+	case "", ObsBitmap, ObsRGB, ObsFeatures:
+	default:
+		return fmt.Errorf("unknown observation mode %q", o.Mode)
+	}
+	cfg = cfg.WithDefaults()
+	if o.Height < 0 || o.Height > cfg.AreaHeight {
+		return fmt.Errorf("invalid bitmap height %d, expected at most %d", o.Height, cfg.AreaHeight)
+	}
+	if o.Width < 0 || o.Width > cfg.AreaWidth {
+		return fmt.Errorf("invalid bitmap width %d, expected at most %d", o.Width, cfg.AreaWidth)
+	}
+	return nil
+}
+
+// Value types of a Space
+const (
+	DTYPE_UINT8   = "uint8"   // integers from 0 to 255
+	DTYPE_FLOAT32 = "float32" // any other values
+)
+
+type Space struct {
+	Shape []int   `json:"shape"` // dimensions of every observation
+	Low   float32 `json:"low"`   // smallest value
+	High  float32 `json:"high"`  // largest value
+	DType string  `json:"dtype"` // DTYPE_UINT8 or DTYPE_FLOAT32
+}
+
+type Observer interface {
+	// Space describes the observations
+	Space() Space
+	// Observe returns the observation of the state
+	Observe(state *breakout.BreakoutState) Observation
+}
+
+// NewObserver returns the observer of the mode in opts for games played
+// with cfg. Invalid options are replaced by their defaults, callers
+// accepting options from outside should check them with
+// ObsOptions.Validate first.
+func NewObserver(cfg breakout.Config, opts ObsOptions) Observer {
+	cfg = cfg.WithDefaults()
+	if opts.Validate(cfg) != nil {
+		opts = ObsOptions{Mode: opts.Mode}
+	}
+	switch opts.Mode {
+	case ObsRGB:
+		return rgbObserver{height: cfg.AreaHeight, width: cfg.AreaWidth}
+	case ObsFeatures:
+		return featureObserver{cfg: cfg}
+	}
+	if opts.Height == 0 {
+		opts.Height = cfg.AreaHeight / BITMAP_FACTOR
+	}
+	if opts.Width == 0 {
+		opts.Width = cfg.AreaWidth / BITMAP_FACTOR
+	}
+	return bitmapObserver{height: opts.Height, width: opts.Width}
+}
+
+// bitmapObserver observes the cell values of DrawBitmap
+type bitmapObserver struct {
+	height, width int
+}
+
+func (o bitmapObserver) Space() Space {
+	return Space{Shape: []int{o.height, o.width}, Low: 0, High: numCells - 1, DType: DTYPE_UINT8}
+}
+
+func (o bitmapObserver) Observe(state *breakout.BreakoutState) Observation {
+	return BitmapObservation(DrawBitmap(state, o.height, o.width))
+}
+
+// palette holds the RGB colors of the bitmap cell values, close to the
+// colors of the web client
+var palette = [numCells][3]uint8{
+	CellEmpty:          {0, 0, 0},
+	CellYellow:         {255, 255, 0},
+	CellGreen:          {0, 128, 0},
+	CellOrange:         {255, 165, 0},
+	CellRed:            {255, 0, 0},
+	CellPaddle:         {0, 0, 255},
+	CellBall:           {255, 255, 255},
+	CellMultiHit:       {192, 192, 192},
+	CellIndestructible: {105, 105, 105},
+	CellExplosive:      {128, 0, 128},
+	CellCapsule:        {0, 255, 255},
+	CellLaser:          {255, 99, 71},
+}
+
+// CellColor returns the RGB color of a bitmap cell value, black for values
+// out of range
+func CellColor(cell int) [3]uint8 {
+	if cell < 0 || cell >= numCells {
+		return palette[CellEmpty]
+	}
+	return palette[cell]
+}
+
+// rgbObserver observes the game area in full resolution
+type rgbObserver struct {
+	height, width int
+}
+
+func (o rgbObserver) Space() Space {
+	return Space{Shape: []int{o.height, o.width, 3}, Low: 0, High: 255, DType: DTYPE_UINT8}
+}
+
+func (o rgbObserver) Observe(state *breakout.BreakoutState) Observation {
+	data := make([]float32, o.height*o.width*3)
+	drawCells(state, o.height, o.width, func(y, x, cell int) {
+		i := (y*o.width + x) * 3
+		for c, v := range palette[cell] {
+			data[i+c] = float32(v)
+		}
+	})
+	return Observation{Shape: []int{o.height, o.width, 3}, Data: data}
+}
+
+// featureObserver observes the first ball, the paddle and the brick mask.
+// Positions and sizes are fractions of the game area, velocities fractions
+// of twice the configured ball speed (the arcade rules speed the ball up to
+// that) and the brick mask is 1 for every brick left at index
+// row*BricksPerRow+col, row 0 being the bottom row.
+type featureObserver struct {
+	cfg breakout.Config
+}
+
+func (o featureObserver) Space() Space {
+	n := NUM_BALL_PADDLE_FEATURES + o.cfg.BrickRows*o.cfg.BricksPerRow
+	return Space{Shape: []int{n}, Low: -1, High: 1, DType: DTYPE_FLOAT32}
+}
+
+func (o featureObserver) Observe(state *breakout.BreakoutState) Observation {
+	data := make([]float32, o.Space().Shape[0])
+	w, h := float64(o.cfg.AreaWidth), float64(o.cfg.AreaHeight)
+	if len(state.Balls) > 0 {
+		ball := state.Balls[0]
+		maxSpeed := 2 * o.cfg.BallSpeed
+		data[0] = clamp(float64(ball.X)/w, 0, 1)
+		data[1] = clamp(float64(ball.Y)/h, 0, 1)
+		data[2] = clamp(ball.VX/maxSpeed, -1, 1)
+		data[3] = clamp(ball.VY/maxSpeed, -1, 1)
+	}
+	data[4] = clamp(float64(state.PaddleX)/w, 0, 1)
+	data[5] = clamp(float64(state.PaddleWidth)/w, 0, 1)
+	for _, brick := range state.Bricks {
+		if brick.Row >= 0 && brick.Row < o.cfg.BrickRows && brick.Col >= 0 && brick.Col < o.cfg.BricksPerRow {
+			data[NUM_BALL_PADDLE_FEATURES+brick.Row*o.cfg.BricksPerRow+brick.Col] = 1
+		}
+	}
+	return Observation{Shape: []int{len(data)}, Data: data}
+}
+
+// clamp returns v limited to [lo, hi] as float32
+func clamp(v, lo, hi float64) float32 {
+	return float32(min(max(v, lo), hi))
+}
@@ -0,0 +1,99 @@
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"reflect"
+	"testing"
+)
+
+func TestObserverSpaces(t *testing.T) {
+	cfg := breakout.DefaultConfig()
+	state := breakout.NewBreakout(cfg, 1).GetState()
+	tests := []struct {
+		opts  ObsOptions
+		shape []int
+	}{
+		{ObsOptions{}, []int{breakout.AREA_HEIGHT / 3, breakout.AREA_WIDTH / 3}},
+		{ObsOptions{Mode: ObsBitmap, Height: 42, Width: 21}, []int{42, 21}},
+		{ObsOptions{Mode: ObsRGB}, []int{breakout.AREA_HEIGHT, breakout.AREA_WIDTH, 3}},
+		{ObsOptions{Mode: ObsFeatures}, []int{NUM_BALL_PADDLE_FEATURES + cfg.BrickRows*cfg.BricksPerRow}},
+	}
+	for _, test := range tests {
+		o := NewObserver(cfg, test.opts)
+		space := o.Space()
+		if !reflect.DeepEqual(space.Shape, test.shape) {
+			t.Errorf("Expected shape %v for %+v, got %v", test.shape, test.opts, space.Shape)
+		}
+		obs := o.Observe(&state)
+		if !reflect.DeepEqual(obs.Shape, space.Shape) || len(obs.Data) != shapeSize(space.Shape) {
+			t.Errorf("Expected an observation of shape %v for %+v, got %v", space.Shape, test.opts, obs.Shape)
+		}
+		for _, v := range obs.Data {
+			if v < space.Low || v > space.High {
+				t.Errorf("Expected values in [%v, %v] for %+v, got %v", space.Low, space.High, test.opts, v)
+				break
+			}
+		}
+	}
+}
+
+func TestObsOptionsValidate(t *testing.T) {
+	cfg := breakout.DefaultConfig()
+	for _, opts := range []ObsOptions{{Mode: "pixels"}, {Height: -1}, {Width: breakout.AREA_WIDTH + 1}} {
+		if opts.Validate(cfg) == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+	if err := (ObsOptions{Mode: ObsRGB}).Validate(cfg); err != nil {
+		t.Errorf("Expected valid options, got %v", err)
+	}
+}
+
+func TestRGBObserverColors(t *testing.T) {
+	cfg := breakout.DefaultConfig()
+	state := breakout.NewBreakout(cfg, 1).GetState()
+	obs := NewObserver(cfg, ObsOptions{Mode: ObsRGB}).Observe(&state)
+	pixel := func(y, x int) [3]float32 {
+		i := (y*cfg.AreaWidth + x) * 3
+		return [3]float32{obs.Data[i], obs.Data[i+1], obs.Data[i+2]}
+	}
+	if got := pixel(state.BallY, state.BallX); got != [3]float32{255, 255, 255} {
+		t.Errorf("Expected a white ball, got %v", got)
+	}
+	if got := pixel(cfg.AreaHeight-1, state.PaddleX+1); got != [3]float32{0, 0, 255} {
+		t.Errorf("Expected a blue paddle, got %v", got)
+	}
+}
+
+func TestFeatureObserver(t *testing.T) {
+	cfg := breakout.DefaultConfig()
+	game := breakout.NewBreakout(cfg, 1)
+	state := game.GetState()
+	o := NewObserver(cfg, ObsOptions{Mode: ObsFeatures})
+	obs := o.Observe(&state)
+	if want := float32(state.BallX) / float32(cfg.AreaWidth); obs.Data[0] != want {
+		t.Errorf("Expected ball x %v, got %v", want, obs.Data[0])
+	}
+	if want := float32(state.PaddleWidth) / float32(cfg.AreaWidth); obs.Data[5] != want {
+		t.Errorf("Expected paddle width %v, got %v", want, obs.Data[5])
+	}
+	if obs.Data[2] == 0 && obs.Data[3] == 0 {
+		t.Error("Expected the ball velocity")
+	}
+	bricks := 0
+	for _, v := range obs.Data[NUM_BALL_PADDLE_FEATURES:] {
+		bricks += int(v)
+	}
+	if bricks != len(state.Bricks) {
+		t.Errorf("Expected %d bricks in the mask, got %d", len(state.Bricks), bricks)
+	}
+}
+
+func TestCellColor(t *testing.T) {
+	if got := CellColor(CellPaddle); got != [3]uint8{0, 0, 255} {
+		t.Errorf("Expected a blue paddle, got %v", got)
+	}
+	if got := CellColor(numCells); got != CellColor(CellEmpty) {
+		t.Errorf("Expected the empty color out of range, got %v", got)
+	}
+}
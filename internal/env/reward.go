@@ -0,0 +1,97 @@
+// Package env provides the reward functions of the environment.
+//
+// A RewardFunc turns the record of a frame (breakout.FrameRecord) into the
+// reward of the frame. The built-in presets:
+// - score: The points scored, the default.
+// - clipped: 1 if points were scored, 0 otherwise, as in Atari training.
+// - survival: 1 per paddle hit and -1 per lost life, to keep the ball in play.
+// - shaped: 1 per cleared brick, a bonus per paddle hit and cleared level and a penalty per lost life.
+//
+// Types:
+// - RewardFunc: The reward of a frame.
+//
+// Functions:
+// - ScoreReward, ClippedReward, SurvivalReward, ShapedReward: The presets.
+// - RewardPreset: Returns a preset by name.
+// - RewardPresetNames: Returns the names of the presets.
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"fmt"
+	"slices"
+)
+
+// Weights of ShapedReward
+const (
+	SHAPED_PADDLE_HIT    = 0.1
+	SHAPED_LEVEL_CLEARED = 5.0
+	SHAPED_LIFE_LOST     = -1.0
+)
+
+// RewardFunc returns the reward of the frame described by the record
+type RewardFunc func(rec *breakout.FrameRecord) float64
+
+// ScoreReward returns the points scored in the frame
+func ScoreReward(rec *breakout.FrameRecord) float64 {
+	return float64(rec.Points())
+}
+
+// ClippedReward returns 1 if points were scored in the frame and 0 otherwise
+func ClippedReward(rec *breakout.FrameRecord) float64 {
+	if rec.Points() > 0 {
+		return 1
+	}
+	return 0
+}
+
+// SurvivalReward returns 1 per paddle hit and -1 for a lost life
+func SurvivalReward(rec *breakout.FrameRecord) float64 {
+	reward := float64(rec.PaddleHits)
+	if rec.LifeLost {
+		reward--
+	}
+	return reward
+}
+
+// ShapedReward returns 1 per cleared brick plus the SHAPED_ weights for
+// paddle hits, a cleared level and a lost life
+func ShapedReward(rec *breakout.FrameRecord) float64 {
+	reward := float64(len(rec.Cleared)) + SHAPED_PADDLE_HIT*float64(rec.PaddleHits)
+	if rec.LevelCleared {
+		reward += SHAPED_LEVEL_CLEARED
+	}
+	if rec.LifeLost {
+		reward += SHAPED_LIFE_LOST
+	}
+	return reward
+}
+
+var rewardPresets = map[string]RewardFunc{
+	"score":    ScoreReward,
+	"clipped":  ClippedReward,
+	"survival": SurvivalReward,
+	"shaped":   ShapedReward,
+}
+
+// RewardPreset returns the preset with the given name, "" for "score"
+func RewardPreset(name string) (RewardFunc, error) {
+	if name == "" {
+		return ScoreReward, nil
+	}
+	f, ok := rewardPresets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown reward preset %q, expected one of %v", name, RewardPresetNames())
+	}
+	return f, nil
+}
+
+// RewardPresetNames returns the sorted names of the presets
+func RewardPresetNames() []string {
+	names := make([]string, 0, len(rewardPresets))
+	for name := range rewardPresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
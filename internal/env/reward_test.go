@@ -0,0 +1,58 @@
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"testing"
+)
+
+func TestRewardPresets(t *testing.T) {
+	rec := &breakout.FrameRecord{
+		Cleared:      []breakout.ClearedBrick{{Points: 3}, {Points: 7}},
+		PaddleHits:   1,
+		LifeLost:     true,
+		LevelCleared: true,
+	}
+	tests := map[string]float64{
+		"":         10,
+		"score":    10,
+		"clipped":  1,
+		"survival": 0,
+		"shaped":   2 + SHAPED_PADDLE_HIT + SHAPED_LEVEL_CLEARED + SHAPED_LIFE_LOST,
+	}
+	for name, want := range tests {
+		f, err := RewardPreset(name)
+		if err != nil {
+			t.Fatalf("Expected preset %q, got %v", name, err)
+		}
+		if got := f(rec); got != want {
+			t.Errorf("Expected reward %v for %q, got %v", want, name, got)
+		}
+	}
+	if got := ClippedReward(&breakout.FrameRecord{}); got != 0 {
+		t.Errorf("Expected no clipped reward without points, got %v", got)
+	}
+	if _, err := RewardPreset("bonus"); err == nil {
+		t.Error("Expected error for an unknown preset")
+	}
+	if len(RewardPresetNames()) != 4 {
+		t.Errorf("Expected 4 presets, got %v", RewardPresetNames())
+	}
+}
+
+func TestEnvRewardFunc(t *testing.T) {
+	e := NewBreakoutEnv(breakout.DefaultConfig(), Options{Reward: SurvivalReward})
+	e.Reset(1)
+	lost := 0.0
+	for range 2000 {
+		_, reward, terminated, _, _ := e.Step(ActionNoop)
+		if reward < 0 {
+			lost -= reward
+		}
+		if terminated {
+			break
+		}
+	}
+	if lost != breakout.MAX_LIVES {
+		t.Errorf("Expected a penalty for each of the %d lives, got %v", breakout.MAX_LIVES, lost)
+	}
+}
@@ -0,0 +1,156 @@
+// Package env provides a vectorized environment that steps many
+// independent environments at once.
+//
+// A finished episode (terminated or truncated) is reset automatically
+// within the same step. Its slot then returns the first observation of the
+// new episode, while the reward, the flags and the info still describe the
+// step that ended the old episode.
+//
+// Types:
+// - VecEnv: N environments stepped together.
+// - VecStep: The outcome of one step of all environments.
+//
+// Functions:
+// - NewVecEnv: Creates a vectorized environment.
+// - Stack: Stacks observations of the same shape into one.
+package env
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type VecStep struct {
+	Observations []Observation // observations, of the new episode where one ended
+	Rewards      []float64     // rewards of the step
+	Terminated   []bool        // the game of the episode is over
+	Truncated    []bool        // the episode reached the step limit
+	Infos        []Info        // infos after the step, of the old episode where one ended
+}
+
+type VecEnv struct {
+	envs     []Env
+	workers  int     // goroutines stepping the environments, 1 for none
+	seed     int64   // base seed of the episode seeds
+	episodes []int64 // episodes started per environment
+}
+
+// NewVecEnv creates a vectorized environment of envs that are stepped by
+// up to workers goroutines (1 or less steps them one after another).
+func NewVecEnv(envs []Env, workers int) *VecEnv {
+	return &VecEnv{
+		envs:     envs,
+		workers:  max(workers, 1),
+		episodes: make([]int64, len(envs)),
+	}
+}
+
+// Len returns the number of environments
+func (v *VecEnv) Len() int {
+	return len(v.envs)
+}
+
+// Space describes the stacked observations of shape [N, ...], the
+// environments are expected to share their space
+func (v *VecEnv) Space() Space {
+	space := v.envs[0].Space()
+	space.Shape = append([]int{len(v.envs)}, space.Shape...)
+	return space
+}
+
+// Reset starts a new episode in every environment and returns their first
+// observations. The environments get distinct seeds derived from seed, so
+// a VecEnv replays exactly with the same seed; 0 picks a time based seed to
+// derive them from, so the environments still get distinct seeds.
+func (v *VecEnv) Reset(seed int64) []Observation {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	v.seed = seed
+	obs := make([]Observation, len(v.envs))
+	v.each(func(i int) {
+		v.episodes[i] = 0
+		obs[i] = v.envs[i].Reset(v.episodeSeed(i))
+	})
+	return obs
+}
+
+// Step applies one action to every environment and advances all of them by
+// one frame. Finished episodes are reset, see the package comment.
+func (v *VecEnv) Step(actions []Action) (VecStep, error) {
+	if len(actions) != len(v.envs) {
+		return VecStep{}, fmt.Errorf("got %d actions for %d environments", len(actions), len(v.envs))
+	}
+	for i, a := range actions {
+		if !a.Valid() {
+			return VecStep{}, fmt.Errorf("invalid action %d for environment %d", a, i)
+		}
+	}
+	n := len(v.envs)
+	s := VecStep{
+		Observations: make([]Observation, n),
+		Rewards:      make([]float64, n),
+		Terminated:   make([]bool, n),
+		Truncated:    make([]bool, n),
+		Infos:        make([]Info, n),
+	}
+	v.each(func(i int) {
+		s.Observations[i], s.Rewards[i], s.Terminated[i], s.Truncated[i], s.Infos[i] = v.envs[i].Step(actions[i])
+		if s.Terminated[i] || s.Truncated[i] {
+			v.episodes[i]++
+			s.Observations[i] = v.envs[i].Reset(v.episodeSeed(i))
+		}
+	})
+	return s, nil
+}
+
+// episodeSeed returns the seed of the current episode of environment i
+func (v *VecEnv) episodeSeed(i int) int64 {
+	return v.seed + int64(i) + v.episodes[i]*int64(len(v.envs))
+}
+
+// each calls f for every environment index, spread over the workers
+func (v *VecEnv) each(f func(i int)) {
+	n := len(v.envs)
+	workers := min(v.workers, n)
+	if workers <= 1 {
+		for i := range n {
+			f(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < n; i += workers {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Stack stacks observations of the same shape into one observation with a
+// leading dimension of len(obs), e.g. a batch of bitmaps to [N, H, W]
+func Stack(obs []Observation) (Observation, error) {
+	if len(obs) == 0 {
+		return Observation{Shape: []int{0}}, nil
+	}
+	shape := obs[0].Shape
+	data := make([]float32, 0, len(obs)*len(obs[0].Data))
+	for i, o := range obs {
+		if len(o.Shape) != len(shape) || len(o.Data) != len(obs[0].Data) {
+			return Observation{}, fmt.Errorf("observation %d has shape %v, expected %v", i, o.Shape, shape)
+		}
+		for j := range shape {
+			if o.Shape[j] != shape[j] {
+				return Observation{}, fmt.Errorf("observation %d has shape %v, expected %v", i, o.Shape, shape)
+			}
+		}
+		data = append(data, o.Data...)
+	}
+	return Observation{Shape: append([]int{len(obs)}, shape...), Data: data}, nil
+}
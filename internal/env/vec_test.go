@@ -0,0 +1,104 @@
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"reflect"
+	"testing"
+)
+
+func newTestVecEnv(n, workers int, opts Options) *VecEnv {
+	envs := make([]Env, n)
+	for i := range envs {
+		envs[i] = NewBreakoutEnv(breakout.DefaultConfig(), opts)
+	}
+	return NewVecEnv(envs, workers)
+}
+
+func TestVecEnvWorkersDoNotChangeResults(t *testing.T) {
+	seq := newTestVecEnv(6, 1, Options{})
+	par := newTestVecEnv(6, 4, Options{})
+	if !reflect.DeepEqual(seq.Reset(11), par.Reset(11)) {
+		t.Fatal("Expected the same first observations")
+	}
+	actions := []Action{ActionNoop, ActionLeft, ActionRight, ActionLeft, ActionNoop, ActionRight}
+	for range 200 {
+		a, err := seq.Step(actions)
+		if err != nil {
+			t.Fatalf("Expected step to succeed, got %v", err)
+		}
+		b, _ := par.Step(actions)
+		if !reflect.DeepEqual(a, b) {
+			t.Fatal("Expected the same results with and without workers")
+		}
+	}
+}
+
+func TestVecEnvDistinctSeeds(t *testing.T) {
+	v := newTestVecEnv(2, 1, Options{})
+	obs := v.Reset(5)
+	if reflect.DeepEqual(obs[0], obs[1]) {
+		t.Error("Expected the environments to start from different seeds")
+	}
+}
+
+func TestVecEnvDistinctRandomSeeds(t *testing.T) {
+	v := newTestVecEnv(8, 4, Options{})
+	v.Reset(0)
+	seeds := map[int64]bool{}
+	for _, e := range v.envs {
+		seeds[e.(*BreakoutEnv).Game().Seed()] = true
+	}
+	if len(seeds) != 8 {
+		t.Errorf("Expected 8 distinct seeds for seed 0, got %d", len(seeds))
+	}
+}
+
+func TestVecEnvAutoReset(t *testing.T) {
+	v := newTestVecEnv(2, 2, Options{MaxSteps: 3})
+	v.Reset(1)
+	actions := []Action{ActionLeft, ActionRight}
+	for range 2 {
+		v.Step(actions)
+	}
+	s, _ := v.Step(actions)
+	for i := range 2 {
+		if !s.Truncated[i] || s.Infos[i].Frame != 3 {
+			t.Errorf("Expected environment %d to be truncated at frame 3, got %v at %d", i, s.Truncated[i], s.Infos[i].Frame)
+		}
+	}
+	// the finished slot already holds the first observation of the next episode
+	fresh := NewBreakoutEnv(breakout.DefaultConfig(), Options{}).Reset(v.episodeSeed(0))
+	if !reflect.DeepEqual(s.Observations[0], fresh) {
+		t.Error("Expected the observation of the new episode")
+	}
+	s, _ = v.Step(actions)
+	if s.Infos[0].Frame != 1 || s.Truncated[0] {
+		t.Errorf("Expected the new episode at frame 1, got %d", s.Infos[0].Frame)
+	}
+}
+
+func TestVecEnvRejectsInvalidActions(t *testing.T) {
+	v := newTestVecEnv(2, 1, Options{})
+	v.Reset(1)
+	if _, err := v.Step([]Action{ActionNoop}); err == nil {
+		t.Error("Expected error for too few actions")
+	}
+	if _, err := v.Step([]Action{ActionNoop, Action(7)}); err == nil {
+		t.Error("Expected error for an invalid action")
+	}
+}
+
+func TestStack(t *testing.T) {
+	a := Observation{Shape: []int{2, 2}, Data: []float32{1, 2, 3, 4}}
+	b := Observation{Shape: []int{2, 2}, Data: []float32{5, 6, 7, 8}}
+	s, err := Stack([]Observation{a, b})
+	if err != nil {
+		t.Fatalf("Expected stack to succeed, got %v", err)
+	}
+	if !reflect.DeepEqual(s.Shape, []int{2, 2, 2}) || !reflect.DeepEqual(s.Data, []float32{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("Expected stacked [2 2 2] observation, got %v %v", s.Shape, s.Data)
+	}
+	if _, err := Stack([]Observation{a, {Shape: []int{4}, Data: a.Data}}); err == nil {
+		t.Error("Expected error for different shapes")
+	}
+}
@@ -0,0 +1,200 @@
+// Package env provides wrappers that change how an environment is played,
+// as in the standard Atari setups.
+//
+// Every wrapper is an Env around another Env, so they compose. Wrappers.Wrap
+// applies them in the usual order: sticky actions next to the game, frame
+// skip around them and frame stacking outermost.
+//
+// Types:
+// - FrameSkip: Repeats every action for k frames and sums the rewards.
+// - FrameStack: Observes the last k observations stacked into one.
+// - StickyActions: Repeats the previous action with a probability.
+// - Wrappers: The wrapper settings of an environment.
+//
+// Functions:
+// - NewFrameSkip, NewFrameStack, NewStickyActions: Wrap an environment.
+package env
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// stickyStream is the stream of the PCG source of StickyActions
+const stickyStream = 0x5eed571c4
+
+type FrameSkip struct {
+	env Env
+	k   int // frames every action is repeated
+}
+
+// NewFrameSkip returns e repeating every action for k frames
+func NewFrameSkip(e Env, k int) *FrameSkip {
+	return &FrameSkip{env: e, k: max(k, 1)}
+}
+
+// Reset starts a new episode, see Env
+func (f *FrameSkip) Reset(seed int64) Observation {
+	return f.env.Reset(seed)
+}
+
+// Step applies the action for k frames, or until the episode ends, and
+// returns the last observation with the sum of the rewards
+func (f *FrameSkip) Step(action Action) (Observation, float64, bool, bool, Info) {
+	var obs Observation
+	var terminated, truncated bool
+	var info Info
+	total := 0.0
+	for range f.k {
+		var reward float64
+		obs, reward, terminated, truncated, info = f.env.Step(action)
+		total += reward
+		if terminated || truncated {
+			break
+		}
+	}
+	return obs, total, terminated, truncated, info
+}
+
+// Space describes the observations, see Env
+func (f *FrameSkip) Space() Space {
+	return f.env.Space()
+}
+
+type FrameStack struct {
+	env    Env
+	k      int           // observations in the stack
+	frames []Observation // last k observations, oldest first
+}
+
+// NewFrameStack returns e observing its last k observations, stacked into
+// one of shape [k, ...]
+func NewFrameStack(e Env, k int) *FrameStack {
+	return &FrameStack{env: e, k: max(k, 1)}
+}
+
+// Reset starts a new episode, the stack is filled with its first
+// observation
+func (f *FrameStack) Reset(seed int64) Observation {
+	obs := f.env.Reset(seed)
+	f.frames = f.frames[:0]
+	for range f.k {
+		f.frames = append(f.frames, obs)
+	}
+	return f.observe()
+}
+
+// Step advances the episode, see Env, and pushes its observation onto the
+// stack
+func (f *FrameStack) Step(action Action) (Observation, float64, bool, bool, Info) {
+	obs, reward, terminated, truncated, info := f.env.Step(action)
+	if len(f.frames) == 0 {
+		// stepped without a reset, fill the stack like Reset
+		for range f.k - 1 {
+			f.frames = append(f.frames, obs)
+		}
+	} else {
+		f.frames = f.frames[1:]
+	}
+	f.frames = append(f.frames, obs)
+	return f.observe(), reward, terminated, truncated, info
+}
+
+// Space describes the stacked observations of shape [k, ...]
+func (f *FrameStack) Space() Space {
+	space := f.env.Space()
+	space.Shape = append([]int{f.k}, space.Shape...)
+	return space
+}
+
+// observe returns the stacked observations
+func (f *FrameStack) observe() Observation {
+	obs, _ := Stack(f.frames)
+	return obs
+}
+
+type StickyActions struct {
+	env  Env
+	p    float64    // probability to repeat the previous action
+	rng  *rand.Rand // seeded by Reset
+	last Action     // action played in the previous frame
+}
+
+// NewStickyActions returns e repeating the previous action instead of the
+// given one with probability p. The repeats are drawn from a source seeded
+// by Reset, so the same seed gives the same episode.
+func NewStickyActions(e Env, p float64) *StickyActions {
+	s := &StickyActions{env: e, p: p}
+	s.seed(1)
+	return s
+}
+
+// Reset starts a new episode, see Env, with the previous action ActionNoop
+func (s *StickyActions) Reset(seed int64) Observation {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s.seed(seed)
+	s.last = ActionNoop
+	return s.env.Reset(seed)
+}
+
+// Step plays the action, or with probability p the previous action, see Env
+func (s *StickyActions) Step(action Action) (Observation, float64, bool, bool, Info) {
+	if s.rng.Float64() >= s.p {
+		s.last = action
+	}
+	return s.env.Step(s.last)
+}
+
+// Space describes the observations, see Env
+func (s *StickyActions) Space() Space {
+	return s.env.Space()
+}
+
+// seed seeds the source of the repeats
+func (s *StickyActions) seed(seed int64) {
+	s.rng = rand.New(rand.NewPCG(uint64(seed), stickyStream))
+}
+
+type Wrappers struct {
+	FrameSkip     int     `json:"frame_skip"`     // frames every action is repeated, 0 or 1 for none
+	FrameStack    int     `json:"frame_stack"`    // observations stacked, 0 or 1 for none
+	StickyActions float64 `json:"sticky_actions"` // probability to repeat the previous action, e.g. 0.25
+}
+
+// MAX_FRAME_SKIP and MAX_FRAME_STACK limit the wrapper settings
+const (
+	MAX_FRAME_SKIP  = 64
+	MAX_FRAME_STACK = 64
+)
+
+// Validate returns an error for settings out of range
+func (w Wrappers) Validate() error {
+	if w.FrameSkip < 0 || w.FrameSkip > MAX_FRAME_SKIP {
+		return fmt.Errorf("invalid frame skip %d, expected 0 to %d", w.FrameSkip, MAX_FRAME_SKIP)
+	}
+	if w.FrameStack < 0 || w.FrameStack > MAX_FRAME_STACK {
+		return fmt.Errorf("invalid frame stack %d, expected 0 to %d", w.FrameStack, MAX_FRAME_STACK)
+	}
+	if w.StickyActions < 0 || w.StickyActions >= 1 {
+		return fmt.Errorf("invalid sticky action probability %g, expected 0 to below 1", w.StickyActions)
+	}
+	return nil
+}
+
+// Wrap returns e with the wrappers that are set, see the package comment
+// for their order
+func (w Wrappers) Wrap(e Env) Env {
+	if w.StickyActions > 0 {
+		e = NewStickyActions(e, w.StickyActions)
+	}
+	if w.FrameSkip > 1 {
+		e = NewFrameSkip(e, w.FrameSkip)
+	}
+	if w.FrameStack > 1 {
+		e = NewFrameStack(e, w.FrameStack)
+	}
+	return e
+}
@@ -0,0 +1,124 @@
+package env
+
+import (
+	"breakout-go/internal/breakout"
+	"reflect"
+	"testing"
+)
+
+// scriptedEnv is an Env returning the frame number as observation and a
+// reward of 1 per frame, it terminates at frame end
+type scriptedEnv struct {
+	frame, end int
+	actions    []Action
+}
+
+func (e *scriptedEnv) Reset(seed int64) Observation {
+	e.frame = 0
+	e.actions = nil
+	return e.observe()
+}
+
+func (e *scriptedEnv) Step(action Action) (Observation, float64, bool, bool, Info) {
+	e.frame++
+	e.actions = append(e.actions, action)
+	return e.observe(), 1, e.frame >= e.end, false, Info{Frame: e.frame}
+}
+
+func (e *scriptedEnv) Space() Space {
+	return Space{Shape: []int{1}, Low: 0, High: 255, DType: "uint8"}
+}
+
+func (e *scriptedEnv) observe() Observation {
+	return Observation{Shape: []int{1}, Data: []float32{float32(e.frame)}}
+}
+
+func TestFrameSkipSumsRewards(t *testing.T) {
+	inner := &scriptedEnv{end: 10}
+	e := NewFrameSkip(inner, 4)
+	e.Reset(1)
+	obs, reward, terminated, _, info := e.Step(ActionLeft)
+	if reward != 4 || info.Frame != 4 || obs.Data[0] != 4 || terminated {
+		t.Errorf("Expected 4 frames with reward 4, got reward %v at frame %d", reward, info.Frame)
+	}
+	e.Step(ActionLeft)
+	// the episode ends after 2 of the 4 frames
+	_, reward, terminated, _, info = e.Step(ActionRight)
+	if reward != 2 || info.Frame != 10 || !terminated {
+		t.Errorf("Expected the skip to stop at the end of the episode, got reward %v at frame %d", reward, info.Frame)
+	}
+}
+
+func TestFrameStack(t *testing.T) {
+	e := NewFrameStack(&scriptedEnv{end: 100}, 3)
+	obs := e.Reset(1)
+	if !reflect.DeepEqual(obs.Shape, []int{3, 1}) || !reflect.DeepEqual(obs.Data, []float32{0, 0, 0}) {
+		t.Errorf("Expected the first observation stacked 3 times, got %v %v", obs.Shape, obs.Data)
+	}
+	e.Step(ActionNoop)
+	obs, _, _, _, _ = e.Step(ActionNoop)
+	if !reflect.DeepEqual(obs.Data, []float32{0, 1, 2}) {
+		t.Errorf("Expected the last 3 observations oldest first, got %v", obs.Data)
+	}
+	obs, _, _, _, _ = e.Step(ActionNoop)
+	if !reflect.DeepEqual(obs.Data, []float32{1, 2, 3}) {
+		t.Errorf("Expected the oldest observation to drop out, got %v", obs.Data)
+	}
+	if space := e.Space(); !reflect.DeepEqual(space.Shape, []int{3, 1}) {
+		t.Errorf("Expected space shape [3 1], got %v", space.Shape)
+	}
+}
+
+func TestStickyActions(t *testing.T) {
+	inner := &scriptedEnv{end: 10000}
+	e := NewStickyActions(inner, 0.25)
+	e.Reset(7)
+	for i := range 4000 {
+		e.Step(Action(i % 2))
+	}
+	sticky := 0
+	for i, a := range inner.actions {
+		if a != Action(i%2) {
+			sticky++
+		}
+	}
+	// a repeat of a repeat plays the alternating action again, so p/(1+p) of
+	// the frames play another action than asked for
+	if sticky < 650 || sticky > 950 {
+		t.Errorf("Expected about a fifth of the frames to play another action, got %d of 4000", sticky)
+	}
+
+	// the same seed repeats the same actions
+	played := inner.actions
+	e.Reset(7)
+	for i := range 4000 {
+		e.Step(Action(i % 2))
+	}
+	if !reflect.DeepEqual(played, inner.actions) {
+		t.Error("Expected the same sticky actions for the same seed")
+	}
+}
+
+func TestWrappersWrap(t *testing.T) {
+	w := Wrappers{FrameSkip: 4, FrameStack: 4, StickyActions: 0.25}
+	if err := w.Validate(); err != nil {
+		t.Fatalf("Expected valid wrappers, got %v", err)
+	}
+	e := w.Wrap(NewBreakoutEnv(breakout.DefaultConfig(), Options{}))
+	obs := e.Reset(3)
+	want := []int{4, breakout.AREA_HEIGHT / 3, breakout.AREA_WIDTH / 3}
+	if !reflect.DeepEqual(obs.Shape, want) || !reflect.DeepEqual(e.Space().Shape, want) {
+		t.Errorf("Expected stacked observations of shape %v, got %v", want, obs.Shape)
+	}
+	if _, _, _, _, info := e.Step(ActionRight); info.Frame != 4 {
+		t.Errorf("Expected 4 frames per step, got %d", info.Frame)
+	}
+	for _, invalid := range []Wrappers{{FrameSkip: -1}, {FrameStack: MAX_FRAME_STACK + 1}, {StickyActions: 1}} {
+		if invalid.Validate() == nil {
+			t.Errorf("Expected error for %+v", invalid)
+		}
+	}
+	if _, ok := (Wrappers{}).Wrap(&scriptedEnv{}).(*scriptedEnv); !ok {
+		t.Error("Expected no wrappers without settings")
+	}
+}
@@ -0,0 +1,92 @@
+// Package render provides the pixel font of the rendered text.
+//
+// The glyphs are 3x5 pixels, letters are drawn in upper case and characters
+// without a glyph as blanks.
+package render
+
+import (
+	"math"
+	"strings"
+)
+
+// Size of the glyphs in font pixels, GLYPH_ADVANCE includes the gap to the
+// next glyph
+const (
+	GLYPH_WIDTH   = 3
+	GLYPH_HEIGHT  = 5
+	GLYPH_ADVANCE = GLYPH_WIDTH + 1
+)
+
+// glyphs holds the rows of every glyph, top first, '#' for a set pixel
+var glyphs = map[rune][GLYPH_HEIGHT]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'-': {"...", "...", "###", "...", "..."},
+	'/': {"..#", "..#", ".#.", "#..", "#.."},
+	'.': {"...", "...", "...", "...", ".#."},
+}
+
+// text draws the text with its top left corner at x, y and glyphs of the
+// given height, all in game coordinates
+func (c *canvas) text(x, y, height float64, s, name string) {
+	col := rgba(name)
+	// size of a font pixel in image pixels
+	n := max(int(math.Round(height*c.scale/GLYPH_HEIGHT)), 1)
+	px, py := c.px(x), c.px(y)
+	for i, r := range []rune(strings.ToUpper(s)) {
+		g, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		gx := px + i*GLYPH_ADVANCE*n
+		for row, line := range g {
+			for j, ch := range line {
+				if ch == '#' {
+					c.fillPixels(gx+j*n, py+row*n, gx+(j+1)*n, py+(row+1)*n, col)
+				}
+			}
+		}
+	}
+}
+
+// textCentered draws the text centered on x, y, see text
+func (c *canvas) textCentered(x, y, height float64, s, name string) {
+	n := max(math.Round(height*c.scale/GLYPH_HEIGHT), 1) / c.scale
+	w := float64(len([]rune(s))*GLYPH_ADVANCE-1) * n
+	c.text(x-w/2, y-GLYPH_HEIGHT*n/2, height, s, name)
+}
@@ -0,0 +1,38 @@
+package render
+
+import (
+	"image"
+	"testing"
+)
+
+func TestGlyphs(t *testing.T) {
+	for r, g := range glyphs {
+		for _, line := range g {
+			if len(line) != GLYPH_WIDTH {
+				t.Errorf("Expected glyph %q rows of %d pixels, got %q", r, GLYPH_WIDTH, line)
+			}
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, 40, 20)), scale: 2}
+	c.text(1, 1, GLYPH_HEIGHT, "1-", "white")
+	// "1" at font pixel size 2 from image pixel 2: its top row is ".#."
+	if c.img.RGBAAt(2, 2) == colors["white"] || c.img.RGBAAt(4, 2) != colors["white"] {
+		t.Error("Expected the top row of 1")
+	}
+	// "-" sets the middle row only
+	x := 2 + GLYPH_ADVANCE*2
+	if c.img.RGBAAt(x, 2+2*2) != colors["white"] || c.img.RGBAAt(x, 2) == colors["white"] {
+		t.Error("Expected the middle row of -")
+	}
+	// lower case is drawn in upper case
+	lower := &canvas{img: image.NewRGBA(image.Rect(0, 0, 40, 20)), scale: 2}
+	upper := &canvas{img: image.NewRGBA(image.Rect(0, 0, 40, 20)), scale: 2}
+	lower.text(0, 0, GLYPH_HEIGHT, "ab", "white")
+	upper.text(0, 0, GLYPH_HEIGHT, "AB", "white")
+	if string(lower.img.Pix) != string(upper.img.Pix) {
+		t.Error("Expected lower case text in upper case")
+	}
+}
@@ -0,0 +1,206 @@
+// Package render draws game states into images without a browser.
+//
+// Render draws a BreakoutState like the canvas of the web client
+// (cmd/web/index.html): the black game area, the blue paddle, white balls,
+// bricks in their colors with a dark gray border, the marks of the special
+// bricks, power-up capsules, laser shots and the status line. Text is drawn
+// with a small pixel font, see font.go.
+//
+// Variables:
+// - Palette: Every color Render uses, for paletted images like GIF frames.
+//
+// Functions:
+// - Render: Draws a game state into a new image at a scale.
+// - Paletted: Converts a rendered image to an image with Palette.
+package render
+
+import (
+	"breakout-go/internal/breakout"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+)
+
+// colors holds the CSS colors of the web client by name
+var colors = map[string]color.RGBA{
+	"black":       {0, 0, 0, 255},
+	"white":       {255, 255, 255, 255},
+	"blue":        {0, 0, 255, 255},
+	"yellow":      {255, 255, 0, 255},
+	"green":       {0, 128, 0, 255},
+	"orange":      {255, 165, 0, 255},
+	"red":         {255, 0, 0, 255},
+	"gray":        {128, 128, 128, 255},
+	"darkgray":    {169, 169, 169, 255},
+	"dimgray":     {105, 105, 105, 255},
+	"deepskyblue": {0, 191, 255, 255},
+	"cyan":        {0, 255, 255, 255},
+	"limegreen":   {50, 205, 50, 255},
+	"magenta":     {255, 0, 255, 255},
+}
+
+// capsuleColors holds the colors of the power-up capsules by kind
+var capsuleColors = map[string]string{
+	"wide":   "deepskyblue",
+	"slow":   "orange",
+	"multi":  "cyan",
+	"sticky": "limegreen",
+	"laser":  "red",
+	"life":   "magenta",
+}
+
+// Palette holds every color Render uses, black first
+var Palette = color.Palette{
+	colors["black"], colors["white"], colors["blue"], colors["yellow"],
+	colors["green"], colors["orange"], colors["red"], colors["gray"],
+	colors["darkgray"], colors["dimgray"], colors["deepskyblue"], colors["cyan"],
+	colors["limegreen"], colors["magenta"],
+}
+
+// Render draws the game state into a new image of the game area enlarged
+// by scale, e.g. 182x240 pixels for the default area at scale 1
+func Render(state *breakout.BreakoutState, scale float64) *image.RGBA {
+	w := max(int(math.Round(float64(state.Width)*scale)), 1)
+	h := max(int(math.Round(float64(state.Height)*scale)), 1)
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, w, h)), scale: scale}
+	c.fill(0, 0, float64(state.Width), float64(state.Height), "black")
+
+	// paddle
+	c.fill(float64(state.PaddleX), float64(state.Height-state.PaddleHeight), float64(state.PaddleWidth), float64(state.PaddleHeight), "blue")
+
+	// balls
+	for _, ball := range state.Balls {
+		c.circle(float64(ball.X), float64(ball.Y), float64(ball.Radius), "white")
+	}
+
+	// bricks with a one pixel border and the marks of their kind
+	for _, brick := range state.Bricks {
+		x, y, bw, bh := float64(brick.X), float64(brick.Y), float64(brick.Width), float64(brick.Height)
+		c.fill(x, y, bw, bh, brick.Color)
+		c.border(x, y, bw, bh, 1, "darkgray")
+		switch brick.Kind {
+		case "multi":
+			c.textCentered(x+bw/2, y+bh/2, bh*0.8, fmt.Sprint(brick.Hits), "black")
+		case "indestructible":
+			c.border(x, y, bw, bh, max(2, scale), "dimgray")
+		case "explosive":
+			lw := max(1, scale/2)
+			c.line(x, y, x+bw, y+bh, lw, "black")
+			c.line(x+bw, y, x, y+bh, lw, "black")
+		}
+	}
+
+	// power-up capsules with the first letter of their kind
+	for _, capsule := range state.Capsules {
+		x, y, cw, ch := float64(capsule.X), float64(capsule.Y), float64(capsule.Width), float64(capsule.Height)
+		name, ok := capsuleColors[capsule.Kind]
+		if !ok {
+			name = "white"
+		}
+		c.fill(x, y, cw, ch, name)
+		if capsule.Kind != "" {
+			c.textCentered(x+cw/2, y+ch/2, ch, strings.ToUpper(capsule.Kind[:1]), "black")
+		}
+	}
+
+	// laser shots
+	for _, laser := range state.Lasers {
+		lw := max(1, scale/2)
+		c.line(float64(laser.X), float64(laser.Y), float64(laser.X), float64(laser.Y+laser.Length), lw, "red")
+	}
+
+	// status line and the active power-ups with their remaining frames
+	c.text(3, 3, STATUS_SIZE, fmt.Sprintf("LIVES: %d LEVEL: %d SCORE: %d", state.Lives, state.Level, state.Score), "white")
+	if len(state.Effects) > 0 {
+		var effects []string
+		for _, e := range state.Effects {
+			effects = append(effects, fmt.Sprintf("%s %d", e.Kind, e.Remaining))
+		}
+		c.text(3, 5+STATUS_SIZE, STATUS_SIZE, strings.Join(effects, "  "), "white")
+	}
+	return c.img
+}
+
+// STATUS_SIZE is the height of the status line in game pixels
+const STATUS_SIZE = 5
+
+// Paletted returns the image converted to Palette, every pixel keeps its
+// color if the image was drawn by Render
+func Paletted(img *image.RGBA) *image.Paletted {
+	p := image.NewPaletted(img.Bounds(), Palette)
+	draw.Draw(p, p.Rect, img, img.Rect.Min, draw.Src)
+	return p
+}
+
+// canvas draws shapes given in game coordinates into an image
+type canvas struct {
+	img   *image.RGBA
+	scale float64 // image pixels per game pixel
+}
+
+// rgba returns a color by name, white for unknown names
+func rgba(name string) color.RGBA {
+	if c, ok := colors[name]; ok {
+		return c
+	}
+	return colors["white"]
+}
+
+// fill fills the rectangle of game coordinates
+func (c *canvas) fill(x, y, w, h float64, name string) {
+	c.fillPixels(c.px(x), c.px(y), c.px(x+w), c.px(y+h), rgba(name))
+}
+
+// fillPixels fills the image pixels [x0, x1) x [y0, y1)
+func (c *canvas) fillPixels(x0, y0, x1, y1 int, col color.RGBA) {
+	draw.Draw(c.img, image.Rect(x0, y0, x1, y1), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// border draws the border of width lw image pixels inside the rectangle of
+// game coordinates
+func (c *canvas) border(x, y, w, h, lw float64, name string) {
+	x0, y0, x1, y1 := c.px(x), c.px(y), c.px(x+w), c.px(y+h)
+	n := max(int(math.Round(lw)), 1)
+	col := rgba(name)
+	c.fillPixels(x0, y0, x1, y0+n, col)
+	c.fillPixels(x0, y1-n, x1, y1, col)
+	c.fillPixels(x0, y0, x0+n, y1, col)
+	c.fillPixels(x1-n, y0, x1, y1, col)
+}
+
+// circle fills the circle of game coordinates
+func (c *canvas) circle(cx, cy, r float64, name string) {
+	col := rgba(name)
+	x, y, rr := cx*c.scale, cy*c.scale, r*c.scale
+	for py := int(math.Floor(y - rr)); py <= int(math.Ceil(y+rr)); py++ {
+		for px := int(math.Floor(x - rr)); px <= int(math.Ceil(x+rr)); px++ {
+			dx, dy := float64(px)+0.5-x, float64(py)+0.5-y
+			if dx*dx+dy*dy <= rr*rr {
+				c.img.SetRGBA(px, py, col)
+			}
+		}
+	}
+}
+
+// line draws a line of width lw image pixels between two points of game
+// coordinates
+func (c *canvas) line(x0, y0, x1, y1, lw float64, name string) {
+	col := rgba(name)
+	ax, ay, bx, by := x0*c.scale, y0*c.scale, x1*c.scale, y1*c.scale
+	steps := max(int(math.Ceil(math.Max(math.Abs(bx-ax), math.Abs(by-ay)))), 1)
+	n := max(int(math.Round(lw)), 1)
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		px := int(math.Round(ax+(bx-ax)*t)) - n/2
+		py := int(math.Round(ay+(by-ay)*t)) - n/2
+		c.fillPixels(px, py, px+n, py+n, col)
+	}
+}
+
+// px returns the image pixel of a game coordinate
+func (c *canvas) px(v float64) int {
+	return int(math.Round(v * c.scale))
+}
@@ -0,0 +1,74 @@
+package render
+
+import (
+	"breakout-go/internal/breakout"
+	"image/color"
+	"testing"
+)
+
+// testState is a small game state with every kind of element
+func testState() *breakout.BreakoutState {
+	return &breakout.BreakoutState{
+		Width: 60, Height: 40, PaddleX: 20, PaddleWidth: 20, PaddleHeight: 3, Live: 1, Lives: 5,
+		Balls: []breakout.BallState{{X: 30, Y: 25, Radius: 2}},
+		Bricks: []breakout.BrickState{
+			{X: 0, Y: 10, Width: 15, Height: 6, Color: "red", Kind: "multi", Hits: 2},
+			{X: 15, Y: 10, Width: 15, Height: 6, Color: "gray", Kind: "indestructible"},
+			{X: 30, Y: 10, Width: 15, Height: 6, Color: "orange", Kind: "explosive"},
+			{X: 45, Y: 10, Width: 15, Height: 6, Color: "green", Kind: "normal"},
+		},
+		Capsules: []breakout.CapsuleState{{X: 5, Y: 20, Width: 8, Height: 4, Kind: "laser"}},
+		Lasers:   []breakout.LaserState{{X: 50, Y: 20, Length: 4}},
+	}
+}
+
+func TestRenderColors(t *testing.T) {
+	img := Render(testState(), 4)
+	if b := img.Bounds(); b.Dx() != 240 || b.Dy() != 160 {
+		t.Fatalf("Expected a 240x160 image at scale 4, got %v", b)
+	}
+	tests := []struct {
+		name string
+		x, y int // image pixel
+		want string
+	}{
+		{"area", 20, 140, "black"},
+		{"paddle", 120, 154, "blue"},
+		{"ball", 120, 100, "white"},
+		{"brick", 210, 52, "green"},
+		{"brick border", 180, 40, "darkgray"},
+		{"indestructible border", 62, 43, "dimgray"},
+		{"indestructible brick", 90, 52, "gray"},
+		{"capsule", 22, 81, "red"},
+		{"laser", 200, 88, "red"},
+	}
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.x, tt.y); got != colors[tt.want] {
+			t.Errorf("Expected the %s in %s, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRenderScale(t *testing.T) {
+	state := breakout.NewBreakout(breakout.DefaultConfig(), 1).GetState()
+	for _, scale := range []float64{0.5, 1, 2.5} {
+		img := Render(&state, scale)
+		want := int(float64(state.Width)*scale + 0.5)
+		if img.Bounds().Dx() != want {
+			t.Errorf("Expected width %d at scale %g, got %d", want, scale, img.Bounds().Dx())
+		}
+	}
+}
+
+func TestPaletted(t *testing.T) {
+	img := Render(testState(), 3)
+	p := Paletted(img)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if got := color.RGBAModel.Convert(p.At(x, y)); got != img.RGBAAt(x, y) {
+				t.Fatalf("Expected the paletted image to keep pixel %d,%d %v, got %v", x, y, img.RGBAAt(x, y), got)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package replay provides the player that re-simulates a replay.
+//
+// Types:
+// - Player: Plays a replay forward and seeks to any frame.
+//
+// Functions:
+// - NewPlayer: Creates a player at frame 0 of a replay.
+package replay
+
+import (
+	"breakout-go/internal/breakout"
+	"fmt"
+)
+
+// KEYFRAME_INTERVAL is the number of frames between the game copies a
+// player keeps to seek quickly
+const KEYFRAME_INTERVAL = 600
+
+type Player struct {
+	replay    *Replay
+	game      *breakout.Breakout   // game after frame frames
+	frame     int                  // frames played
+	keyframes []*breakout.Breakout // game after frame i*KEYFRAME_INTERVAL
+}
+
+// NewPlayer returns a player at frame 0 of the replay. It fails if the
+// replay was recorded with another engine version or an invalid config.
+func NewPlayer(rp *Replay) (*Player, error) {
+	if rp.EngineVersion != breakout.ENGINE_VERSION {
+		return nil, fmt.Errorf("replay recorded with engine version %d, this is version %d", rp.EngineVersion, breakout.ENGINE_VERSION)
+	}
+	cfg := rp.Config.WithDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid replay config: %w", err)
+	}
+	game := breakout.NewBreakout(cfg, rp.Seed)
+	return &Player{
+		replay:    rp,
+		game:      game,
+		keyframes: []*breakout.Breakout{game.Clone()},
+	}, nil
+}
+
+// Len returns the number of frames of the replay
+func (p *Player) Len() int {
+	return len(p.replay.Inputs)
+}
+
+// Frame returns the number of frames played
+func (p *Player) Frame() int {
+	return p.frame
+}
+
+// Game returns the game after the frames played. It must not be changed,
+// Clone it to play on.
+func (p *Player) Game() *breakout.Breakout {
+	return p.game
+}
+
+// Step plays the next frame and returns its events, false at the end of
+// the replay
+func (p *Player) Step() ([]breakout.Event, bool) {
+	if p.frame >= len(p.replay.Inputs) {
+		return nil, false
+	}
+	events := Play(p.game, p.replay.Inputs[p.frame])
+	p.frame++
+	if p.frame%KEYFRAME_INTERVAL == 0 && p.frame/KEYFRAME_INTERVAL == len(p.keyframes) {
+		p.keyframes = append(p.keyframes, p.game.Clone())
+	}
+	return events, true
+}
+
+// Seek moves the player to the given frame, 0 to Len, playing on from the
+// nearest keyframe before it
+func (p *Player) Seek(frame int) error {
+	if frame < 0 || frame > p.Len() {
+		return fmt.Errorf("frame %d out of range 0 to %d", frame, p.Len())
+	}
+	if frame < p.frame || frame-p.frame > KEYFRAME_INTERVAL {
+		k := min(frame/KEYFRAME_INTERVAL, len(p.keyframes)-1)
+		if k*KEYFRAME_INTERVAL > p.frame || frame < p.frame {
+			p.game = p.keyframes[k].Clone()
+			p.frame = k * KEYFRAME_INTERVAL
+		}
+	}
+	for p.frame < frame {
+		p.Step()
+	}
+	return nil
+}
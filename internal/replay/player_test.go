@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"breakout-go/internal/breakout"
+	"reflect"
+	"testing"
+)
+
+func TestPlayerReproducesGame(t *testing.T) {
+	cfg := breakout.DefaultConfig()
+	cfg.PowerUps = true
+	rp, game := record(cfg, 11, 2000)
+	p, err := NewPlayer(rp)
+	if err != nil {
+		t.Fatalf("Expected a player, got %v", err)
+	}
+	for {
+		if _, ok := p.Step(); !ok {
+			break
+		}
+	}
+	if p.Frame() != 2000 {
+		t.Errorf("Expected frame 2000, got %d", p.Frame())
+	}
+	if !reflect.DeepEqual(p.Game().Snapshot(), game.Snapshot()) {
+		t.Error("Expected the replayed game to equal the recorded game")
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	rp, _ := record(breakout.DefaultConfig(), 3, 2*KEYFRAME_INTERVAL+50)
+	want := map[int]breakout.Snapshot{}
+	p, _ := NewPlayer(rp)
+	for _, f := range []int{0, 10, KEYFRAME_INTERVAL + 1, 2*KEYFRAME_INTERVAL + 50} {
+		p.Seek(f)
+		want[f] = p.Game().Snapshot()
+	}
+
+	// seek backwards and across keyframes on a fresh player
+	p, _ = NewPlayer(rp)
+	for _, f := range []int{2*KEYFRAME_INTERVAL + 50, 10, KEYFRAME_INTERVAL + 1, 0, 10} {
+		if err := p.Seek(f); err != nil {
+			t.Fatalf("Expected to seek to frame %d, got %v", f, err)
+		}
+		if p.Frame() != f {
+			t.Errorf("Expected frame %d, got %d", f, p.Frame())
+		}
+		if !reflect.DeepEqual(p.Game().Snapshot(), want[f]) {
+			t.Errorf("Expected the game of frame %d", f)
+		}
+	}
+	if err := p.Seek(p.Len() + 1); err == nil {
+		t.Error("Expected an error seeking past the end")
+	}
+}
+
+func TestPlayerEngineVersion(t *testing.T) {
+	rp, _ := record(breakout.DefaultConfig(), 3, 10)
+	rp.EngineVersion = breakout.ENGINE_VERSION + 1
+	if _, err := NewPlayer(rp); err == nil {
+		t.Error("Expected an error for another engine version")
+	}
+}
@@ -0,0 +1,233 @@
+// Package replay records played games and replays them frame by frame.
+//
+// A replay holds everything needed to play a game again: the engine version,
+// the seed, the configuration and the input of every frame. The engine is
+// deterministic, so re-simulating the input reproduces every frame exactly.
+//
+// The replay file format is compact binary, integers are varints
+// (encoding/binary):
+// - magic "BKRP" and the format version byte FORMAT_VERSION
+// - engine version, seed, creation time (unix seconds), final score
+// - length and JSON of the configuration
+// - number of frames and the input as runs of (input byte, run length)
+//
+// Types:
+// - Input: The input of one frame.
+// - Replay: A recorded game.
+// - Recorder: Records the input of a game as it is played.
+//
+// Functions:
+// - Play: Plays one frame of a game with an input.
+// - NewRecorder: Starts recording a new game.
+// - Read: Reads a replay file.
+// - (*Replay) WriteTo: Writes a replay file.
+package replay
+
+import (
+	"breakout-go/internal/breakout"
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FORMAT_VERSION is the version of the replay file format
+const FORMAT_VERSION = 1
+
+// MAX_FRAMES limits the frames of a replay, one hour at 60 frames per
+// second. Recorders stop recording there, longer replay files are refused.
+const MAX_FRAMES = 60 * 60 * 60
+
+// magic starts every replay file
+const magic = "BKRP"
+
+// Input is the input of one frame, a combination of the Input flags
+type Input uint8
+
+const (
+	InputLeft  Input = 1 << iota // move the paddle left
+	InputRight                   // move the paddle right
+	InputFire                    // release the balls held by the sticky paddle
+)
+
+// NewInput returns the input for the held keys, left and right together
+// cancel out
+func NewInput(left, right, fire bool) Input {
+	var in Input
+	if left && !right {
+		in |= InputLeft
+	} else if right && !left {
+		in |= InputRight
+	}
+	if fire {
+		in |= InputFire
+	}
+	return in
+}
+
+// Play applies the input to the game and plays one frame. Every recorded
+// frame must be played with Play, so the replay takes the same steps.
+func Play(b *breakout.Breakout, in Input) []breakout.Event {
+	if in&InputLeft != 0 {
+		b.PaddleLeft()
+	} else if in&InputRight != 0 {
+		b.PaddleRight()
+	}
+	if in&InputFire != 0 {
+		b.ReleaseBall()
+	}
+	return b.Step()
+}
+
+type Replay struct {
+	EngineVersion int             // breakout.ENGINE_VERSION of the recording
+	Seed          int64           // seed of the game
+	Config        breakout.Config // configuration of the game
+	Created       time.Time       // start of the recording
+	Score         int             // score after the last frame
+	Inputs        []Input         // input of every frame
+}
+
+type Recorder struct {
+	replay Replay
+}
+
+// NewRecorder starts recording a game created with cfg and seed, before
+// its first frame is played
+func NewRecorder(cfg breakout.Config, seed int64) *Recorder {
+	return &Recorder{replay: Replay{
+		EngineVersion: breakout.ENGINE_VERSION,
+		Seed:          seed,
+		Config:        cfg,
+		Created:       time.Now(),
+	}}
+}
+
+// Record notes the input of a frame and the score after it. After
+// MAX_FRAMES frames the recording stops, the replay ends there.
+func (r *Recorder) Record(in Input, score int) {
+	if len(r.replay.Inputs) >= MAX_FRAMES {
+		return
+	}
+	r.replay.Inputs = append(r.replay.Inputs, in)
+	r.replay.Score = score
+}
+
+// Len returns the number of recorded frames
+func (r *Recorder) Len() int {
+	return len(r.replay.Inputs)
+}
+
+// Replay returns a copy of the recording so far
+func (r *Recorder) Replay() *Replay {
+	rp := r.replay
+	rp.Inputs = append([]Input(nil), r.replay.Inputs...)
+	return &rp
+}
+
+// WriteTo writes the replay in the replay file format
+func (rp *Replay) WriteTo(w io.Writer) (int64, error) {
+	cfg, err := json.Marshal(rp.Config)
+	if err != nil {
+		return 0, err
+	}
+	buf := []byte(magic)
+	buf = append(buf, FORMAT_VERSION)
+	buf = binary.AppendUvarint(buf, uint64(rp.EngineVersion))
+	buf = binary.AppendVarint(buf, rp.Seed)
+	buf = binary.AppendVarint(buf, rp.Created.Unix())
+	buf = binary.AppendVarint(buf, int64(rp.Score))
+	buf = binary.AppendUvarint(buf, uint64(len(cfg)))
+	buf = append(buf, cfg...)
+	buf = binary.AppendUvarint(buf, uint64(len(rp.Inputs)))
+	for i := 0; i < len(rp.Inputs); {
+		run := 1
+		for i+run < len(rp.Inputs) && rp.Inputs[i+run] == rp.Inputs[i] {
+			run++
+		}
+		buf = append(buf, byte(rp.Inputs[i]))
+		buf = binary.AppendUvarint(buf, uint64(run))
+		i += run
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// Read reads a replay in the replay file format
+func Read(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, head); err != nil || string(head[:len(magic)]) != magic {
+		return nil, errors.New("not a replay file")
+	}
+	if head[len(magic)] != FORMAT_VERSION {
+		return nil, fmt.Errorf("unsupported replay format version %d", head[len(magic)])
+	}
+	var rp Replay
+	engine, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	rp.EngineVersion = int(engine)
+	if rp.Seed, err = binary.ReadVarint(br); err != nil {
+		return nil, truncated(err)
+	}
+	created, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	rp.Created = time.Unix(created, 0)
+	score, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	rp.Score = int(score)
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	if n > 1<<20 {
+		return nil, fmt.Errorf("replay config of %d bytes", n)
+	}
+	cfg := make([]byte, n)
+	if _, err := io.ReadFull(br, cfg); err != nil {
+		return nil, truncated(err)
+	}
+	if err := json.Unmarshal(cfg, &rp.Config); err != nil {
+		return nil, fmt.Errorf("invalid replay config: %w", err)
+	}
+	frames, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, truncated(err)
+	}
+	if frames > MAX_FRAMES {
+		return nil, fmt.Errorf("replay of %d frames, at most %d are supported", frames, MAX_FRAMES)
+	}
+	// the inputs grow with the runs read, a short file cannot claim memory
+	// for MAX_FRAMES frames
+	for uint64(len(rp.Inputs)) < frames {
+		in, err := br.ReadByte()
+		if err != nil {
+			return nil, truncated(err)
+		}
+		run, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, truncated(err)
+		}
+		if run == 0 || run > frames-uint64(len(rp.Inputs)) {
+			return nil, fmt.Errorf("invalid input run of %d frames", run)
+		}
+		for range run {
+			rp.Inputs = append(rp.Inputs, Input(in))
+		}
+	}
+	return &rp, nil
+}
+
+// truncated wraps a read error of a replay file
+func truncated(err error) error {
+	return fmt.Errorf("truncated replay file: %w", err)
+}
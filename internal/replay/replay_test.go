@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"breakout-go/internal/breakout"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// record plays a game of n frames with a scripted input and returns its
+// replay and the game after the last frame
+func record(cfg breakout.Config, seed int64, n int) (*Replay, *breakout.Breakout) {
+	game := breakout.NewBreakout(cfg, seed)
+	rec := NewRecorder(cfg, seed)
+	for i := range n {
+		in := NewInput(i%90 < 30, i%90 >= 60, i%200 == 0)
+		Play(game, in)
+		rec.Record(in, game.GetState().Score)
+	}
+	return rec.Replay(), game
+}
+
+func TestNewInput(t *testing.T) {
+	tests := []struct {
+		left, right, fire bool
+		want              Input
+	}{
+		{false, false, false, 0},
+		{true, false, false, InputLeft},
+		{false, true, true, InputRight | InputFire},
+		{true, true, false, 0},
+	}
+	for _, tt := range tests {
+		if got := NewInput(tt.left, tt.right, tt.fire); got != tt.want {
+			t.Errorf("Expected input %b for %v, got %b", tt.want, tt, got)
+		}
+	}
+}
+
+func TestWriteAndRead(t *testing.T) {
+	rp, _ := record(breakout.DefaultConfig(), 7, 1000)
+	var buf bytes.Buffer
+	if _, err := rp.WriteTo(&buf); err != nil {
+		t.Fatalf("Expected to write the replay, got %v", err)
+	}
+	if buf.Len() > 1000 {
+		t.Errorf("Expected run-length coded input, got %d bytes for 1000 frames", buf.Len())
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Expected to read the replay, got %v", err)
+	}
+	rp.Created = time.Unix(rp.Created.Unix(), 0)
+	if !reflect.DeepEqual(got, rp) {
+		t.Errorf("Expected the replay to survive a round trip, got %+v", got)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	rp, _ := record(breakout.DefaultConfig(), 7, 100)
+	var buf bytes.Buffer
+	rp.WriteTo(&buf)
+	data := buf.Bytes()
+
+	tests := map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("XXXX"), data[4:]...),
+		"version":   append([]byte(magic+"\x09"), data[5:]...),
+		"truncated": data[:len(data)-1],
+	}
+	for name, data := range tests {
+		if _, err := Read(bytes.NewReader(data)); err == nil {
+			t.Errorf("Expected an error for the %s replay", name)
+		}
+	}
+	if _, err := Read(strings.NewReader(magic)); err == nil {
+		t.Error("Expected an error for a replay without a version")
+	}
+}
+
+func TestRecorderReplayIsCopy(t *testing.T) {
+	rec := NewRecorder(breakout.DefaultConfig(), 1)
+	rec.Record(InputLeft, 0)
+	rp := rec.Replay()
+	rec.Record(InputRight, 5)
+	if len(rp.Inputs) != 1 || rec.Len() != 2 {
+		t.Errorf("Expected the replay to keep 1 frame and the recorder 2, got %d and %d", len(rp.Inputs), rec.Len())
+	}
+	if rec.Replay().Score != 5 {
+		t.Errorf("Expected score 5, got %d", rec.Replay().Score)
+	}
+}
+
+func TestMaxFrames(t *testing.T) {
+	rec := NewRecorder(breakout.DefaultConfig(), 1)
+	for i := range MAX_FRAMES + 10 {
+		rec.Record(InputLeft, i)
+	}
+	rp := rec.Replay()
+	if len(rp.Inputs) != MAX_FRAMES || rp.Score != MAX_FRAMES-1 {
+		t.Errorf("Expected the recording to stop at %d frames, got %d frames and score %d", MAX_FRAMES, len(rp.Inputs), rp.Score)
+	}
+	var buf bytes.Buffer
+	rp.WriteTo(&buf)
+	if _, err := Read(&buf); err != nil {
+		t.Errorf("Expected a replay of MAX_FRAMES frames to be read, got %v", err)
+	}
+
+	// a single run claims more frames than allowed
+	rp.Inputs = append(rp.Inputs, InputLeft)
+	buf.Reset()
+	rp.WriteTo(&buf)
+	if buf.Len() > 1000 {
+		t.Fatalf("Expected a short file, got %d bytes", buf.Len())
+	}
+	if _, err := Read(&buf); err == nil {
+		t.Error("Expected an error for a replay of more than MAX_FRAMES frames")
+	}
+}
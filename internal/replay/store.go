@@ -0,0 +1,189 @@
+// Package replay provides the store that keeps replays by id.
+//
+// Types:
+// - Info: The summary of a stored replay.
+// - Store: Keeps replays in a directory or in memory.
+//
+// Functions:
+// - NewStore: Creates a store.
+package replay
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FILE_EXT is the extension of replay files in a store directory
+const FILE_EXT = ".bkrp"
+
+// ErrNotFound is returned for ids without a stored replay
+var ErrNotFound = errors.New("replay not found")
+
+// validID matches the ids the store hands out
+var validID = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+type Info struct {
+	ID      string    `json:"id"`
+	Seed    int64     `json:"seed"`
+	Score   int       `json:"score"`
+	Frames  int       `json:"frames"`
+	Created time.Time `json:"created"`
+}
+
+type Store struct {
+	mu     sync.Mutex
+	dir    string            // directory of the replay files, "" to keep them in memory
+	max    int               // replays kept, 0 for no limit
+	infos  map[string]Info   // summary of every stored replay
+	order  []string          // ids of the stored replays, oldest first
+	memory map[string][]byte // replay files kept in memory
+}
+
+// NewStore returns a store of the replay files in dir, which is created if
+// needed. With dir "" the replays are kept in memory. The store keeps at
+// most max replays, 0 for no limit, and drops the ones stored first.
+func NewStore(dir string, max int) (*Store, error) {
+	s := &Store{dir: dir, max: max, infos: map[string]Info{}, memory: map[string][]byte{}}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), FILE_EXT)
+		if !ok || !validID.MatchString(id) {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		rp, err := Read(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		s.infos[id] = info(id, rp)
+	}
+	for _, i := range slices.Backward(s.sorted()) {
+		s.order = append(s.order, i.ID)
+	}
+	s.prune()
+	return s, nil
+}
+
+// Save stores the replay and returns its summary with the new id
+func (s *Store) Save(rp *Replay) (Info, error) {
+	var buf bytes.Buffer
+	if _, err := rp.WriteTo(&buf); err != nil {
+		return Info{}, err
+	}
+	id := newID()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir == "" {
+		s.memory[id] = buf.Bytes()
+	} else if err := os.WriteFile(s.path(id), buf.Bytes(), 0o644); err != nil {
+		return Info{}, err
+	}
+	s.infos[id] = info(id, rp)
+	s.order = append(s.order, id)
+	s.prune()
+	return s.infos[id], nil
+}
+
+// Get returns the stored replay file
+func (s *Store) Get(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.infos[id]; !ok {
+		return nil, ErrNotFound
+	}
+	if s.dir == "" {
+		return s.memory[id], nil
+	}
+	return os.ReadFile(s.path(id))
+}
+
+// Has returns true if the replay is stored
+func (s *Store) Has(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.infos[id]
+	return ok
+}
+
+// Load returns the stored replay
+func (s *Store) Load(id string) (*Replay, error) {
+	data, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(data))
+}
+
+// List returns the summaries of the stored replays, newest first
+func (s *Store) List() []Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sorted()
+}
+
+// sorted returns the summaries newest first, s.mu must be held
+func (s *Store) sorted() []Info {
+	infos := make([]Info, 0, len(s.infos))
+	for _, i := range s.infos {
+		infos = append(infos, i)
+	}
+	slices.SortFunc(infos, func(a, b Info) int {
+		if c := b.Created.Compare(a.Created); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return infos
+}
+
+// prune drops the replays stored first above the limit, s.mu must be held
+func (s *Store) prune() {
+	for s.max > 0 && len(s.order) > s.max {
+		id := s.order[0]
+		s.order = s.order[1:]
+		delete(s.infos, id)
+		delete(s.memory, id)
+		if s.dir != "" {
+			os.Remove(s.path(id))
+		}
+	}
+}
+
+// path returns the file of a replay
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+FILE_EXT)
+}
+
+// info returns the summary of a replay
+func info(id string, rp *Replay) Info {
+	return Info{ID: id, Seed: rp.Seed, Score: rp.Score, Frames: len(rp.Inputs), Created: rp.Created}
+}
+
+// newID returns a random replay id
+func newID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
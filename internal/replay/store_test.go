@@ -0,0 +1,64 @@
+package replay
+
+import (
+	"breakout-go/internal/breakout"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStoreInMemory(t *testing.T) {
+	s, _ := NewStore("", 2)
+	var ids []string
+	for i := range 3 {
+		rp, _ := record(breakout.DefaultConfig(), int64(i+1), 100)
+		rp.Created = time.Unix(int64(1000-i), 0) // stored order, not age, decides what is dropped
+		info, err := s.Save(rp)
+		if err != nil {
+			t.Fatalf("Expected to save the replay, got %v", err)
+		}
+		ids = append(ids, info.ID)
+	}
+	if _, err := s.Get(ids[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the first replay to be dropped, got %v", err)
+	}
+	if s.Has(ids[0]) || !s.Has(ids[1]) {
+		t.Error("Expected Has to report only the kept replays")
+	}
+	list := s.List()
+	if len(list) != 2 || list[0].ID != ids[1] || list[1].ID != ids[2] {
+		t.Errorf("Expected replays %v newest first, got %+v", ids[1:], list)
+	}
+	rp, err := s.Load(ids[2])
+	if err != nil || rp.Seed != 3 || len(rp.Inputs) != 100 {
+		t.Errorf("Expected replay of seed 3 with 100 frames, got %+v, %v", rp, err)
+	}
+}
+
+func TestStoreDirectory(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Expected a store, got %v", err)
+	}
+	rp, _ := record(breakout.DefaultConfig(), 5, 100)
+	info, _ := s.Save(rp)
+	id := info.ID
+
+	// a new store finds the replays of the directory
+	s, _ = NewStore(dir, 0)
+	list := s.List()
+	if len(list) != 1 || list[0].ID != id || list[0].Frames != 100 || list[0].Seed != 5 {
+		t.Errorf("Expected the saved replay, got %+v", list)
+	}
+	got, err := s.Load(id)
+	if err != nil || !reflect.DeepEqual(got.Inputs, rp.Inputs) {
+		t.Errorf("Expected the saved input, got %v", err)
+	}
+	for _, bad := range []string{"../secret", "", id + "/.."} {
+		if _, err := s.Get(bad); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected no replay for id %q, got %v", bad, err)
+		}
+	}
+}
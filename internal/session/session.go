@@ -0,0 +1,425 @@
+// Package session provides a manager for many concurrent games on one
+// server, so every browser or training worker plays its own game.
+//
+// Every session owns a game environment (env.BreakoutEnv), or a vector of
+// them (env.VecEnv) for a VecSession, and a mutex. All access to the games
+// goes through the session. The manager caps the number
+// of live sessions and removes sessions that have not been used for the idle
+// timeout.
+//
+// Types:
+// - Options: The seed, configuration, step limit, observation mode, wrappers and reward of a new session.
+// - Session: A game played by one client.
+// - VecSession: Many games stepped together by one client.
+// - Manager: Creates, finds and expires sessions.
+// - StepResult: The outcome of one step.
+//
+// Functions:
+// - NewManager: Creates a session manager.
+// - (*Manager) Create, CreateVec, Get, GetVec, Delete, Expire, Run: Manage sessions.
+// - (*Session) State, Space, Info, Observation, Step, Reset, Snapshot: Play the game of a session.
+// - (*VecSession) Space, Observations, Step, Reset: Play the games of a vector session.
+package session
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// MAX_VEC_ENVS is the number of environments of a VecSession at most
+const MAX_VEC_ENVS = 256
+
+// ErrTooManySessions is returned by Create when the session cap is reached
+var ErrTooManySessions = errors.New("too many sessions")
+
+type Options struct {
+	Seed        int64            // seed of every game of the session, 0 for a new random seed per game
+	Config      *breakout.Config // game configuration, nil for the default of the manager
+	MaxSteps    int              // steps after which an episode is truncated, 0 for no limit
+	Observation env.ObsOptions   // observation mode, see env.NewObserver
+	Wrappers    env.Wrappers     // frame skip, frame stack and sticky actions
+	Reward      string           // reward preset, see env.RewardPreset, "" for "score"
+}
+
+type StepResult struct {
+	Observation env.Observation        // observation after the step
+	Reward      float64                // reward of the step
+	Terminated  bool                   // the game is over
+	Truncated   bool                   // the episode reached the step limit
+	Info        env.Info               // lives, level, score and frame of the episode
+	State       breakout.BreakoutState // state after the frame
+}
+
+type Session struct {
+	id       string
+	mu       sync.Mutex
+	game     *env.BreakoutEnv // environment of the game
+	env      env.Env          // game environment with the wrappers of the options
+	obs      env.Observation  // observation of the last reset or step
+	seed     int64            // seed from the options, 0 for a new random seed per game
+	lastUsed time.Time        // guarded by the mutex of the manager
+}
+
+type VecSession struct {
+	id       string
+	mu       sync.Mutex
+	vec      *env.VecEnv
+	obs      []env.Observation // observations of the last reset or step
+	seed     int64             // seed from the options, 0 for random seeds
+	lastUsed time.Time         // guarded by the mutex of the manager
+}
+
+type Manager struct {
+	mu          sync.Mutex
+	sessions    map[string]*Session
+	vecs        map[string]*VecSession
+	cfg         breakout.Config  // default game configuration
+	maxSessions int              // number of live sessions at most
+	pending     int              // reserved sessions that are still being created
+	idleTimeout time.Duration    // sessions unused for this long are removed
+	now         func() time.Time // clock, replaced in tests
+}
+
+// NewManager creates a manager for at most maxSessions live sessions that
+// play with cfg unless a session brings its own configuration. Sessions that
+// are not used for idleTimeout are removed.
+func NewManager(cfg breakout.Config, maxSessions int, idleTimeout time.Duration) *Manager {
+	return &Manager{
+		sessions:    make(map[string]*Session),
+		vecs:        make(map[string]*VecSession),
+		cfg:         cfg,
+		maxSessions: maxSessions,
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+	}
+}
+
+// Create starts a new session. Idle sessions are removed first, if the cap
+// is still reached ErrTooManySessions is returned.
+func (m *Manager) Create(opts Options) (*Session, error) {
+	cfg, err := m.config(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !m.reserve() {
+		return nil, ErrTooManySessions
+	}
+	game := env.NewBreakoutEnv(cfg, opts.envOptions())
+	s := &Session{
+		id:   newID(),
+		game: game,
+		env:  opts.Wrappers.Wrap(game),
+		seed: opts.Seed,
+	}
+	s.obs = s.env.Reset(s.seed)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending--
+	s.lastUsed = m.now()
+	m.sessions[s.id] = s
+	return s, nil
+}
+
+// CreateVec starts a new vector session of n environments that are stepped
+// by up to workers goroutines, 0 for one per CPU. It counts as one session
+// towards the cap.
+func (m *Manager) CreateVec(opts Options, n, workers int) (*VecSession, error) {
+	if n <= 0 || n > MAX_VEC_ENVS {
+		return nil, fmt.Errorf("invalid number of environments %d, expected 1 to %d", n, MAX_VEC_ENVS)
+	}
+	cfg, err := m.config(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !m.reserve() {
+		return nil, ErrTooManySessions
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	envs := make([]env.Env, n)
+	for i := range envs {
+		envs[i] = opts.Wrappers.Wrap(env.NewBreakoutEnv(cfg, opts.envOptions()))
+	}
+	s := &VecSession{
+		id:   newID(),
+		vec:  env.NewVecEnv(envs, workers),
+		seed: opts.Seed,
+	}
+	s.obs = s.vec.Reset(s.seed)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending--
+	s.lastUsed = m.now()
+	m.vecs[s.id] = s
+	return s, nil
+}
+
+// Get returns the session with the given ID and marks it as used
+func (m *Manager) Get(id string) (*Session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if ok {
+		s.lastUsed = m.now()
+	}
+	return s, ok
+}
+
+// GetVec returns the vector session with the given ID and marks it as used
+func (m *Manager) GetVec(id string) (*VecSession, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.vecs[id]
+	if ok {
+		s.lastUsed = m.now()
+	}
+	return s, ok
+}
+
+// Delete removes a session or vector session, it returns false if there is
+// no such session
+func (m *Manager) Delete(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.sessions[id]
+	_, okVec := m.vecs[id]
+	delete(m.sessions, id)
+	delete(m.vecs, id)
+	return ok || okVec
+}
+
+// Len returns the number of live sessions, including vector sessions
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions) + len(m.vecs)
+}
+
+// Expire removes the idle sessions and returns how many were removed
+func (m *Manager) Expire() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expire()
+}
+
+// Run removes idle sessions periodically until stop is closed
+func (m *Manager) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(max(m.idleTimeout/4, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.Expire()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// expire removes the idle sessions, m.mu must be held
+func (m *Manager) expire() int {
+	n := 0
+	for id, s := range m.sessions {
+		if m.now().Sub(s.lastUsed) >= m.idleTimeout {
+			delete(m.sessions, id)
+			n++
+		}
+	}
+	for id, s := range m.vecs {
+		if m.now().Sub(s.lastUsed) >= m.idleTimeout {
+			delete(m.vecs, id)
+			n++
+		}
+	}
+	return n
+}
+
+// reserve removes the idle sessions and, if there is room for one more
+// session, holds it for a session being created until it is added and
+// returns true. The games are built after the reservation, so a full
+// manager refuses a session before building it.
+func (m *Manager) reserve() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire()
+	if len(m.sessions)+len(m.vecs)+m.pending >= m.maxSessions {
+		return false
+	}
+	m.pending++
+	return true
+}
+
+// config returns the game configuration for the session options and checks
+// the observation options against it, the wrappers and the reward preset
+func (m *Manager) config(opts Options) (breakout.Config, error) {
+	cfg := m.cfg
+	if opts.Config != nil {
+		cfg = opts.Config.WithDefaults()
+		if err := cfg.Validate(); err != nil {
+			return cfg, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	if err := opts.Observation.Validate(cfg); err != nil {
+		return cfg, fmt.Errorf("invalid observation: %w", err)
+	}
+	if err := opts.Wrappers.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid wrappers: %w", err)
+	}
+	if _, err := env.RewardPreset(opts.Reward); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// envOptions returns the options of the environments of a session, the
+// reward preset is expected to be checked by Manager.config
+func (o Options) envOptions() env.Options {
+	reward, _ := env.RewardPreset(o.Reward)
+	return env.Options{MaxSteps: o.MaxSteps, Observation: o.Observation, Reward: reward}
+}
+
+// newID returns a random session ID
+func newID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// ID returns the ID of the session
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Seed returns the seed of the current game
+func (s *Session) Seed() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.game.Game().Seed()
+}
+
+// State returns the state of the game and the frames played since the last
+// reset
+func (s *Session) State() (breakout.BreakoutState, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.game.State(), s.game.Info().Frame
+}
+
+// Space describes the observations of the session
+func (s *Session) Space() env.Space {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.env.Space()
+}
+
+// Info returns the info of the current episode
+func (s *Session) Info() env.Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.game.Info()
+}
+
+// Observation returns the observation of the last reset or step, of the
+// game started by Create for a new session
+func (s *Session) Observation() env.Observation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.obs
+}
+
+// Step applies the action and advances the game by one step, one frame
+// unless the options skip frames
+func (s *Session) Step(action env.Action) (StepResult, error) {
+	if !action.Valid() {
+		return StepResult{}, fmt.Errorf("invalid action %d", action)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obs, reward, terminated, truncated, info := s.env.Step(action)
+	s.obs = obs
+	return StepResult{
+		Observation: obs,
+		Reward:      reward,
+		Terminated:  terminated,
+		Truncated:   truncated,
+		Info:        info,
+		State:       s.game.State(),
+	}, nil
+}
+
+// Reset starts a new game from the seed, 0 for the seed of the session
+// options, and returns its first observation
+func (s *Session) Reset(seed int64) env.Observation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seed == 0 {
+		seed = s.seed
+	}
+	s.obs = s.env.Reset(seed)
+	return s.obs
+}
+
+// Snapshot returns the full state of the game
+func (s *Session) Snapshot() breakout.Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.game.Game().Snapshot()
+}
+
+// ID returns the ID of the vector session
+func (s *VecSession) ID() string {
+	return s.id
+}
+
+// Len returns the number of environments
+func (s *VecSession) Len() int {
+	return s.vec.Len()
+}
+
+// Space describes the stacked observations of the environments
+func (s *VecSession) Space() env.Space {
+	return s.vec.Space()
+}
+
+// Observations returns the observations of the last reset or step, of the
+// games started by CreateVec for a new session
+func (s *VecSession) Observations() []env.Observation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.obs
+}
+
+// Step applies one action per environment and advances all games by one
+// step, one frame unless the options skip frames. Finished episodes are
+// reset (see env.VecEnv).
+func (s *VecSession) Step(actions []env.Action) (env.VecStep, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res, err := s.vec.Step(actions)
+	if err == nil {
+		s.obs = res.Observations
+	}
+	return res, err
+}
+
+// Reset starts new episodes in all environments from the seed, 0 for the
+// seed of the session options, and returns their first observations
+func (s *VecSession) Reset(seed int64) []env.Observation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seed == 0 {
+		seed = s.seed
+	}
+	s.obs = s.vec.Reset(seed)
+	return s.obs
+}
@@ -0,0 +1,287 @@
+package session
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestManager(maxSessions int) (*Manager, *time.Time) {
+	m := NewManager(breakout.DefaultConfig(), maxSessions, time.Minute)
+	now := time.Unix(1000, 0)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestCreateAndGet(t *testing.T) {
+	m, _ := newTestManager(4)
+	s, err := m.Create(Options{Seed: 3})
+	if err != nil {
+		t.Fatalf("Expected session, got %v", err)
+	}
+	got, ok := m.Get(s.ID())
+	if !ok || got != s {
+		t.Error("Expected to find the session by its ID")
+	}
+	if s.Seed() != 3 {
+		t.Errorf("Expected seed 3, got %d", s.Seed())
+	}
+	if _, ok := m.Get("unknown"); ok {
+		t.Error("Expected no session for an unknown ID")
+	}
+}
+
+func TestSessionsAreIndependent(t *testing.T) {
+	m, _ := newTestManager(4)
+	a, _ := m.Create(Options{Seed: 5})
+	b, _ := m.Create(Options{Seed: 5})
+
+	for range 50 {
+		a.Step(env.ActionLeft)
+	}
+	b.Step(env.ActionNoop)
+
+	stateA, frameA := a.State()
+	stateB, frameB := b.State()
+	if frameA != 50 || frameB != 1 {
+		t.Errorf("Expected frames 50 and 1, got %d and %d", frameA, frameB)
+	}
+	if stateA.PaddleX == stateB.PaddleX {
+		t.Error("Expected the paddles of the sessions to differ")
+	}
+}
+
+func TestSessionResetReplaysSeed(t *testing.T) {
+	m, _ := newTestManager(1)
+	s, _ := m.Create(Options{Seed: 9})
+	first, _ := s.State()
+	for range 20 {
+		s.Step(env.ActionRight)
+	}
+
+	s.Reset(0)
+	if state, _ := s.State(); !reflect.DeepEqual(state, first) {
+		t.Error("Expected reset to start the same game again")
+	}
+	if _, frame := s.State(); frame != 0 {
+		t.Errorf("Expected frame 0 after reset, got %d", frame)
+	}
+	if s.Reset(4); s.Seed() != 4 {
+		t.Errorf("Expected reset with seed 4, got %d", s.Seed())
+	}
+}
+
+func TestSessionObservation(t *testing.T) {
+	m, _ := newTestManager(1)
+	s, _ := m.Create(Options{Seed: 9})
+	created := s.Observation()
+	if len(created.Data) == 0 {
+		t.Fatal("Expected the observation of the game started by Create")
+	}
+	res, _ := s.Step(env.ActionLeft)
+	if !reflect.DeepEqual(s.Observation(), res.Observation) {
+		t.Error("Expected the observation of the last step")
+	}
+	if obs := s.Reset(0); !reflect.DeepEqual(obs, created) || !reflect.DeepEqual(s.Observation(), created) {
+		t.Error("Expected the reset to return and keep the first observation again")
+	}
+}
+
+func TestSessionMaxSteps(t *testing.T) {
+	m, _ := newTestManager(1)
+	s, _ := m.Create(Options{Seed: 1, MaxSteps: 3})
+	for i := range 3 {
+		res, _ := s.Step(env.ActionNoop)
+		if res.Truncated != (i == 2) {
+			t.Errorf("Expected truncation only at step 3, got %v at step %d", res.Truncated, i+1)
+		}
+	}
+}
+
+func TestStepRejectsInvalidAction(t *testing.T) {
+	m, _ := newTestManager(1)
+	s, _ := m.Create(Options{Seed: 1})
+	if _, err := s.Step(3); err == nil {
+		t.Error("Expected error for action 3")
+	}
+}
+
+func TestCreateWithConfig(t *testing.T) {
+	m, _ := newTestManager(2)
+	cfg := breakout.Config{AreaWidth: 120, BricksPerRow: 12}
+	s, err := m.Create(Options{Seed: 1, Config: &cfg})
+	if err != nil {
+		t.Fatalf("Expected session, got %v", err)
+	}
+	if state, _ := s.State(); state.Width != 120 {
+		t.Errorf("Expected width 120, got %d", state.Width)
+	}
+
+	invalid := breakout.Config{PaddleWidth: -1}
+	if _, err := m.Create(Options{Config: &invalid}); err == nil {
+		t.Error("Expected error for an invalid config")
+	}
+}
+
+func TestCreateWithObservation(t *testing.T) {
+	m, _ := newTestManager(2)
+	s, err := m.Create(Options{Observation: env.ObsOptions{Mode: env.ObsBitmap, Height: 40, Width: 30}})
+	if err != nil {
+		t.Fatalf("Expected session, got %v", err)
+	}
+	if obs := s.Reset(0); len(obs.Shape) != 2 || obs.Shape[0] != 40 || obs.Shape[1] != 30 {
+		t.Errorf("Expected 40x30 observations, got %v", obs.Shape)
+	}
+	if _, err := m.Create(Options{Observation: env.ObsOptions{Mode: "pixels"}}); err == nil {
+		t.Error("Expected error for an unknown observation mode")
+	}
+	if m.Len() != 1 {
+		t.Errorf("Expected no session for invalid options, got %d", m.Len())
+	}
+}
+
+func TestCreateWithWrappers(t *testing.T) {
+	m, _ := newTestManager(2)
+	s, err := m.Create(Options{Seed: 1, Wrappers: env.Wrappers{FrameSkip: 3}})
+	if err != nil {
+		t.Fatalf("Expected session, got %v", err)
+	}
+	s.Step(env.ActionLeft)
+	if _, frame := s.State(); frame != 3 {
+		t.Errorf("Expected 3 frames after one step, got %d", frame)
+	}
+	if _, err := m.Create(Options{Wrappers: env.Wrappers{FrameStack: -1}}); err == nil {
+		t.Error("Expected error for invalid wrappers")
+	}
+}
+
+func TestCreateWithReward(t *testing.T) {
+	m, _ := newTestManager(2)
+	if _, err := m.Create(Options{Reward: "clipped"}); err != nil {
+		t.Fatalf("Expected session, got %v", err)
+	}
+	if _, err := m.Create(Options{Reward: "bonus"}); err == nil {
+		t.Error("Expected error for an unknown reward preset")
+	}
+}
+
+func TestSessionCap(t *testing.T) {
+	m, _ := newTestManager(2)
+	m.Create(Options{})
+	m.Create(Options{})
+	if _, err := m.Create(Options{}); !errors.Is(err, ErrTooManySessions) {
+		t.Errorf("Expected ErrTooManySessions, got %v", err)
+	}
+}
+
+func TestSessionCapCountsSessionsBeingCreated(t *testing.T) {
+	m, _ := newTestManager(2)
+	if !m.reserve() {
+		t.Fatal("Expected room for a session")
+	}
+	// the reserved slot is taken while its session is built
+	m.Create(Options{})
+	if _, err := m.CreateVec(Options{}, 2, 1); !errors.Is(err, ErrTooManySessions) {
+		t.Errorf("Expected ErrTooManySessions, got %v", err)
+	}
+	if m.pending != 1 || m.Len() != 1 {
+		t.Errorf("Expected the created session to release its reservation, got %d pending and %d sessions", m.pending, m.Len())
+	}
+}
+
+func TestIdleSessionsExpire(t *testing.T) {
+	m, now := newTestManager(2)
+	idle, _ := m.Create(Options{})
+	used, _ := m.Create(Options{})
+
+	*now = now.Add(40 * time.Second)
+	m.Get(used.ID())
+	*now = now.Add(30 * time.Second)
+
+	if n := m.Expire(); n != 1 {
+		t.Errorf("Expected 1 expired session, got %d", n)
+	}
+	if _, ok := m.Get(idle.ID()); ok {
+		t.Error("Expected idle session to be removed")
+	}
+	if _, ok := m.Get(used.ID()); !ok {
+		t.Error("Expected used session to be kept")
+	}
+
+	// the cap counts only live sessions
+	if _, err := m.Create(Options{}); err != nil {
+		t.Errorf("Expected session after expiry, got %v", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	m, _ := newTestManager(1)
+	s, _ := m.Create(Options{})
+	if !m.Delete(s.ID()) {
+		t.Error("Expected delete to find the session")
+	}
+	if m.Delete(s.ID()) {
+		t.Error("Expected second delete to find nothing")
+	}
+	if m.Len() != 0 {
+		t.Errorf("Expected no sessions, got %d", m.Len())
+	}
+}
+
+func TestConcurrentSteps(t *testing.T) {
+	m := NewManager(breakout.DefaultConfig(), 8, time.Minute)
+	s, _ := m.Create(Options{Seed: 2})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				s.Step(env.ActionLeft)
+				s.State()
+			}
+		}()
+	}
+	wg.Wait()
+	if _, frame := s.State(); frame != 400 {
+		t.Errorf("Expected 400 frames, got %d", frame)
+	}
+}
+
+func TestCreateVec(t *testing.T) {
+	m, _ := newTestManager(2)
+	v, err := m.CreateVec(Options{Seed: 3}, 4, 2)
+	if err != nil {
+		t.Fatalf("Expected vector session, got %v", err)
+	}
+	if got, ok := m.GetVec(v.ID()); !ok || got != v {
+		t.Error("Expected to find the vector session by its ID")
+	}
+	if _, ok := m.Get(v.ID()); ok {
+		t.Error("Expected no single session for a vector session ID")
+	}
+	if len(v.Observations()) != 4 {
+		t.Errorf("Expected the 4 observations of the games started by CreateVec, got %d", len(v.Observations()))
+	}
+	res, err := v.Step([]env.Action{env.ActionLeft, env.ActionRight, env.ActionNoop, env.ActionLeft})
+	if err != nil || len(res.Observations) != 4 {
+		t.Fatalf("Expected 4 observations, got %d, %v", len(res.Observations), err)
+	}
+
+	// a vector session counts as one session towards the cap
+	m.Create(Options{})
+	if _, err := m.CreateVec(Options{}, 2, 1); !errors.Is(err, ErrTooManySessions) {
+		t.Errorf("Expected ErrTooManySessions, got %v", err)
+	}
+	if !m.Delete(v.ID()) || m.Len() != 1 {
+		t.Errorf("Expected delete to remove the vector session, %d sessions left", m.Len())
+	}
+	if _, err := m.CreateVec(Options{}, MAX_VEC_ENVS+1, 1); err == nil {
+		t.Error("Expected error for too many environments")
+	}
+}
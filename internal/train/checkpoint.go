@@ -0,0 +1,111 @@
+// Package train provides the checkpoints of a training.
+//
+// A checkpoint file is JSON: the format version, the kind of the agent, the
+// steps and episodes trained and the agent itself, so a training can be
+// resumed and a trained agent played.
+//
+// Types:
+// - Checkpoint: An agent with the progress of its training.
+//
+// Functions:
+// - LoadCheckpoint: Reads a checkpoint file.
+package train
+
+import (
+	"breakout-go/internal/env"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CHECKPOINT_VERSION is the version of the checkpoint format
+const CHECKPOINT_VERSION = 1
+
+// Checkpoint holds an agent and the progress of its training
+type Checkpoint struct {
+	Agent    Agent
+	Steps    int // steps trained
+	Episodes int // episodes trained
+}
+
+// checkpointFile is the content of a checkpoint file
+type checkpointFile struct {
+	Version  int             `json:"version"`
+	Kind     string          `json:"kind"`
+	Steps    int             `json:"steps"`
+	Episodes int             `json:"episodes"`
+	Agent    json.RawMessage `json:"agent"`
+}
+
+// Save writes the checkpoint to the file at path. The file is replaced
+// only once the checkpoint is written completely, so a training that is
+// stopped while saving keeps its previous checkpoint.
+func (c *Checkpoint) Save(path string) error {
+	agent, err := json.Marshal(c.Agent)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(checkpointFile{
+		Version:  CHECKPOINT_VERSION,
+		Kind:     c.Agent.Kind(),
+		Steps:    c.Steps,
+		Episodes: c.Episodes,
+		Agent:    agent,
+	})
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails after the rename
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadCheckpoint reads the checkpoint file at path
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file checkpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	if file.Version != CHECKPOINT_VERSION {
+		return nil, fmt.Errorf("checkpoint version %d, expected %d", file.Version, CHECKPOINT_VERSION)
+	}
+	c := &Checkpoint{Steps: file.Steps, Episodes: file.Episodes}
+	switch file.Kind {
+	case KIND_TABULAR:
+		var q QTable
+		if err := json.Unmarshal(file.Agent, &q); err != nil {
+			return nil, fmt.Errorf("invalid tabular agent: %w", err)
+		}
+		if err := q.Buckets.Validate(); err != nil {
+			return nil, err
+		}
+		if len(q.Q) != q.Buckets.States()*env.NUM_ACTIONS {
+			return nil, fmt.Errorf("%d values for %d states, expected %d", len(q.Q), q.Buckets.States(), q.Buckets.States()*env.NUM_ACTIONS)
+		}
+		c.Agent = &q
+	case KIND_LINEAR:
+		var l Linear
+		if err := json.Unmarshal(file.Agent, &l); err != nil {
+			return nil, fmt.Errorf("invalid linear agent: %w", err)
+		}
+		c.Agent = &l
+	default:
+		return nil, fmt.Errorf("unknown agent kind %q", file.Kind)
+	}
+	return c, nil
+}
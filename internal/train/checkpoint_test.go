@@ -0,0 +1,60 @@
+package train
+
+import (
+	"breakout-go/internal/env"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	q := NewQTable(Buckets{BallX: 2, BallY: 3, Paddle: 4})
+	q.Q[5] = 1.5
+	l := NewLinear()
+	l.Weights[env.ActionLeft][3] = -0.25
+	for _, agent := range []Agent{q, l} {
+		path := filepath.Join(dir, agent.Kind()+".json")
+		saved := &Checkpoint{Agent: agent, Steps: 1234, Episodes: 56}
+		if err := saved.Save(path); err != nil {
+			t.Fatalf("Expected the %s checkpoint to be saved, got %v", agent.Kind(), err)
+		}
+		loaded, err := LoadCheckpoint(path)
+		if err != nil {
+			t.Fatalf("Expected the %s checkpoint to load, got %v", agent.Kind(), err)
+		}
+		if !reflect.DeepEqual(loaded, saved) {
+			t.Errorf("Expected %+v, got %+v", saved, loaded)
+		}
+	}
+	// saving again replaces the file and leaves no temporary files
+	if err := (&Checkpoint{Agent: l}).Save(filepath.Join(dir, "linear.json")); err != nil {
+		t.Fatalf("Expected the checkpoint to be replaced, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected 2 files, got %d", len(entries))
+	}
+}
+
+func TestLoadCheckpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"json":    `{"version":`,
+		"version": `{"version":2,"kind":"linear","agent":{}}`,
+		"kind":    `{"version":1,"kind":"deep","agent":{}}`,
+		"buckets": `{"version":1,"kind":"tabular","agent":{"buckets":{"ball_x":0,"ball_y":1,"paddle":1},"q":[]}}`,
+		"size":    `{"version":1,"kind":"tabular","agent":{"buckets":{"ball_x":1,"ball_y":1,"paddle":1},"q":[1,2]}}`,
+		"weights": `{"version":1,"kind":"linear","agent":{"weights":"x"}}`,
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name+".json")
+		os.WriteFile(path, []byte(content), 0o644)
+		if _, err := LoadCheckpoint(path); err == nil {
+			t.Errorf("Expected an error for a checkpoint with an invalid %s", name)
+		}
+	}
+	if _, err := LoadCheckpoint(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
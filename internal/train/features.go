@@ -0,0 +1,116 @@
+// Package train provides the features the agents learn from.
+//
+// Both agents read the ball and paddle features at the start of the
+// env.ObsFeatures observation and ignore the brick mask. Buckets discretize
+// them into the states of QTable, linearFeatures tiles the offset of the
+// ball from the paddle into the inputs of Linear.
+//
+// Types:
+// - Buckets: The resolution of the discretized state.
+//
+// Functions:
+// - DefaultBuckets: Returns the default resolution.
+package train
+
+import "fmt"
+
+// Indices of the ball and paddle features of env.ObsFeatures
+const (
+	featBallX = iota
+	featBallY
+	featBallVX
+	featBallVY
+	featPaddleX
+	featPaddleWidth
+)
+
+// VELOCITY_BUCKETS is the number of ball directions: left, straight or
+// right, each rising or falling
+const VELOCITY_BUCKETS = 6
+
+// MAX_BUCKETS limits every dimension of Buckets
+const MAX_BUCKETS = 256
+
+// Buckets is the number of equal intervals the ball position and the paddle
+// position are divided into. The ball direction adds VELOCITY_BUCKETS.
+type Buckets struct {
+	BallX  int `json:"ball_x"`
+	BallY  int `json:"ball_y"`
+	Paddle int `json:"paddle"`
+}
+
+// DefaultBuckets returns a resolution that tells the paddle where to go in
+// about seven thousand states
+func DefaultBuckets() Buckets {
+	return Buckets{BallX: 12, BallY: 8, Paddle: 12}
+}
+
+// Validate returns an error for a dimension out of 1 to MAX_BUCKETS
+func (b Buckets) Validate() error {
+	for _, n := range []int{b.BallX, b.BallY, b.Paddle} {
+		if n < 1 || n > MAX_BUCKETS {
+			return fmt.Errorf("invalid buckets %+v, expected 1 to %d per dimension", b, MAX_BUCKETS)
+		}
+	}
+	return nil
+}
+
+// States returns the number of discretized states
+func (b Buckets) States() int {
+	return b.BallX * b.BallY * VELOCITY_BUCKETS * b.Paddle
+}
+
+// State returns the discretized state of the features observation, from 0
+// to States()-1
+func (b Buckets) State(obs []float32) int {
+	x := bucket(float64(obs[featBallX]), b.BallX)
+	y := bucket(float64(obs[featBallY]), b.BallY)
+	v := 0 // rising left
+	switch vx := obs[featBallVX]; {
+	case vx > 0.01:
+		v = 2
+	case vx >= -0.01:
+		v = 1
+	}
+	if obs[featBallVY] > 0 {
+		v += 3
+	}
+	// the paddle moves between 0 and 1 minus its width
+	travel := 1 - float64(obs[featPaddleWidth])
+	p := b.Paddle - 1
+	if travel > 0 {
+		p = bucket(float64(obs[featPaddleX])/travel, b.Paddle)
+	}
+	return ((x*b.BallY+y)*VELOCITY_BUCKETS+v)*b.Paddle + p
+}
+
+// bucket returns the interval of v in [0, 1] divided into n intervals
+func bucket(v float64, n int) int {
+	return min(max(int(v*float64(n)), 0), n-1)
+}
+
+// OFFSET_TILES is the number of equal intervals the offset of the ball
+// from the paddle center is divided into for Linear, from half the game
+// width to the left to half of it to the right
+const OFFSET_TILES = 12
+
+// NUM_LINEAR_FEATURES is the number of inputs of Linear: a bias and the
+// offset tiles of a rising and of a falling ball
+const NUM_LINEAR_FEATURES = 1 + 2*OFFSET_TILES
+
+// linearFeatures returns the inputs of Linear: 1 for the bias and for the
+// tile of the offset of the ball from the paddle center, 0 for the other
+// tiles. Raw positions do not work as inputs, the value of an action
+// depends on them in a way no linear function comes close to, while the
+// tiles let it follow the ball.
+func linearFeatures(obs []float32) [NUM_LINEAR_FEATURES]float64 {
+	var f [NUM_LINEAR_FEATURES]float64
+	center := float64(obs[featPaddleX]) + float64(obs[featPaddleWidth])/2
+	tile := bucket(float64(obs[featBallX])-center+0.5, OFFSET_TILES)
+	if obs[featBallVY] > 0 {
+		tile += OFFSET_TILES
+	}
+	f[0] = 1
+	f[1+tile] = 1
+	return f
+}
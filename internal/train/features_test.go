@@ -0,0 +1,80 @@
+package train
+
+import (
+	"breakout-go/internal/env"
+	"testing"
+)
+
+// features returns a features observation of the ball and the paddle
+func features(ballX, ballY, vx, vy, paddleX, paddleWidth float32) []float32 {
+	obs := make([]float32, env.NUM_BALL_PADDLE_FEATURES+3)
+	copy(obs, []float32{ballX, ballY, vx, vy, paddleX, paddleWidth})
+	return obs
+}
+
+func TestBucketsState(t *testing.T) {
+	b := Buckets{BallX: 4, BallY: 3, Paddle: 5}
+	if b.States() != 4*3*VELOCITY_BUCKETS*5 {
+		t.Errorf("Expected %d states, got %d", 4*3*VELOCITY_BUCKETS*5, b.States())
+	}
+	seen := map[int]bool{}
+	for _, x := range []float32{0, 0.3, 0.6, 1} {
+		for _, y := range []float32{0, 0.5, 1} {
+			for _, vx := range []float32{-0.5, 0, 0.5} {
+				for _, vy := range []float32{-0.5, 0.5} {
+					for _, p := range []float32{0, 0.2, 0.4, 0.6, 0.8} {
+						s := b.State(features(x, y, vx, vy, p, 0.2))
+						if s < 0 || s >= b.States() {
+							t.Fatalf("Expected a state below %d, got %d", b.States(), s)
+						}
+						seen[s] = true
+					}
+				}
+			}
+		}
+	}
+	if len(seen) != b.States() {
+		t.Errorf("Expected %d distinct states, got %d", b.States(), len(seen))
+	}
+	// out of range values are clamped
+	if s := b.State(features(2, -1, 0, 1, 0.9, 0.2)); s != b.State(features(1, 0, 0, 1, 0.8, 0.2)) {
+		t.Error("Expected out of range features in the outer buckets")
+	}
+}
+
+func TestBucketsValidate(t *testing.T) {
+	if err := DefaultBuckets().Validate(); err != nil {
+		t.Errorf("Expected the default buckets to be valid, got %v", err)
+	}
+	for _, b := range []Buckets{{0, 1, 1}, {1, MAX_BUCKETS + 1, 1}, {1, 1, -1}} {
+		if err := b.Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", b)
+		}
+	}
+}
+
+func TestLinearFeatures(t *testing.T) {
+	tests := []struct {
+		name string
+		obs  []float32
+		tile int
+	}{
+		// paddle center at 0.5
+		{"rising above the center", features(0.5, 0.5, 0, -0.5, 0.25, 0.5), OFFSET_TILES / 2},
+		{"falling above the center", features(0.5, 0.5, 0, 0.5, 0.25, 0.5), OFFSET_TILES + OFFSET_TILES/2},
+		{"far left", features(0, 0.5, 0, 0.5, 0.8, 0.2), OFFSET_TILES},
+		{"far right", features(1, 0.5, 0, -0.5, 0, 0.2), OFFSET_TILES - 1},
+	}
+	for _, tt := range tests {
+		f := linearFeatures(tt.obs)
+		for i, v := range f {
+			want := 0.0
+			if i == 0 || i == 1+tt.tile {
+				want = 1
+			}
+			if v != want {
+				t.Errorf("%s: expected feature %d to be %g, got %g", tt.name, i, want, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package train provides the linear agent.
+//
+// Types:
+// - Linear: Action values as a linear function of the features.
+//
+// Functions:
+// - NewLinear: Creates an agent with zero weights.
+package train
+
+import "breakout-go/internal/env"
+
+// KIND_LINEAR is the Kind of Linear
+const KIND_LINEAR = "linear"
+
+// Linear estimates the value of every action as the dot product of its
+// weights with linearFeatures of the observation
+type Linear struct {
+	Weights [env.NUM_ACTIONS][NUM_LINEAR_FEATURES]float64 `json:"weights"`
+}
+
+// NewLinear returns an agent with zero weights
+func NewLinear() *Linear {
+	return &Linear{}
+}
+
+func (l *Linear) Kind() string {
+	return KIND_LINEAR
+}
+
+// Values returns the values of the actions in the state of obs, see Agent
+func (l *Linear) Values(obs []float32) [env.NUM_ACTIONS]float64 {
+	f := linearFeatures(obs)
+	var values [env.NUM_ACTIONS]float64
+	for a := range values {
+		for i, v := range f {
+			values[a] += l.Weights[a][i] * v
+		}
+	}
+	return values
+}
+
+// Update takes a gradient step of the squared error of the action value
+// towards target, see Agent. The step is normalized by the squared length
+// of the features, so alpha is the fraction of the error that is corrected
+// like for QTable, which keeps the weights from diverging.
+func (l *Linear) Update(obs []float32, action env.Action, target, alpha float64) {
+	f := linearFeatures(obs)
+	var value, norm float64
+	for i, v := range f {
+		value += l.Weights[action][i] * v
+		norm += v * v
+	}
+	step := alpha * (target - value) / norm // norm >= 1 from the bias
+	for i, v := range f {
+		l.Weights[action][i] += step * v
+	}
+}
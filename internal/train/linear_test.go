@@ -0,0 +1,46 @@
+package train
+
+import (
+	"breakout-go/internal/env"
+	"math"
+	"testing"
+)
+
+func TestLinearUpdate(t *testing.T) {
+	l := NewLinear()
+	obs := features(0.2, 0.5, 0, 0.5, 0.4, 0.2)
+	// two features are set, the step is normalized by their count
+	l.Update(obs, env.ActionRight, 4, 0.5)
+	if v := l.Values(obs); v != [env.NUM_ACTIONS]float64{0, 0, 2} {
+		t.Errorf("Expected the value of right to move halfway to 4, got %v", v)
+	}
+	for range 100 {
+		l.Update(obs, env.ActionRight, 4, 0.5)
+	}
+	if v := l.Values(obs)[env.ActionRight]; math.Abs(v-4) > 1e-9 {
+		t.Errorf("Expected the value of right to converge to 4, got %g", v)
+	}
+}
+
+func TestLinearLearnsToFollowTheBall(t *testing.T) {
+	l := NewLinear()
+	// moving towards a falling ball is worth more than moving away
+	for range 50 {
+		for _, x := range []float32{0.1, 0.3, 0.7, 0.9} {
+			obs := features(x, 0.5, 0, 0.5, 0.4, 0.2)
+			towards, away := env.ActionLeft, env.ActionRight
+			if x > 0.5 {
+				towards, away = away, towards
+			}
+			l.Update(obs, towards, 1, 0.1)
+			l.Update(obs, away, -1, 0.1)
+			l.Update(obs, env.ActionNoop, 0, 0.1)
+		}
+	}
+	if a := Greedy(l, features(0.1, 0.5, 0, 0.5, 0.4, 0.2)); a != env.ActionLeft {
+		t.Errorf("Expected left for a ball left of the paddle, got %v", a)
+	}
+	if a := Greedy(l, features(0.9, 0.5, 0, 0.5, 0.4, 0.2)); a != env.ActionRight {
+		t.Errorf("Expected right for a ball right of the paddle, got %v", a)
+	}
+}
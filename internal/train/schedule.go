@@ -0,0 +1,84 @@
+// Package train provides the exploration schedules of the trainer.
+//
+// Types:
+// - Schedule: The exploration rate by training step.
+// - Constant: The same rate at every step.
+// - LinearDecay: A rate falling linearly from a start to an end value.
+// - ExpDecay: A rate falling exponentially from a start to an end value.
+//
+// Functions:
+// - NewSchedule: Creates a schedule by name.
+package train
+
+import (
+	"fmt"
+	"math"
+)
+
+// Names of the schedules for NewSchedule
+const (
+	SCHEDULE_CONSTANT = "constant"
+	SCHEDULE_LINEAR   = "linear"
+	SCHEDULE_EXP      = "exp"
+)
+
+type Schedule interface {
+	// Value returns the rate at the step, counted from 0
+	Value(step int) float64
+}
+
+// Constant is a schedule with the same rate at every step
+type Constant float64
+
+func (c Constant) Value(step int) float64 {
+	return float64(c)
+}
+
+// LinearDecay falls linearly from Start to End over Steps steps and stays
+// at End after them
+type LinearDecay struct {
+	Start, End float64
+	Steps      int
+}
+
+func (d LinearDecay) Value(step int) float64 {
+	if step >= d.Steps {
+		return d.End
+	}
+	return d.Start + (d.End-d.Start)*float64(step)/float64(d.Steps)
+}
+
+// ExpDecay falls exponentially from Start towards End, halving the distance
+// to End every HalfLife steps
+type ExpDecay struct {
+	Start, End float64
+	HalfLife   int
+}
+
+func (d ExpDecay) Value(step int) float64 {
+	if d.HalfLife <= 0 {
+		return d.End
+	}
+	return d.End + (d.Start-d.End)*math.Exp2(-float64(step)/float64(d.HalfLife))
+}
+
+// NewSchedule returns the schedule with the given name from start to end,
+// over steps steps for SCHEDULE_LINEAR and with a half-life of steps steps
+// for SCHEDULE_EXP. SCHEDULE_CONSTANT stays at start.
+func NewSchedule(name string, start, end float64, steps int) (Schedule, error) {
+	if start < 0 || start > 1 || end < 0 || end > 1 {
+		return nil, fmt.Errorf("invalid rates %g and %g, expected 0 to 1", start, end)
+	}
+	if steps < 0 {
+		return nil, fmt.Errorf("invalid steps %d", steps)
+	}
+	switch name {
+	case SCHEDULE_CONSTANT:
+		return Constant(start), nil
+	case SCHEDULE_LINEAR:
+		return LinearDecay{Start: start, End: end, Steps: steps}, nil
+	case SCHEDULE_EXP:
+		return ExpDecay{Start: start, End: end, HalfLife: steps}, nil
+	}
+	return nil, fmt.Errorf("unknown schedule %q, expected %s, %s or %s", name, SCHEDULE_CONSTANT, SCHEDULE_LINEAR, SCHEDULE_EXP)
+}
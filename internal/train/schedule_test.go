@@ -0,0 +1,56 @@
+package train
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSchedules(t *testing.T) {
+	tests := []struct {
+		schedule Schedule
+		step     int
+		want     float64
+	}{
+		{Constant(0.1), 0, 0.1},
+		{Constant(0.1), 1000, 0.1},
+		{LinearDecay{Start: 1, End: 0.1, Steps: 100}, 0, 1},
+		{LinearDecay{Start: 1, End: 0.1, Steps: 100}, 50, 0.55},
+		{LinearDecay{Start: 1, End: 0.1, Steps: 100}, 100, 0.1},
+		{LinearDecay{Start: 1, End: 0.1, Steps: 100}, 500, 0.1},
+		{ExpDecay{Start: 1, End: 0.2, HalfLife: 10}, 0, 1},
+		{ExpDecay{Start: 1, End: 0.2, HalfLife: 10}, 10, 0.6},
+		{ExpDecay{Start: 1, End: 0.2, HalfLife: 10}, 20, 0.4},
+		{ExpDecay{Start: 1, End: 0.2, HalfLife: 0}, 0, 0.2},
+	}
+	for _, tt := range tests {
+		if got := tt.schedule.Value(tt.step); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Expected %g for %+v at step %d, got %g", tt.want, tt.schedule, tt.step, got)
+		}
+	}
+}
+
+func TestNewSchedule(t *testing.T) {
+	for _, name := range []string{SCHEDULE_CONSTANT, SCHEDULE_LINEAR, SCHEDULE_EXP} {
+		s, err := NewSchedule(name, 1, 0.1, 100)
+		if err != nil || s.Value(0) != 1 {
+			t.Errorf("Expected the %s schedule to start at 1, got %v", name, err)
+		}
+	}
+	if s, _ := NewSchedule(SCHEDULE_LINEAR, 1, 0.1, 100); s != (LinearDecay{Start: 1, End: 0.1, Steps: 100}) {
+		t.Errorf("Expected a linear decay, got %+v", s)
+	}
+	for _, args := range []struct {
+		name       string
+		start, end float64
+		steps      int
+	}{
+		{"cosine", 1, 0.1, 100},
+		{SCHEDULE_LINEAR, 1.5, 0.1, 100},
+		{SCHEDULE_LINEAR, 1, -0.1, 100},
+		{SCHEDULE_EXP, 1, 0.1, -1},
+	} {
+		if _, err := NewSchedule(args.name, args.start, args.end, args.steps); err == nil {
+			t.Errorf("Expected an error for %+v", args)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package train provides the tabular agent.
+//
+// Types:
+// - QTable: Action values by discretized state.
+//
+// Functions:
+// - NewQTable: Creates a table of zero values.
+package train
+
+import "breakout-go/internal/env"
+
+// KIND_TABULAR is the Kind of QTable
+const KIND_TABULAR = "tabular"
+
+// QTable holds the value of every action in every state of Buckets
+type QTable struct {
+	Buckets Buckets   `json:"buckets"`
+	Q       []float64 `json:"q"` // NUM_ACTIONS values per state, by state
+}
+
+// NewQTable returns a table of zero values for the states of b, which has
+// to be valid
+func NewQTable(b Buckets) *QTable {
+	return &QTable{Buckets: b, Q: make([]float64, b.States()*env.NUM_ACTIONS)}
+}
+
+func (q *QTable) Kind() string {
+	return KIND_TABULAR
+}
+
+// Values returns the values of the actions in the state of obs, see Agent
+func (q *QTable) Values(obs []float32) [env.NUM_ACTIONS]float64 {
+	i := q.Buckets.State(obs) * env.NUM_ACTIONS
+	return [env.NUM_ACTIONS]float64(q.Q[i : i+env.NUM_ACTIONS])
+}
+
+// Update moves the value of the action in the state of obs towards target,
+// see Agent
+func (q *QTable) Update(obs []float32, action env.Action, target, alpha float64) {
+	i := q.Buckets.State(obs)*env.NUM_ACTIONS + int(action)
+	q.Q[i] += alpha * (target - q.Q[i])
+}
@@ -0,0 +1,23 @@
+package train
+
+import (
+	"breakout-go/internal/env"
+	"testing"
+)
+
+func TestQTable(t *testing.T) {
+	q := NewQTable(Buckets{BallX: 2, BallY: 2, Paddle: 2})
+	if len(q.Q) != q.Buckets.States()*env.NUM_ACTIONS {
+		t.Fatalf("Expected %d values, got %d", q.Buckets.States()*env.NUM_ACTIONS, len(q.Q))
+	}
+	obs := features(0.2, 0.2, 0.5, 0.5, 0, 0.2)
+	other := features(0.8, 0.2, 0.5, 0.5, 0, 0.2)
+	q.Update(obs, env.ActionLeft, 10, 0.5)
+	q.Update(obs, env.ActionLeft, 10, 0.5)
+	if v := q.Values(obs); v != [env.NUM_ACTIONS]float64{0, 7.5, 0} {
+		t.Errorf("Expected the value of left to move towards 10, got %v", v)
+	}
+	if v := q.Values(other); v != [env.NUM_ACTIONS]float64{} {
+		t.Errorf("Expected the values of another state to stay 0, got %v", v)
+	}
+}
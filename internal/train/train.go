@@ -0,0 +1,205 @@
+// Package train provides a dependency-free Q-learning trainer that plays
+// the environment in process, without the HTTP server.
+//
+// The agents learn action values from the features observation of the
+// environment (see env.ObsFeatures): QTable looks them up by a discretized
+// state, Linear approximates them with a linear function of coarse features
+// of the ball relative to the paddle.
+// A Trainer plays epsilon-greedy episodes and applies the one-step
+// Q-learning update after every step, the exploration rate follows a
+// Schedule. Checkpoints save an agent with the progress of its training to
+// a file, see checkpoint.go.
+//
+// Types:
+// - Agent: Estimates and learns the action values of observations.
+// - Options: Learning rate, discount, exploration schedule and seed.
+// - Trainer: Plays and learns from training episodes.
+// - EpisodeStats: The return, score and length of an episode.
+//
+// Functions:
+// - DefaultOptions: Returns options that train on the default game.
+// - NewTrainer: Creates a trainer for an environment and an agent.
+// - Greedy: Returns the action with the highest value.
+// - Evaluate: Plays greedy episodes without learning.
+package train
+
+import (
+	"breakout-go/internal/env"
+	"fmt"
+	"math/rand/v2"
+)
+
+// trainStream is the stream of the PCG source of the exploration
+const trainStream = 0x7a17
+
+type Agent interface {
+	// Kind returns the name of the agent, e.g. "tabular"
+	Kind() string
+	// Values returns the estimated value of every action in the state of
+	// the features observation obs
+	Values(obs []float32) [env.NUM_ACTIONS]float64
+	// Update moves the value of the action in the state of obs towards
+	// target by the learning rate alpha
+	Update(obs []float32, action env.Action, target, alpha float64)
+}
+
+type Options struct {
+	Alpha   float64  // learning rate
+	Gamma   float64  // discount of future rewards
+	Epsilon Schedule // probability of a random action by step, nil for none
+	Seed    int64    // seed of the exploration, episode n is played with seed Seed+n, at least 1
+}
+
+// DefaultOptions returns options that train both agents on the default
+// game in a few minutes
+func DefaultOptions() Options {
+	return Options{
+		Alpha:   0.1,
+		Gamma:   0.99,
+		Epsilon: LinearDecay{Start: 1, End: 0.05, Steps: 200_000},
+		Seed:    1,
+	}
+}
+
+// Validate returns an error for a learning rate, discount or seed out of
+// range. The seeds of the episodes start at Seed and must not reach 0, which
+// the environments take for a time based seed.
+func (o Options) Validate() error {
+	if o.Alpha <= 0 || o.Alpha > 1 {
+		return fmt.Errorf("invalid learning rate %g, expected above 0 to 1", o.Alpha)
+	}
+	if o.Gamma < 0 || o.Gamma > 1 {
+		return fmt.Errorf("invalid discount %g, expected 0 to 1", o.Gamma)
+	}
+	if o.Seed < 1 {
+		return fmt.Errorf("invalid seed %d, expected at least 1", o.Seed)
+	}
+	return nil
+}
+
+type Trainer struct {
+	env   env.Env
+	agent Agent
+	opts  Options
+	rng   *rand.Rand
+
+	Steps    int // steps trained, the exploration schedule runs on them
+	Episodes int // episodes trained
+}
+
+// NewTrainer returns a trainer of the agent on the environment. It fails
+// for invalid options and environments that do not observe features, see
+// env.ObsFeatures. Set Steps and Episodes to resume a training.
+func NewTrainer(e env.Env, agent Agent, opts Options) (*Trainer, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkSpace(e.Space()); err != nil {
+		return nil, err
+	}
+	return &Trainer{env: e, agent: agent, opts: opts, rng: rand.New(rand.NewPCG(uint64(opts.Seed), trainStream))}, nil
+}
+
+// checkSpace returns an error for observations that are not features
+func checkSpace(space env.Space) error {
+	if len(space.Shape) != 1 || space.Shape[0] < env.NUM_BALL_PADDLE_FEATURES || space.DType != env.DTYPE_FLOAT32 {
+		return fmt.Errorf("observations of shape %v, expected the %s observation", space.Shape, env.ObsFeatures)
+	}
+	return nil
+}
+
+// Agent returns the trained agent
+func (t *Trainer) Agent() Agent {
+	return t.agent
+}
+
+// Epsilon returns the current exploration rate
+func (t *Trainer) Epsilon() float64 {
+	if t.opts.Epsilon == nil {
+		return 0
+	}
+	return t.opts.Epsilon.Value(t.Steps)
+}
+
+type EpisodeStats struct {
+	Seed    int64   `json:"seed"`
+	Return  float64 `json:"return"`  // sum of the rewards
+	Score   int     `json:"score"`   // final score
+	Level   int     `json:"level"`   // level reached
+	Frames  int     `json:"frames"`  // frames played
+	Epsilon float64 `json:"epsilon"` // exploration rate at the end
+}
+
+// Episode plays one training episode until it is terminated or truncated,
+// updating the agent after every step
+func (t *Trainer) Episode() EpisodeStats {
+	stats := EpisodeStats{Seed: t.opts.Seed + int64(t.Episodes)}
+	obs := t.env.Reset(stats.Seed)
+	for {
+		action := Greedy(t.agent, obs.Data)
+		if t.rng.Float64() < t.Epsilon() {
+			action = env.Action(t.rng.IntN(env.NUM_ACTIONS))
+		}
+		next, reward, terminated, truncated, info := t.env.Step(action)
+		target := reward
+		if !terminated {
+			values := t.agent.Values(next.Data)
+			target += t.opts.Gamma * values[argmax(values)]
+		}
+		t.agent.Update(obs.Data, action, target, t.opts.Alpha)
+		t.Steps++
+		stats.Return += reward
+		stats.Score, stats.Level, stats.Frames = info.Score, info.Level, info.Frame
+		if terminated || truncated {
+			break
+		}
+		obs = next
+	}
+	t.Episodes++
+	stats.Epsilon = t.Epsilon()
+	return stats
+}
+
+// Greedy returns the action with the highest value in the state of obs,
+// the first one of equal values
+func Greedy(agent Agent, obs []float32) env.Action {
+	return env.Action(argmax(agent.Values(obs)))
+}
+
+// Evaluate plays n greedy episodes, with the seeds from seed on, until they
+// are terminated or truncated and returns their stats. The agent is not
+// updated. It fails like NewTrainer for environments that do not observe
+// features.
+func Evaluate(e env.Env, agent Agent, seed int64, n int) ([]EpisodeStats, error) {
+	if err := checkSpace(e.Space()); err != nil {
+		return nil, err
+	}
+	stats := make([]EpisodeStats, n)
+	for i := range stats {
+		s := &stats[i]
+		s.Seed = seed + int64(i)
+		obs := e.Reset(s.Seed)
+		for {
+			next, reward, terminated, truncated, info := e.Step(Greedy(agent, obs.Data))
+			s.Return += reward
+			s.Score, s.Level, s.Frames = info.Score, info.Level, info.Frame
+			if terminated || truncated {
+				break
+			}
+			obs = next
+		}
+	}
+	return stats, nil
+}
+
+// argmax returns the index of the largest value, the first one of equal
+// values
+func argmax(values [env.NUM_ACTIONS]float64) int {
+	best := 0
+	for i, v := range values {
+		if v > values[best] {
+			best = i
+		}
+	}
+	return best
+}
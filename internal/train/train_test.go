@@ -0,0 +1,111 @@
+package train
+
+import (
+	"breakout-go/internal/breakout"
+	"breakout-go/internal/env"
+	"reflect"
+	"testing"
+)
+
+func newTestEnv(mode env.ObsMode) env.Env {
+	return env.NewFrameSkip(env.NewBreakoutEnv(breakout.DefaultConfig(), env.Options{
+		MaxSteps:    2000,
+		Observation: env.ObsOptions{Mode: mode},
+		Reward:      env.ShapedReward,
+	}), 4)
+}
+
+func TestNewTrainerChecksEnvAndOptions(t *testing.T) {
+	if _, err := NewTrainer(newTestEnv(env.ObsBitmap), NewLinear(), DefaultOptions()); err == nil {
+		t.Error("Expected an error for the bitmap observation")
+	}
+	opts := DefaultOptions()
+	opts.Alpha = 0
+	if _, err := NewTrainer(newTestEnv(env.ObsFeatures), NewLinear(), opts); err == nil {
+		t.Error("Expected an error for a learning rate of 0")
+	}
+	opts = DefaultOptions()
+	opts.Seed = 0
+	if _, err := NewTrainer(newTestEnv(env.ObsFeatures), NewLinear(), opts); err == nil {
+		t.Error("Expected an error for seed 0, a time based first episode")
+	}
+	if _, err := Evaluate(newTestEnv(env.ObsRGB), NewLinear(), 1, 1); err == nil {
+		t.Error("Expected Evaluate to fail for the rgb observation")
+	}
+}
+
+func TestTrainerEpisode(t *testing.T) {
+	q := NewQTable(DefaultBuckets())
+	tr, err := NewTrainer(newTestEnv(env.ObsFeatures), q, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Expected a trainer, got %v", err)
+	}
+	first := tr.Episode()
+	second := tr.Episode()
+	if tr.Episodes != 2 || first.Seed != 1 || second.Seed != 2 {
+		t.Errorf("Expected episodes with seeds 1 and 2, got %d episodes, seeds %d and %d", tr.Episodes, first.Seed, second.Seed)
+	}
+	if want := (first.Frames + second.Frames + 3) / 4; tr.Steps < want-1 || tr.Steps > want+1 {
+		t.Errorf("Expected about %d steps of 4 frames, got %d", want, tr.Steps)
+	}
+	if first.Epsilon >= 1 || second.Epsilon >= first.Epsilon {
+		t.Errorf("Expected the exploration rate to fall, got %g and %g", first.Epsilon, second.Epsilon)
+	}
+	if !slicesHasNonZero(q.Q) {
+		t.Error("Expected the training to update the table")
+	}
+}
+
+func TestTrainerIsDeterministic(t *testing.T) {
+	run := func() (*Linear, []EpisodeStats) {
+		l := NewLinear()
+		tr, _ := NewTrainer(newTestEnv(env.ObsFeatures), l, DefaultOptions())
+		var stats []EpisodeStats
+		for range 3 {
+			stats = append(stats, tr.Episode())
+		}
+		return l, stats
+	}
+	a, statsA := run()
+	b, statsB := run()
+	if !reflect.DeepEqual(a, b) || !reflect.DeepEqual(statsA, statsB) {
+		t.Error("Expected the same training for the same seed")
+	}
+}
+
+func TestEvaluateDoesNotLearn(t *testing.T) {
+	l := NewLinear()
+	l.Weights[env.ActionLeft][0] = 1
+	before := *l
+	stats, err := Evaluate(newTestEnv(env.ObsFeatures), l, 7, 2)
+	if err != nil {
+		t.Fatalf("Expected stats, got %v", err)
+	}
+	if len(stats) != 2 || stats[0].Seed != 7 || stats[1].Seed != 8 {
+		t.Errorf("Expected 2 episodes with seeds 7 and 8, got %+v", stats)
+	}
+	if *l != before {
+		t.Error("Expected Evaluate to keep the weights")
+	}
+}
+
+func TestGreedy(t *testing.T) {
+	l := NewLinear()
+	obs := make([]float32, env.NUM_BALL_PADDLE_FEATURES)
+	if a := Greedy(l, obs); a != env.ActionNoop {
+		t.Errorf("Expected the first action of equal values, got %v", a)
+	}
+	l.Weights[env.ActionRight][0] = 1
+	if a := Greedy(l, obs); a != env.ActionRight {
+		t.Errorf("Expected the action with the highest value, got %v", a)
+	}
+}
+
+func slicesHasNonZero(values []float64) bool {
+	for _, v := range values {
+		if v != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+// Package websocket provides a minimal WebSocket (RFC 6455) implementation
+// on top of net/http, so the game server can stream frames without external
+// dependencies.
+//
+// It covers the server handshake, a client (used by tests and tools) and
+// reading and writing of complete messages. Fragmented messages are
+// reassembled, pings are answered and a close frame is answered before the
+// connection is closed. Extensions, subprotocols and wss:// are not
+// supported.
+//
+// Types:
+// - Conn: A WebSocket connection.
+//
+// Functions:
+// - Upgrade: Upgrades an HTTP request to a WebSocket connection.
+// - Dial: Opens a client connection to a ws:// URL.
+// - AcceptKey: Computes the Sec-WebSocket-Accept value of a handshake key.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Message types (frame opcodes)
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// MAX_MESSAGE_SIZE is the size of the largest message ReadMessage accepts
+const MAX_MESSAGE_SIZE = 1 << 20
+
+// acceptGUID is appended to the handshake key, see RFC 6455 section 1.3
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrClosed is returned by ReadMessage when the peer closed the connection
+var ErrClosed = errors.New("websocket: connection closed")
+
+type Conn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool       // frames sent by a client are masked
+	wmu    sync.Mutex // serializes writes
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for a Sec-WebSocket-Key
+func AcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// Upgrade performs the server side of the handshake and takes over the
+// connection of the request. On failure it has already answered the request
+// with an HTTP error. Browsers send the Origin of the page, a handshake from
+// a page of another host is refused (see sameOrigin), so other sites cannot
+// drive the game through the browser of a player.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!headerHas(r.Header, "Connection", "upgrade") ||
+		!headerHas(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "Expected WebSocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: not a handshake request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("websocket: unsupported version %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-origin WebSocket handshake", http.StatusForbidden)
+		return nil, fmt.Errorf("websocket: origin %q does not match host %q", r.Header.Get("Origin"), r.Host)
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "Missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: missing key")
+	}
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", AcceptKey(key))
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	return &Conn{conn: conn, br: brw.Reader}, nil
+}
+
+// Dial opens a client connection to a ws:// URL
+func Dial(rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" {
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "80")
+	}
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	_, err = fmt.Fprintf(conn, "GET %s HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n", u.RequestURI(), u.Host, key)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodGet})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: unexpected status %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != AcceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: invalid accept key")
+	}
+	return &Conn{conn: conn, br: br, client: true}, nil
+}
+
+// ReadMessage reads the next text or binary message. Control frames are
+// handled on the way, a close frame from the peer returns ErrClosed.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	msgType := 0
+	var msg []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case PingMessage:
+			if err := c.writeFrame(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			c.writeFrame(CloseMessage, payload)
+			c.conn.Close()
+			return 0, nil, ErrClosed
+		case 0: // continuation of a fragmented message
+			if msgType == 0 {
+				return 0, nil, fmt.Errorf("websocket: unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if msgType != 0 {
+				return 0, nil, fmt.Errorf("websocket: expected continuation frame")
+			}
+			msgType = op
+		default:
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %d", op)
+		}
+		if len(msg)+len(payload) > MAX_MESSAGE_SIZE {
+			return 0, nil, fmt.Errorf("websocket: message larger than %d bytes", MAX_MESSAGE_SIZE)
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msgType, msg, nil
+		}
+	}
+}
+
+// WriteMessage sends data as a single message frame of the given type. It
+// is safe to call from several goroutines.
+func (c *Conn) WriteMessage(msgType int, data []byte) error {
+	return c.writeFrame(msgType, data)
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v
+func (c *Conn) ReadJSON(v any) error {
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON sends v encoded as JSON in a text message
+func (c *Conn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, data)
+}
+
+// Close sends a normal closure frame and closes the connection
+func (c *Conn) Close() error {
+	c.writeFrame(CloseMessage, []byte{0x03, 0xe8}) // status 1000
+	return c.conn.Close()
+}
+
+// readFrame reads a single frame and unmasks its payload
+func (c *Conn) readFrame() (bool, int, []byte, error) {
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := h[0]&0x80 != 0
+	op := int(h[0] & 0x0f)
+	if h[0]&0x70 != 0 {
+		return false, 0, nil, fmt.Errorf("websocket: reserved bits set")
+	}
+	masked := h[1]&0x80 != 0
+	if masked == c.client {
+		// clients mask every frame, servers none
+		return false, 0, nil, fmt.Errorf("websocket: invalid frame masking")
+	}
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > MAX_MESSAGE_SIZE {
+		return false, 0, nil, fmt.Errorf("websocket: frame larger than %d bytes", MAX_MESSAGE_SIZE)
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// writeFrame writes data as a single, final frame
+func (c *Conn) writeFrame(op int, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	buf := make([]byte, 0, 14+len(data))
+	buf = append(buf, 0x80|byte(op))
+	maskBit := byte(0)
+	if c.client {
+		maskBit = 0x80
+	}
+	n := len(data)
+	switch {
+	case n < 126:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	if c.client {
+		var mask [4]byte
+		rand.Read(mask[:])
+		buf = append(buf, mask[:]...)
+		for i, v := range data {
+			buf = append(buf, v^mask[i%4])
+		}
+	} else {
+		buf = append(buf, data...)
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+// headerHas returns true if the comma separated header contains the token
+func headerHas(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameOrigin returns true if the request has no Origin header (clients
+// other than browsers) or the host of its Origin is the host of the request
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
@@ -0,0 +1,144 @@
+package websocket
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newEchoServer starts a server that sends every message back
+func newEchoServer(t *testing.T) (*httptest.Server, string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msgType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(msgType, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestAcceptKey(t *testing.T) {
+	// example from RFC 6455 section 1.3
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Expected accept key s3pPLMBiTxaQ9kYGzzhZRbK+xOo=, got %s", got)
+	}
+}
+
+func TestEchoMessageSizes(t *testing.T) {
+	_, url := newEchoServer(t)
+	conn, err := Dial(url)
+	if err != nil {
+		t.Fatalf("Expected dial to succeed, got %v", err)
+	}
+	defer conn.Close()
+
+	// payload lengths with 7 bit, 16 bit and 64 bit length encoding
+	for _, n := range []int{0, 5, 125, 126, 1000, 70000} {
+		data := bytes.Repeat([]byte{'x'}, n)
+		if err := conn.WriteMessage(BinaryMessage, data); err != nil {
+			t.Fatalf("Expected write of %d bytes to succeed, got %v", n, err)
+		}
+		msgType, got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Expected read of %d bytes to succeed, got %v", n, err)
+		}
+		if msgType != BinaryMessage || !bytes.Equal(got, data) {
+			t.Errorf("Expected echo of %d bytes, got type %d with %d bytes", n, msgType, len(got))
+		}
+	}
+}
+
+func TestEchoJSON(t *testing.T) {
+	_, url := newEchoServer(t)
+	conn, err := Dial(url)
+	if err != nil {
+		t.Fatalf("Expected dial to succeed, got %v", err)
+	}
+	defer conn.Close()
+
+	type message struct{ Key string }
+	if err := conn.WriteJSON(message{Key: "left"}); err != nil {
+		t.Fatalf("Expected write to succeed, got %v", err)
+	}
+	var got message
+	if err := conn.ReadJSON(&got); err != nil {
+		t.Fatalf("Expected read to succeed, got %v", err)
+	}
+	if got.Key != "left" {
+		t.Errorf("Expected key left, got %q", got.Key)
+	}
+}
+
+func TestPingAnsweredAndCloseReported(t *testing.T) {
+	_, url := newEchoServer(t)
+	conn, err := Dial(url)
+	if err != nil {
+		t.Fatalf("Expected dial to succeed, got %v", err)
+	}
+
+	// the server answers the ping with a pong, which the client skips
+	conn.writeFrame(PingMessage, []byte("ping"))
+	conn.WriteMessage(TextMessage, []byte("after ping"))
+	_, got, err := conn.ReadMessage()
+	if err != nil || string(got) != "after ping" {
+		t.Errorf("Expected message after ping, got %q, %v", got, err)
+	}
+
+	conn.writeFrame(CloseMessage, nil)
+	if _, _, err := conn.ReadMessage(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after close, got %v", err)
+	}
+}
+
+func TestUpgradeRejectsPlainRequest(t *testing.T) {
+	srv, _ := newEchoServer(t)
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Expected request to succeed, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestUpgradeChecksOrigin(t *testing.T) {
+	srv, _ := newEchoServer(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	tests := map[string]int{
+		"http://" + host:                  http.StatusSwitchingProtocols,
+		"http://" + strings.ToUpper(host): http.StatusSwitchingProtocols,
+		"http://evil.example":             http.StatusForbidden,
+		"null":                            http.StatusForbidden,
+	}
+	for origin, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Expected request to succeed, got %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Expected status %d for origin %s, got %d", want, origin, resp.StatusCode)
+		}
+	}
+}